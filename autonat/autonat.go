@@ -0,0 +1,76 @@
+// Package autonat provides the interfaces of the AutoNAT subsystem, which
+// determines whether the local node is reachable from the public internet by
+// asking other peers to dial it back.
+//
+// The reachability status is expressed as a network.Reachability, and changes
+// are announced on the event bus with event.EvtLocalReachabilityChanged.
+package autonat
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// ErrNoPublicAddr is returned by AutoNAT.PublicAddr when the node hasn't been
+// determined to be publicly reachable.
+var ErrNoPublicAddr = errors.New("no public address")
+
+// AutoNAT is the interface of the AutoNAT service running on a host.
+type AutoNAT interface {
+	io.Closer
+
+	// Status returns the current reachability of the local node.
+	Status() network.Reachability
+
+	// PublicAddr returns a public address the node was dialed back on, or
+	// ErrNoPublicAddr if the status is not network.ReachabilityPublic.
+	PublicAddr() (ma.Multiaddr, error)
+}
+
+// Client is a client of the AutoNAT protocol, used to ask remote peers to
+// dial the local node back.
+type Client interface {
+	// DialBack asks p to dial the local node back on its advertised
+	// addresses, and returns the address the dial back succeeded on.
+	DialBack(ctx context.Context, p peer.ID) (ma.Multiaddr, error)
+}
+
+// Status codes of the AutoNAT protocol.
+const (
+	StatusOK            int32 = 0
+	StatusDialError     int32 = 100
+	StatusDialRefused   int32 = 101
+	StatusBadRequest    int32 = 200
+	StatusInternalError int32 = 300
+)
+
+// Error is returned by Client.DialBack when the remote peer responded with an
+// error.
+type Error struct {
+	// Status is the protocol status code returned by the remote peer.
+	Status int32
+	// Text is the status text returned by the remote peer.
+	Text string
+}
+
+func (e *Error) Error() string {
+	return "autonat error: " + e.Text
+}
+
+// IsDialError returns true if the remote peer failed to dial the local node
+// back, which suggests the node is not publicly reachable.
+func (e *Error) IsDialError() bool {
+	return e.Status == StatusDialError
+}
+
+// IsDialRefused returns true if the remote peer refused to dial the local
+// node back, e.g. because of rate limiting.
+func (e *Error) IsDialRefused() bool {
+	return e.Status == StatusDialRefused
+}
@@ -0,0 +1,148 @@
+// Package clockskew provides interfaces and helpers for estimating the clock
+// offset between the local node and connected peers.
+//
+// Estimates are derived from ping-style timestamp exchanges, in the manner of
+// NTP: the local node records when it sent a probe and when it got the reply,
+// and the remote peer records when it received the probe and when it sent the
+// reply. Skew estimates allow expiry checks on records issued by a remote peer
+// to tolerate the difference between its clock and ours.
+package clockskew
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+)
+
+// PeerstoreKey is the peerstore metadata key under which the latest Estimate
+// for a peer is stored.
+const PeerstoreKey = "libp2p/clockskew"
+
+// ErrNoSamples is returned when attempting to compute an estimate from an empty
+// set of samples.
+var ErrNoSamples = errors.New("no clock skew samples")
+
+// Sample is a single ping-style timestamp exchange with a remote peer.
+//
+// Sent and Received are measured with the local clock, RemoteReceived and
+// RemoteSent with the remote peer's clock.
+type Sample struct {
+	// Sent is when the local node sent the probe.
+	Sent time.Time
+	// RemoteReceived is when the remote peer received the probe.
+	RemoteReceived time.Time
+	// RemoteSent is when the remote peer sent its reply.
+	RemoteSent time.Time
+	// Received is when the local node received the reply.
+	Received time.Time
+}
+
+// Offset returns the estimated offset of the remote clock relative to the
+// local clock. A positive offset means the remote clock is ahead of ours.
+func (s Sample) Offset() time.Duration {
+	return (s.RemoteReceived.Sub(s.Sent) + s.RemoteSent.Sub(s.Received)) / 2
+}
+
+// RoundTrip returns the network round-trip time of the exchange, excluding
+// the time the remote peer took to process the probe.
+func (s Sample) RoundTrip() time.Duration {
+	return s.Received.Sub(s.Sent) - s.RemoteSent.Sub(s.RemoteReceived)
+}
+
+// Estimate is the estimated clock skew of a peer.
+type Estimate struct {
+	// Offset is the estimated offset of the remote clock relative to the
+	// local clock. A positive offset means the remote clock is ahead of ours.
+	Offset time.Duration
+
+	// Uncertainty bounds the error of Offset. The true offset is within
+	// [Offset-Uncertainty, Offset+Uncertainty].
+	Uncertainty time.Duration
+
+	// Samples is the number of samples the estimate was derived from.
+	Samples int
+
+	// Updated is the (local) time at which the estimate was computed.
+	Updated time.Time
+}
+
+// ToLocal converts a timestamp produced by the remote peer's clock to the
+// local clock.
+func (e Estimate) ToLocal(remote time.Time) time.Time {
+	return remote.Add(-e.Offset)
+}
+
+// Expired reports whether a remote-issued expiry timestamp has passed at the
+// local time now, giving the peer the benefit of the doubt within the
+// estimate's uncertainty.
+func (e Estimate) Expired(expiry, now time.Time) bool {
+	return now.After(e.ToLocal(expiry).Add(e.Uncertainty))
+}
+
+// FromSamples computes an Estimate from a set of samples.
+//
+// Samples with the smallest round trip are the least affected by asymmetric
+// network delays, so the sample with the minimum RoundTrip is selected and
+// half of its round trip is used as the uncertainty. Samples with a negative
+// round trip (e.g. due to a misbehaving peer) are discarded.
+func FromSamples(samples []Sample) (Estimate, error) {
+	var (
+		best  Sample
+		n     int
+		found bool
+	)
+	for _, s := range samples {
+		rtt := s.RoundTrip()
+		if rtt < 0 {
+			continue
+		}
+		n++
+		if !found || rtt < best.RoundTrip() {
+			best = s
+			found = true
+		}
+	}
+	if !found {
+		return Estimate{}, ErrNoSamples
+	}
+	return Estimate{
+		Offset:      best.Offset(),
+		Uncertainty: best.RoundTrip() / 2,
+		Samples:     n,
+		Updated:     best.Received,
+	}, nil
+}
+
+// Estimator is implemented by services that estimate the clock skew of
+// connected peers.
+//
+// Implementations should store their latest estimate for each peer in the
+// peerstore, under PeerstoreKey, so that other subsystems can consume it via
+// the GetEstimate helper.
+type Estimator interface {
+	// Estimate returns the latest estimate for the given peer, if any.
+	Estimate(p peer.ID) (est Estimate, ok bool)
+
+	// Probe exchanges timestamps with the given peer, updates the estimate
+	// and returns it.
+	Probe(ctx context.Context, p peer.ID) (Estimate, error)
+}
+
+// GetEstimate returns the clock skew estimate stored for the given peer in
+// the peerstore metadata, if any.
+func GetEstimate(pm peerstore.PeerMetadata, p peer.ID) (est Estimate, ok bool) {
+	v, err := pm.Get(p, PeerstoreKey)
+	if err != nil {
+		return Estimate{}, false
+	}
+	est, ok = v.(Estimate)
+	return est, ok
+}
+
+// PutEstimate stores the given clock skew estimate in the peerstore metadata.
+func PutEstimate(pm peerstore.PeerMetadata, p peer.ID, est Estimate) error {
+	return pm.Put(p, PeerstoreKey, est)
+}
@@ -0,0 +1,69 @@
+package clockskew
+
+import (
+	"testing"
+	"time"
+)
+
+func sample(base time.Time, offset, there, processing, back time.Duration) Sample {
+	sent := base
+	remoteReceived := sent.Add(there).Add(offset)
+	remoteSent := remoteReceived.Add(processing)
+	received := remoteSent.Add(-offset).Add(back)
+	return Sample{Sent: sent, RemoteReceived: remoteReceived, RemoteSent: remoteSent, Received: received}
+}
+
+func TestSample(t *testing.T) {
+	now := time.Now()
+	s := sample(now, 3*time.Second, 10*time.Millisecond, 5*time.Millisecond, 10*time.Millisecond)
+	if s.Offset() != 3*time.Second {
+		t.Fatalf("expected offset of 3s, got %s", s.Offset())
+	}
+	if s.RoundTrip() != 20*time.Millisecond {
+		t.Fatalf("expected round trip of 20ms, got %s", s.RoundTrip())
+	}
+}
+
+func TestFromSamples(t *testing.T) {
+	if _, err := FromSamples(nil); err != ErrNoSamples {
+		t.Fatalf("expected ErrNoSamples, got %v", err)
+	}
+
+	now := time.Now()
+	offset := -2 * time.Second
+	est, err := FromSamples([]Sample{
+		// asymmetric delays skew the offset of this one.
+		sample(now, offset, 200*time.Millisecond, 0, 10*time.Millisecond),
+		sample(now, offset, 5*time.Millisecond, time.Millisecond, 5*time.Millisecond),
+		// negative round trip, must be discarded.
+		{Sent: now, Received: now, RemoteReceived: now, RemoteSent: now.Add(time.Second)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.Offset != offset {
+		t.Fatalf("expected offset of %s, got %s", offset, est.Offset)
+	}
+	if est.Uncertainty != 5*time.Millisecond {
+		t.Fatalf("expected uncertainty of 5ms, got %s", est.Uncertainty)
+	}
+	if est.Samples != 2 {
+		t.Fatalf("expected 2 samples, got %d", est.Samples)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Now()
+	// the remote clock is a minute ahead.
+	est := Estimate{Offset: time.Minute, Uncertainty: time.Second}
+
+	if est.Expired(now.Add(30*time.Second), now.Add(-31*time.Second)) {
+		t.Error("expected expiry not to have passed yet")
+	}
+	if !est.Expired(now.Add(30*time.Second), now) {
+		t.Error("expected expiry issued by a fast clock to have passed")
+	}
+	if est.Expired(now.Add(time.Minute), now.Add(time.Second)) {
+		t.Error("expected expiry within uncertainty to be tolerated")
+	}
+}
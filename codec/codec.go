@@ -0,0 +1,193 @@
+// Package codec provides a registry of message codecs used to serialize
+// messages exchanged over libp2p streams.
+//
+// Protocols that exchange length-delimited messages can look up a codec by
+// name and use NewWriter / NewReader to send and receive values over a
+// stream. Applications can plug in additional serialization formats (e.g.
+// CBOR, msgpack, flatbuffers) by calling Register, and advertise the codecs
+// they support for a protocol with ProtocolIDs.
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/libp2p/go-msgio"
+)
+
+const (
+	// Protobuf is the name of the built-in protobuf codec. It only accepts
+	// values implementing proto.Message.
+	Protobuf = "protobuf"
+
+	// JSON is the name of the built-in JSON codec.
+	JSON = "json"
+)
+
+var (
+	// ErrAlreadyRegistered is returned by Register when a codec with the
+	// same name already exists.
+	ErrAlreadyRegistered = errors.New("codec already registered")
+
+	// ErrNotRegistered is returned when looking up an unknown codec.
+	ErrNotRegistered = errors.New("codec not registered")
+)
+
+// Marshaler serializes a value to bytes.
+type Marshaler func(v interface{}) ([]byte, error)
+
+// Unmarshaler deserializes bytes into the value pointed to by v. The data is
+// owned by the Unmarshaler once passed to it, so zero-copy codecs may keep
+// references to it in v.
+type Unmarshaler func(data []byte, v interface{}) error
+
+// Codec is a named pair of Marshaler and Unmarshaler.
+type Codec struct {
+	Name      string
+	Marshal   Marshaler
+	Unmarshal Unmarshaler
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Codec)
+)
+
+func init() {
+	_ = Register(Protobuf, marshalProto, unmarshalProto)
+	_ = Register(JSON, json.Marshal, json.Unmarshal)
+}
+
+// Register adds a codec to the registry under the given name. It returns
+// ErrAlreadyRegistered if the name is taken.
+//
+// Registration should usually be done in the init function of the package
+// providing the codec.
+func Register(name string, m Marshaler, u Unmarshaler) error {
+	if name == "" || m == nil || u == nil {
+		return fmt.Errorf("invalid codec %q: name, marshaler and unmarshaler are required", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("%w: %s", ErrAlreadyRegistered, name)
+	}
+	registry[name] = Codec{Name: name, Marshal: m, Unmarshal: u}
+	return nil
+}
+
+// Lookup returns the codec registered under the given name.
+func Lookup(name string) (Codec, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := registry[name]
+	if !ok {
+		return Codec{}, fmt.Errorf("%w: %s", ErrNotRegistered, name)
+	}
+	return c, nil
+}
+
+// Names returns the names of all registered codecs, sorted.
+func Names() []string {
+	registryMu.RLock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	registryMu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// ProtocolID returns the protocol ID advertising the given codec for the base
+// protocol, by appending the codec name as the last path segment, e.g.
+// /app/rpc/1.0.0/protobuf.
+func ProtocolID(base protocol.ID, codec string) protocol.ID {
+	return protocol.ID(string(base) + "/" + codec)
+}
+
+// ProtocolIDs returns a protocol ID (see ProtocolID) for each of the given
+// codecs, in order. It's meant to be passed to host.NewStream in order of
+// preference, and to be used when registering stream handlers.
+func ProtocolIDs(base protocol.ID, codecs ...string) []protocol.ID {
+	ids := make([]protocol.ID, 0, len(codecs))
+	for _, c := range codecs {
+		ids = append(ids, ProtocolID(base, c))
+	}
+	return ids
+}
+
+// Writer writes length-prefixed, serialized values to a stream.
+type Writer struct {
+	w     msgio.WriteCloser
+	codec Codec
+}
+
+// NewWriter returns a Writer serializing values with the given codec and
+// writing them as unsigned-varint length-prefixed messages.
+func NewWriter(w io.Writer, c Codec) *Writer {
+	return &Writer{w: msgio.NewVarintWriter(w), codec: c}
+}
+
+// WriteMsg serializes v and writes it to the underlying writer.
+func (w *Writer) WriteMsg(v interface{}) error {
+	b, err := w.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.w.WriteMsg(b)
+}
+
+// Reader reads length-prefixed, serialized values from a stream.
+type Reader struct {
+	r     msgio.ReadCloser
+	codec Codec
+}
+
+// NewReader returns a Reader reading unsigned-varint length-prefixed messages
+// of at most maxSize bytes and deserializing them with the given codec. If
+// maxSize is zero, network.MessageSizeMax is used.
+func NewReader(r io.Reader, c Codec, maxSize int) *Reader {
+	if maxSize <= 0 {
+		maxSize = network.MessageSizeMax
+	}
+	return &Reader{r: msgio.NewVarintReaderSize(r, maxSize), codec: c}
+}
+
+// ReadMsg reads the next message and deserializes it into v.
+func (r *Reader) ReadMsg(v interface{}) error {
+	b, err := r.r.ReadMsg()
+	if err != nil {
+		return err
+	}
+	// the read buffer is pooled, while the codec may retain its input
+	data := make([]byte, len(b))
+	copy(data, b)
+	r.r.ReleaseMsg(b)
+	return r.codec.Unmarshal(data, v)
+}
+
+func marshalProto(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func unmarshalProto(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
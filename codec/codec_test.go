@@ -0,0 +1,83 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	require.Contains(t, Names(), Protobuf)
+	require.Contains(t, Names(), JSON)
+
+	_, err := Lookup("does-not-exist")
+	require.True(t, errors.Is(err, ErrNotRegistered))
+
+	err = Register(JSON, func(interface{}) ([]byte, error) { return nil, nil }, func([]byte, interface{}) error { return nil })
+	require.True(t, errors.Is(err, ErrAlreadyRegistered))
+
+	require.Error(t, Register("incomplete", nil, nil))
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, name := range []string{Protobuf, JSON} {
+		t.Run(name, func(t *testing.T) {
+			c, err := Lookup(name)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			w := NewWriter(&buf, c)
+			in := []*pb.PublicKey{
+				{Type: pb.KeyType_Ed25519, Data: []byte("foo")},
+				{Type: pb.KeyType_RSA, Data: []byte("bar")},
+			}
+			for _, m := range in {
+				require.NoError(t, w.WriteMsg(m))
+			}
+
+			r := NewReader(&buf, c, 0)
+			for _, m := range in {
+				var out pb.PublicKey
+				require.NoError(t, r.ReadMsg(&out))
+				require.Equal(t, m.Type, out.Type)
+				require.Equal(t, m.Data, out.Data)
+			}
+		})
+	}
+}
+
+func TestZeroCopyCodec(t *testing.T) {
+	// raw "unmarshals" by keeping a reference to its input
+	raw := Codec{
+		Name:    "raw",
+		Marshal: func(v interface{}) ([]byte, error) { return v.([]byte), nil },
+		Unmarshal: func(data []byte, v interface{}) error {
+			*v.(*[]byte) = data
+			return nil
+		},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf, raw)
+	in := [][]byte{[]byte("first message"), []byte("second message")}
+	for _, m := range in {
+		require.NoError(t, w.WriteMsg(m))
+	}
+
+	r := NewReader(&buf, raw, 0)
+	out := make([][]byte, len(in))
+	for i := range in {
+		require.NoError(t, r.ReadMsg(&out[i]))
+	}
+	require.Equal(t, in, out)
+}
+
+func TestProtocolIDs(t *testing.T) {
+	ids := ProtocolIDs("/app/rpc/1.0.0", "cbor", Protobuf)
+	require.Equal(t, "/app/rpc/1.0.0/cbor", string(ids[0]))
+	require.Equal(t, "/app/rpc/1.0.0/protobuf", string(ids[1]))
+}
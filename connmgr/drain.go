@@ -0,0 +1,48 @@
+package connmgr
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+)
+
+// DefaultDrainTimeout is a sensible default for the time connections are given
+// to drain before being closed by a trim.
+const DefaultDrainTimeout = 5 * time.Second
+
+// SupportsDraining evaluates if the provided ConnManager drains connections
+// before closing them, and if so, it returns the Drainer object.
+func SupportsDraining(mgr ConnManager) (Drainer, bool) {
+	d, ok := mgr.(Drainer)
+	return d, ok
+}
+
+// Drainer is implemented by ConnManagers that close the connections they trim
+// gracefully: instead of closing a connection right away, they emit an
+// event.EvtConnDraining on the event bus, and close the connection once its
+// streams are closed or the drain timeout elapses. This reduces mid-request
+// failures for protocols with long-lived streams.
+//
+// Draining connections keep counting against the connection limits until
+// they are closed.
+type Drainer interface {
+	// SetDrainTimeout sets how long trimmed connections are given to drain.
+	// A zero timeout disables draining, closing connections right away.
+	SetDrainTimeout(time.Duration)
+
+	// DrainTimeout returns how long trimmed connections are given to drain.
+	DrainTimeout() time.Duration
+
+	// IsDraining returns true if the connection is being drained.
+	IsDraining(network.Conn) bool
+}
+
+// IsDraining returns true if mgr is draining the connection, e.g. to decide
+// whether to open a new stream on it. It returns false if mgr doesn't support
+// draining.
+func IsDraining(mgr ConnManager, c network.Conn) bool {
+	if d, ok := SupportsDraining(mgr); ok {
+		return d.IsDraining(c)
+	}
+	return false
+}
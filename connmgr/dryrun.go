@@ -0,0 +1,64 @@
+package connmgr
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// SupportsTrimDryRun evaluates if the provided ConnManager supports dry-run
+// trims, and if so, it returns the DryRunTrimmer object.
+func SupportsTrimDryRun(mgr ConnManager) (DryRunTrimmer, bool) {
+	t, ok := mgr.(DryRunTrimmer)
+	return t, ok
+}
+
+// TrimCandidate describes a peer considered by a trim.
+type TrimCandidate struct {
+	// Peer is the peer whose connections were considered.
+	Peer peer.ID
+
+	// Conns are the connections to the peer.
+	Conns []network.Conn
+
+	// Value is the aggregated tag value of the peer, as in TagInfo.Value.
+	Value int
+
+	// Tags maps the tags of the peer to their values.
+	Tags map[string]int
+
+	// ProtectedBy lists the tags the peer is protected under, if any.
+	ProtectedBy []string
+
+	// Close is true if the connections to the peer would be closed.
+	Close bool
+
+	// Reason explains why the connections would or wouldn't be closed, e.g.
+	// "protected", "in grace period" or "lowest value".
+	Reason string
+}
+
+// TrimReport is the outcome of a dry-run trim.
+type TrimReport struct {
+	// ConnCount is the number of connections at the time of the trim.
+	ConnCount int
+
+	// Target is the number of connections the trim would have trimmed down
+	// to.
+	Target int
+
+	// Candidates are the peers considered by the trim, in the order in which
+	// they would be closed.
+	Candidates []TrimCandidate
+}
+
+// DryRunTrimmer is implemented by ConnManagers that can report which
+// connections a trim would close without closing them, so that operators can
+// tune limits safely on production nodes.
+type DryRunTrimmer interface {
+	// TrimDryRun runs the same heuristic as ConnManager.TrimOpenConns,
+	// ignoring any rate limiting of trims, and reports its decisions
+	// without closing any connection.
+	TrimDryRun(ctx context.Context) (TrimReport, error)
+}
@@ -0,0 +1,72 @@
+package connmgr
+
+import (
+	"github.com/libp2p/go-libp2p-core/network"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// SupportsConnLimits evaluates if the provided ConnManager supports
+// per-direction and per-transport connection limits, and if so, it returns
+// the ConnLimiter object.
+func SupportsConnLimits(mgr ConnManager) (ConnLimiter, bool) {
+	l, ok := mgr.(ConnLimiter)
+	return l, ok
+}
+
+// ConnLimits are connection ceilings enforced by a ConnManager in addition to
+// its low and high water marks. A zero or missing limit means no limit.
+type ConnLimits struct {
+	// Inbound is the maximum number of inbound connections.
+	Inbound int
+
+	// Outbound is the maximum number of outbound connections.
+	Outbound int
+
+	// Transports maps multiaddr protocol codes, e.g. ma.P_TCP, ma.P_QUIC or
+	// ma.P_CIRCUIT, to the maximum number of connections whose remote
+	// address includes that protocol.
+	Transports map[int]int
+}
+
+// ConnCounts are the numbers of connections tracked by a ConnManager, broken
+// down as in ConnLimits.
+type ConnCounts struct {
+	// Inbound is the number of inbound connections.
+	Inbound int
+
+	// Outbound is the number of outbound connections.
+	Outbound int
+
+	// Transports maps the multiaddr protocol codes of ConnLimits.Transports
+	// to the number of connections whose remote address includes that
+	// protocol.
+	Transports map[int]int
+}
+
+// ConnLimiter is implemented by ConnManagers that enforce separate limits on
+// inbound and outbound connections, and on connections over given
+// transports, so that a flood of inbound connections doesn't starve outbound
+// dials.
+//
+// When a limit is exceeded, the ConnManager trims the connections of the
+// class over its limit, subject to the usual tag values and protections,
+// without waiting for the high water mark to be reached.
+type ConnLimiter interface {
+	// Limits returns the limits currently in effect.
+	Limits() ConnLimits
+
+	// SetLimits replaces the limits. Connections in excess of the new limits
+	// are trimmed at the next trim.
+	SetLimits(ConnLimits) error
+
+	// ConnCounts returns the current number of connections, broken down by
+	// direction and by the transports that have a limit.
+	ConnCounts() ConnCounts
+
+	// AllowConn returns false if a new connection in the given direction,
+	// to or from the given remote address, would exceed a limit. Callers,
+	// e.g. connection gaters, can use it to refuse connections before
+	// upgrading them.
+	AllowConn(dir network.Direction, raddr ma.Multiaddr) bool
+}
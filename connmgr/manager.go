@@ -49,7 +49,8 @@ type ConnManager interface {
 	GetTagInfo(p peer.ID) *TagInfo
 
 	// TrimOpenConns terminates open connections based on an implementation-defined
-	// heuristic.
+	// heuristic. ConnManagers implementing Drainer give the connections time
+	// to drain before closing them.
 	TrimOpenConns(ctx context.Context)
 
 	// Notifee returns an implementation that can be called back to inform of
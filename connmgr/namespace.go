@@ -0,0 +1,70 @@
+package connmgr
+
+import (
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// SupportsNamespaces evaluates if the provided ConnManager supports tag
+// namespaces, and if so, it returns the NamespacedTagger object.
+func SupportsNamespaces(mgr ConnManager) (NamespacedTagger, bool) {
+	n, ok := mgr.(NamespacedTagger)
+	return n, ok
+}
+
+// NamespaceSeparator separates the namespace from the tag in the tag names
+// of namespaced tags, as reported in TagInfo.Tags.
+const NamespaceSeparator = "/"
+
+// NamespacedTag returns the tag name of a namespaced tag, as reported in
+// TagInfo.Tags.
+func NamespacedTag(ns, tag string) string {
+	return ns + NamespaceSeparator + tag
+}
+
+// NamespacedTagger is implemented by ConnManagers that group tags into
+// namespaces, e.g. "pubsub", "dht" or an application name, and weigh each
+// namespace by a configurable multiplier. This lets operators balance the
+// tags of different subsystems against each other in one place, instead of
+// tuning tag values in every protocol.
+//
+// The value of a peer, as reported in TagInfo.Value and used to decide which
+// connections to trim, is the sum of its non-namespaced tags and of its
+// namespaced tags multiplied by the weight of their namespace, rounded down.
+type NamespacedTagger interface {
+	// TagPeerNS tags a peer with a tag in the given namespace, associating a
+	// value with the tag. Tags of different namespaces don't collide.
+	TagPeerNS(ns string, p peer.ID, tag string, value int)
+
+	// UntagPeerNS removes a tag in the given namespace from the peer.
+	UntagPeerNS(ns string, p peer.ID, tag string)
+
+	// SetNamespaceWeight sets the multiplier applied to the values of the
+	// tags in the given namespace. A weight of 0 makes the namespace's tags
+	// irrelevant to trimming.
+	SetNamespaceWeight(ns string, weight float64)
+
+	// NamespaceWeight returns the multiplier applied to the values of the
+	// tags in the given namespace, 1 unless set with SetNamespaceWeight.
+	NamespaceWeight(ns string) float64
+}
+
+// TagPeerNS tags a peer with a tag in the given namespace. If mgr doesn't
+// support namespaces, the tag is set with TagPeer under its NamespacedTag
+// name, with no weight applied.
+func TagPeerNS(mgr ConnManager, ns string, p peer.ID, tag string, value int) {
+	if n, ok := SupportsNamespaces(mgr); ok {
+		n.TagPeerNS(ns, p, tag, value)
+		return
+	}
+	mgr.TagPeer(p, NamespacedTag(ns, tag), value)
+}
+
+// UntagPeerNS removes a tag in the given namespace from the peer, falling back
+// to UntagPeer like TagPeerNS.
+func UntagPeerNS(mgr ConnManager, ns string, p peer.ID, tag string) {
+	if n, ok := SupportsNamespaces(mgr); ok {
+		n.UntagPeerNS(ns, p, tag)
+		return
+	}
+	mgr.UntagPeer(p, NamespacedTag(ns, tag))
+}
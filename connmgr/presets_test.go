@@ -0,0 +1,26 @@
+package connmgr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecayExpireWhenInactive(t *testing.T) {
+	decay := DecayExpireWhenInactive(time.Minute)
+
+	for _, tc := range []struct {
+		lastVisit time.Duration
+		rm        bool
+	}{
+		{0, false},
+		{30 * time.Second, false},
+		{2 * time.Minute, true},
+		{time.Hour, true},
+	} {
+		now := time.Now()
+		v := DecayingValue{Added: now.Add(-time.Hour), LastVisit: now.Add(-tc.lastVisit), Value: 10}
+		if _, rm := decay(v); rm != tc.rm {
+			t.Errorf("tag last visited %s ago: expected rm=%t, got %t", tc.lastVisit, tc.rm, rm)
+		}
+	}
+}
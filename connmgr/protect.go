@@ -0,0 +1,51 @@
+package connmgr
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// SupportsExpiringProtection evaluates if the provided ConnManager supports
+// expiring protections, and if so, it returns the ExpiringProtector object.
+func SupportsExpiringProtection(mgr ConnManager) (ExpiringProtector, bool) {
+	p, ok := mgr.(ExpiringProtector)
+	return p, ok
+}
+
+// Protection describes a protection placed on a peer.
+type Protection struct {
+	// Peer is the protected peer.
+	Peer peer.ID
+
+	// Tag is the tag the protection was placed under, identifying the
+	// subsystem that placed it and why.
+	Tag string
+
+	// Added is the time the protection was placed.
+	Added time.Time
+
+	// Expires is the time the protection expires, or the zero time if it
+	// doesn't expire.
+	Expires time.Time
+}
+
+// ExpiringProtector is implemented by ConnManagers that support protections
+// with an expiry, and that can enumerate the protections in place.
+//
+// Protections placed with ConnManager.Protect never expire, and protections
+// that are never revoked accumulate in long-running nodes. Enumerating them
+// with ProtectedPeers makes such leaks visible, and ProtectFor avoids them
+// altogether for protections that are only needed for a bounded time.
+type ExpiringProtector interface {
+	// ProtectFor protects a peer under the given tag for the given duration,
+	// after which the protection is removed as if by Unprotect.
+	//
+	// Calling ProtectFor or Protect for an existing protection with the same
+	// tag replaces its expiry.
+	ProtectFor(id peer.ID, tag string, ttl time.Duration)
+
+	// ProtectedPeers returns all the protections currently in place, one per
+	// peer and tag.
+	ProtectedPeers() []Protection
+}
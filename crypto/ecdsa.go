@@ -11,6 +11,7 @@ import (
 	"math/big"
 
 	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+	"github.com/libp2p/go-libp2p-core/crypto/sensitive"
 	"github.com/libp2p/go-libp2p-core/internal/catch"
 
 	"github.com/minio/sha256-simd"
@@ -19,6 +20,9 @@ import (
 // ECDSAPrivateKey is an implementation of an ECDSA private key
 type ECDSAPrivateKey struct {
 	priv *ecdsa.PrivateKey
+
+	// whether to sign with RFC 6979 nonces, see GenerateHardenedECDSAKeyPair
+	hardened bool
 }
 
 // ECDSAPublicKey is an implementation of an ECDSA public key
@@ -56,7 +60,37 @@ func GenerateECDSAKeyPairWithCurve(curve elliptic.Curve, src io.Reader) (PrivKey
 		return nil, nil, err
 	}
 
-	return &ECDSAPrivateKey{priv}, &ECDSAPublicKey{&priv.PublicKey}, nil
+	return &ECDSAPrivateKey{priv: priv}, &ECDSAPublicKey{&priv.PublicKey}, nil
+}
+
+// GenerateHardenedECDSAKeyPair generates a new ecdsa private and public key
+// with the given curve, whose signatures use hardened signing, see
+// ECDSAPrivateKey.Hardened.
+func GenerateHardenedECDSAKeyPair(curve elliptic.Curve, src io.Reader) (PrivKey, PubKey, error) {
+	priv, err := ecdsa.GenerateKey(curve, src)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ECDSAPrivateKey{priv: priv, hardened: true}, &ECDSAPublicKey{&priv.PublicKey}, nil
+}
+
+// Hardened returns a copy of the key that signs with hardened signing, for
+// deployments where side-channels are a concern: nonces are derived
+// deterministically from the key and the message as specified by RFC 6979,
+// so that signing doesn't depend on the quality of the random source, and
+// the signature is computed with the constant-time arithmetic of
+// crypto/ecdsa. Signatures are standard ECDSA signatures, verified like any
+// other.
+//
+// Deterministic signing requires Go 1.24 or later. With earlier versions,
+// hardened keys sign like other keys, with the hedged nonces of ecdsa.Sign,
+// which also mix the key and the message with the random source.
+//
+// The hardened setting isn't serialized: unmarshalled keys must be hardened
+// again.
+func (ePriv *ECDSAPrivateKey) Hardened() *ECDSAPrivateKey {
+	return &ECDSAPrivateKey{priv: ePriv.priv, hardened: true}
 }
 
 // ECDSAKeyPairFromKey generates a new ecdsa private and public key from an input private key
@@ -65,7 +99,7 @@ func ECDSAKeyPairFromKey(priv *ecdsa.PrivateKey) (PrivKey, PubKey, error) {
 		return nil, nil, ErrNilPrivateKey
 	}
 
-	return &ECDSAPrivateKey{priv}, &ECDSAPublicKey{&priv.PublicKey}, nil
+	return &ECDSAPrivateKey{priv: priv}, &ECDSAPublicKey{&priv.PublicKey}, nil
 }
 
 // ECDSAPublicKeyFromPubKey generates a new ecdsa public key from an input public key
@@ -94,7 +128,7 @@ func UnmarshalECDSAPrivateKey(data []byte) (res PrivKey, err error) {
 		return nil, err
 	}
 
-	return &ECDSAPrivateKey{priv}, nil
+	return &ECDSAPrivateKey{priv: priv}, nil
 }
 
 // UnmarshalECDSAPublicKey returns the public key from x509 bytes
@@ -134,6 +168,9 @@ func (ePriv *ECDSAPrivateKey) Equals(o Key) bool {
 func (ePriv *ECDSAPrivateKey) Sign(data []byte) (sig []byte, err error) {
 	defer func() { catch.HandlePanic(recover(), &err, "ECDSA signing") }()
 	hash := sha256.Sum256(data)
+	if ePriv.hardened {
+		return signECDSAHardened(ePriv.priv, hash[:])
+	}
 	r, s, err := ecdsa.Sign(rand.Reader, ePriv.priv, hash[:])
 	if err != nil {
 		return nil, err
@@ -185,3 +222,8 @@ func (ePub *ECDSAPublicKey) Verify(data, sigBytes []byte) (success bool, err err
 
 	return ecdsa.Verify(ePub.pub, hash[:], sig.R, sig.S), nil
 }
+
+// Zeroize wipes the key material from memory.
+func (ePriv *ECDSAPrivateKey) Zeroize() {
+	sensitive.WipeBigInt(ePriv.priv.D)
+}
@@ -0,0 +1,14 @@
+//go:build go1.24
+
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+)
+
+// signECDSAHardened signs hash with an RFC 6979 nonce, which crypto/ecdsa
+// derives when not given a random source.
+func signECDSAHardened(priv *ecdsa.PrivateKey, hash []byte) ([]byte, error) {
+	return priv.Sign(nil, hash, crypto.SHA256)
+}
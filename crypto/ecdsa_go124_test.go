@@ -0,0 +1,40 @@
+//go:build go1.24
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+)
+
+func TestECDSAHardenedSigningRFC6979(t *testing.T) {
+	// RFC 6979, appendix A.2.5: P-256, SHA-256, message "sample"
+	d, _ := new(big.Int).SetString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721", 16)
+	expectedR, _ := new(big.Int).SetString("EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716", 16)
+	expectedS, _ := new(big.Int).SetString("F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8", 16)
+
+	key := &ecdsa.PrivateKey{D: d}
+	key.Curve = elliptic.P256()
+	key.X, key.Y = key.Curve.ScalarBaseMult(d.Bytes())
+	priv := (&ECDSAPrivateKey{priv: key}).Hardened()
+
+	for i := 0; i < 2; i++ {
+		sigBytes, err := priv.Sign([]byte("sample"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sig ECDSASig
+		if _, err := asn1.Unmarshal(sigBytes, &sig); err != nil {
+			t.Fatal(err)
+		}
+		if sig.R.Cmp(expectedR) != 0 || sig.S.Cmp(expectedS) != 0 {
+			t.Fatalf("unexpected signature (%x, %x)", sig.R, sig.S)
+		}
+		if ok, err := priv.GetPublic().Verify([]byte("sample"), sigBytes); !ok || err != nil {
+			t.Fatalf("hardened signature should verify: %v", err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !go1.24
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+)
+
+// signECDSAHardened signs hash with a hedged nonce, as crypto/ecdsa only
+// supports RFC 6979 nonces from Go 1.24 on.
+func signECDSAHardened(priv *ecdsa.PrivateKey, hash []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, priv, hash)
+}
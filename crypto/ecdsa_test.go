@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"testing"
 )
@@ -140,3 +141,29 @@ func TestECDSAPublicKeyFromPubKey(t *testing.T) {
 		t.Fatal("keys are not equal")
 	}
 }
+
+func TestECDSAHardenedSigning(t *testing.T) {
+	hardened, pub, err := GenerateHardenedECDSAKeyPair(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := hardened.Sign([]byte("data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := pub.Verify([]byte("data"), sig); !ok || err != nil {
+		t.Fatalf("hardened P-384 signature should verify: %v", err)
+	}
+
+	priv, _, err := GenerateECDSAKeyPair(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := priv.(*ECDSAPrivateKey).Hardened()
+	if priv.(*ECDSAPrivateKey).hardened || !h.hardened {
+		t.Fatal("expected Hardened to return a hardened copy of the key")
+	}
+	if !h.Equals(priv) {
+		t.Fatal("expected the hardened key to equal the original key")
+	}
+}
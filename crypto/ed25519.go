@@ -1,7 +1,6 @@
 package crypto
 
 import (
-	"bytes"
 	"crypto/ed25519"
 	"crypto/subtle"
 	"errors"
@@ -9,6 +8,7 @@ import (
 	"io"
 
 	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+	"github.com/libp2p/go-libp2p-core/crypto/sensitive"
 	"github.com/libp2p/go-libp2p-core/internal/catch"
 )
 
@@ -98,7 +98,7 @@ func (k *Ed25519PublicKey) Equals(o Key) bool {
 		return basicEquals(k, o)
 	}
 
-	return bytes.Equal(k.k, edk.k)
+	return subtle.ConstantTimeCompare(k.k, edk.k) == 1
 }
 
 // Verify checks a signature agains the input data.
@@ -154,3 +154,8 @@ func UnmarshalEd25519PrivateKey(data []byte) (PrivKey, error) {
 		k: ed25519.PrivateKey(data),
 	}, nil
 }
+
+// Zeroize wipes the key material from memory.
+func (k *Ed25519PrivateKey) Zeroize() {
+	sensitive.Wipe(k.k)
+}
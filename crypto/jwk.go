@@ -0,0 +1,355 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// ErrInvalidJWK is returned when a JSON Web Key is malformed or describes a
+// key type that isn't supported.
+var ErrInvalidJWK = errors.New("invalid or unsupported JWK")
+
+// jwk is a JSON Web Key, as defined in RFC 7517, RFC 7518, RFC 8037 (Ed25519)
+// and RFC 8812 (secp256k1).
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+
+	// EC and OKP
+	X string `json:"x,omitempty"`
+	Y string `json:"y,omitempty"`
+
+	// RSA
+	N  string `json:"n,omitempty"`
+	E  string `json:"e,omitempty"`
+	P  string `json:"p,omitempty"`
+	Q  string `json:"q,omitempty"`
+	DP string `json:"dp,omitempty"`
+	DQ string `json:"dq,omitempty"`
+	QI string `json:"qi,omitempty"`
+
+	// private exponent or scalar, all key types
+	D string `json:"d,omitempty"`
+}
+
+const secp256k1FieldBytes = 32
+
+func jwkEncode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func jwkEncodeInt(i *big.Int, size int) string {
+	b := i.Bytes()
+	if len(b) < size {
+		b = append(make([]byte, size-len(b)), b...)
+	}
+	return jwkEncode(b)
+}
+
+func jwkDecode(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: missing field", ErrInvalidJWK)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidJWK, err)
+	}
+	return b, nil
+}
+
+func jwkDecodeInt(s string) (*big.Int, error) {
+	b, err := jwkDecode(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func jwkCurve(crv string) elliptic.Curve {
+	switch crv {
+	case "P-256":
+		return elliptic.P256()
+	case "P-384":
+		return elliptic.P384()
+	case "P-521":
+		return elliptic.P521()
+	default:
+		return nil
+	}
+}
+
+func curveBytes(c elliptic.Curve) int {
+	return (c.Params().BitSize + 7) / 8
+}
+
+func jwkFromStdPublicKey(k interface{}) (*jwk, error) {
+	switch k := k.(type) {
+	case *rsa.PublicKey:
+		return &jwk{
+			Kty: "RSA",
+			N:   jwkEncode(k.N.Bytes()),
+			E:   jwkEncode(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		size := curveBytes(k.Curve)
+		return &jwk{
+			Kty: "EC",
+			Crv: k.Curve.Params().Name,
+			X:   jwkEncodeInt(k.X, size),
+			Y:   jwkEncodeInt(k.Y, size),
+		}, nil
+	case ed25519.PublicKey:
+		return &jwk{Kty: "OKP", Crv: "Ed25519", X: jwkEncode(k)}, nil
+	case *btcec.PublicKey:
+		return &jwk{
+			Kty: "EC",
+			Crv: "secp256k1",
+			X:   jwkEncodeInt(k.X(), secp256k1FieldBytes),
+			Y:   jwkEncodeInt(k.Y(), secp256k1FieldBytes),
+		}, nil
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+func jwkFromStdPrivateKey(k interface{}) (*jwk, error) {
+	switch k := k.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, errors.New("multi-prime rsa keys are not supported")
+		}
+		k.Precompute()
+		j, err := jwkFromStdPublicKey(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		j.D = jwkEncode(k.D.Bytes())
+		j.P = jwkEncode(k.Primes[0].Bytes())
+		j.Q = jwkEncode(k.Primes[1].Bytes())
+		j.DP = jwkEncode(k.Precomputed.Dp.Bytes())
+		j.DQ = jwkEncode(k.Precomputed.Dq.Bytes())
+		j.QI = jwkEncode(k.Precomputed.Qinv.Bytes())
+		return j, nil
+	case *ecdsa.PrivateKey:
+		j, err := jwkFromStdPublicKey(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		j.D = jwkEncodeInt(k.D, curveBytes(k.Curve))
+		return j, nil
+	case ed25519.PrivateKey:
+		j, err := jwkFromStdPublicKey(k.Public())
+		if err != nil {
+			return nil, err
+		}
+		j.D = jwkEncode(k.Seed())
+		return j, nil
+	case *btcec.PrivateKey:
+		j, err := jwkFromStdPublicKey(k.PubKey())
+		if err != nil {
+			return nil, err
+		}
+		j.D = jwkEncode(k.Serialize())
+		return j, nil
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+// stdPublicKey returns the standard library (or btcec) public key described
+// by the JWK.
+func (j *jwk) stdPublicKey() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := jwkDecodeInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwkDecodeInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("%w: rsa exponent too large", ErrInvalidJWK)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		x, err := jwkDecodeInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := jwkDecodeInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		if j.Crv == "secp256k1" {
+			point := append([]byte{0x04}, make([]byte, 2*secp256k1FieldBytes)...)
+			if len(x.Bytes()) > secp256k1FieldBytes || len(y.Bytes()) > secp256k1FieldBytes {
+				return nil, fmt.Errorf("%w: invalid secp256k1 point", ErrInvalidJWK)
+			}
+			x.FillBytes(point[1 : 1+secp256k1FieldBytes])
+			y.FillBytes(point[1+secp256k1FieldBytes:])
+			pk, err := btcec.ParsePubKey(point)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidJWK, err)
+			}
+			return pk, nil
+		}
+		curve := jwkCurve(j.Crv)
+		if curve == nil {
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidJWK, j.Crv)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("%w: point not on curve", ErrInvalidJWK)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("%w: unsupported curve %q", ErrInvalidJWK, j.Crv)
+		}
+		x, err := jwkDecode(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: invalid ed25519 public key", ErrInvalidJWK)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %q", ErrInvalidJWK, j.Kty)
+	}
+}
+
+// stdPrivateKey returns the standard library (or btcec) private key described
+// by the JWK, checking that it matches the public part.
+func (j *jwk) stdPrivateKey() (interface{}, error) {
+	pub, err := j.stdPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		d, err := jwkDecodeInt(j.D)
+		if err != nil {
+			return nil, err
+		}
+		p, err := jwkDecodeInt(j.P)
+		if err != nil {
+			return nil, err
+		}
+		q, err := jwkDecodeInt(j.Q)
+		if err != nil {
+			return nil, err
+		}
+		sk := &rsa.PrivateKey{PublicKey: *pub, D: d, Primes: []*big.Int{p, q}}
+		if err := sk.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidJWK, err)
+		}
+		sk.Precompute()
+		return sk, nil
+	case *ecdsa.PublicKey:
+		d, err := jwkDecodeInt(j.D)
+		if err != nil {
+			return nil, err
+		}
+		x, y := pub.Curve.ScalarBaseMult(d.Bytes())
+		if x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+			return nil, fmt.Errorf("%w: private key doesn't match public key", ErrInvalidJWK)
+		}
+		return &ecdsa.PrivateKey{PublicKey: *pub, D: d}, nil
+	case ed25519.PublicKey:
+		seed, err := jwkDecode(j.D)
+		if err != nil {
+			return nil, err
+		}
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("%w: invalid ed25519 private key", ErrInvalidJWK)
+		}
+		sk := ed25519.NewKeyFromSeed(seed)
+		if !bytes.Equal(sk.Public().(ed25519.PublicKey), pub) {
+			return nil, fmt.Errorf("%w: private key doesn't match public key", ErrInvalidJWK)
+		}
+		return sk, nil
+	case *btcec.PublicKey:
+		d, err := jwkDecode(j.D)
+		if err != nil {
+			return nil, err
+		}
+		if len(d) != btcec.PrivKeyBytesLen {
+			return nil, fmt.Errorf("%w: invalid secp256k1 private key", ErrInvalidJWK)
+		}
+		sk, pk := btcec.PrivKeyFromBytes(d)
+		if !pk.IsEqual(pub) {
+			return nil, fmt.Errorf("%w: private key doesn't match public key", ErrInvalidJWK)
+		}
+		return sk, nil
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+// MarshalPublicKeyJWK encodes a public key as a JSON Web Key, for use with
+// WebCrypto and cloud key management tooling. Secp256k1 keys use the
+// "secp256k1" curve name defined in RFC 8812.
+func MarshalPublicKeyJWK(k PubKey) ([]byte, error) {
+	std, err := stdPublicKey(k)
+	if err != nil {
+		return nil, err
+	}
+	j, err := jwkFromStdPublicKey(std)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// MarshalPrivateKeyJWK encodes a private key as a JSON Web Key. The JWK
+// contains the private key material in the clear.
+func MarshalPrivateKeyJWK(k PrivKey) ([]byte, error) {
+	std, err := stdPrivateKey(k)
+	if err != nil {
+		return nil, err
+	}
+	j, err := jwkFromStdPrivateKey(std)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalPublicKeyJWK decodes a public key from a JSON Web Key. Private key
+// members, if any, are ignored.
+func UnmarshalPublicKeyJWK(data []byte) (PubKey, error) {
+	var j jwk
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidJWK, err)
+	}
+	std, err := j.stdPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return fromStdPublicKey(std)
+}
+
+// UnmarshalPrivateKeyJWK decodes a private key from a JSON Web Key.
+func UnmarshalPrivateKeyJWK(data []byte) (PrivKey, error) {
+	var j jwk
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidJWK, err)
+	}
+	std, err := j.stdPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return fromStdPrivateKey(std)
+}
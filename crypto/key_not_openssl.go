@@ -23,7 +23,7 @@ func KeyPairFromStdKey(priv crypto.PrivateKey) (PrivKey, PubKey, error) {
 		return &RsaPrivateKey{*p}, &RsaPublicKey{k: p.PublicKey}, nil
 
 	case *ecdsa.PrivateKey:
-		return &ECDSAPrivateKey{p}, &ECDSAPublicKey{&p.PublicKey}, nil
+		return &ECDSAPrivateKey{priv: p}, &ECDSAPublicKey{&p.PublicKey}, nil
 
 	case *ed25519.PrivateKey:
 		pubIfc := p.Public()
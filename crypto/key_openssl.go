@@ -33,7 +33,7 @@ func KeyPairFromStdKey(priv crypto.PrivateKey) (_priv PrivKey, _pub PubKey, err
 		return &opensslPrivateKey{pk}, &opensslPublicKey{key: pk}, nil
 
 	case *ecdsa.PrivateKey:
-		return &ECDSAPrivateKey{p}, &ECDSAPublicKey{&p.PublicKey}, nil
+		return &ECDSAPrivateKey{priv: p}, &ECDSAPublicKey{&p.PublicKey}, nil
 
 	case *ed25519.PrivateKey:
 		pubIfc := p.Public()
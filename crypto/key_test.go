@@ -302,3 +302,33 @@ func TestUnknownCurveErrors(t *testing.T) {
 		t.Fatal("expected invalid key type to error")
 	}
 }
+
+func TestZeroize(t *testing.T) {
+	for _, typ := range KeyTypes {
+		if typ == RSA {
+			// Only the Go implementation of RSA keys supports
+			// zeroization, see TestRsaZeroize.
+			continue
+		}
+		priv, _, err := test.RandTestKeyPair(typ, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Zeroize(priv) {
+			t.Fatalf("expected key type %d to support zeroization", typ)
+		}
+	}
+
+	priv, _, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Zeroize(priv)
+	raw, err := priv.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, make([]byte, len(raw))) {
+		t.Fatal("expected key material to be wiped")
+	}
+}
@@ -0,0 +1,331 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// ErrNoPEMBlock is returned when PEM data doesn't contain a key block.
+var ErrNoPEMBlock = errors.New("no PEM key block found")
+
+const (
+	pemTypePrivateKey    = "PRIVATE KEY"
+	pemTypeECPrivateKey  = "EC PRIVATE KEY"
+	pemTypeRSAPrivateKey = "RSA PRIVATE KEY"
+	pemTypePublicKey     = "PUBLIC KEY"
+)
+
+var (
+	oidECPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidSecp256k1   = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+// The x509 package doesn't support secp256k1, so secp256k1 keys are encoded
+// with the following structures, as defined in RFC 5208, RFC 5915 and RFC
+// 5480.
+
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+func secp256k1AlgorithmIdentifier() (pkix.AlgorithmIdentifier, error) {
+	params, err := asn1.Marshal(oidSecp256k1)
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, err
+	}
+	return pkix.AlgorithmIdentifier{
+		Algorithm:  oidECPublicKey,
+		Parameters: asn1.RawValue{FullBytes: params},
+	}, nil
+}
+
+func isSecp256k1Algorithm(algo pkix.AlgorithmIdentifier) bool {
+	var curve asn1.ObjectIdentifier
+	if !algo.Algorithm.Equal(oidECPublicKey) {
+		return false
+	}
+	if _, err := asn1.Unmarshal(algo.Parameters.FullBytes, &curve); err != nil {
+		return false
+	}
+	return curve.Equal(oidSecp256k1)
+}
+
+func marshalSecp256k1PKCS8(k *btcec.PrivateKey) ([]byte, error) {
+	sec1, err := asn1.Marshal(ecPrivateKey{
+		Version:    1,
+		PrivateKey: k.Serialize(),
+		PublicKey:  asn1.BitString{Bytes: k.PubKey().SerializeUncompressed()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	algo, err := secp256k1AlgorithmIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8{Algo: algo, PrivateKey: sec1})
+}
+
+func parseSecp256k1PKCS8(der []byte) (*btcec.PrivateKey, error) {
+	var p pkcs8
+	if _, err := asn1.Unmarshal(der, &p); err != nil {
+		return nil, err
+	}
+	if !isSecp256k1Algorithm(p.Algo) {
+		return nil, ErrBadKeyType
+	}
+	return parseSecp256k1SEC1(p.PrivateKey, true)
+}
+
+func parseSecp256k1SEC1(der []byte, curveImplied bool) (*btcec.PrivateKey, error) {
+	var k ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &k); err != nil {
+		return nil, err
+	}
+	if !curveImplied && !k.NamedCurveOID.Equal(oidSecp256k1) {
+		return nil, ErrBadKeyType
+	}
+	if k.Version != 1 || len(k.PrivateKey) != btcec.PrivKeyBytesLen {
+		return nil, errors.New("invalid secp256k1 private key")
+	}
+	priv, _ := btcec.PrivKeyFromBytes(k.PrivateKey)
+	return priv, nil
+}
+
+func marshalSecp256k1PKIX(k *btcec.PublicKey) ([]byte, error) {
+	algo, err := secp256k1AlgorithmIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: algo,
+		PublicKey: asn1.BitString{Bytes: k.SerializeUncompressed()},
+	})
+}
+
+func parseSecp256k1PKIX(der []byte) (*btcec.PublicKey, error) {
+	var spki subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(der, &spki); err != nil {
+		return nil, err
+	}
+	if !isSecp256k1Algorithm(spki.Algorithm) {
+		return nil, ErrBadKeyType
+	}
+	return btcec.ParsePubKey(spki.PublicKey.RightAlign())
+}
+
+// stdPrivateKey returns the standard library (or btcec) representation of k.
+// Unlike PrivKeyToStdKey, it doesn't depend on the key implementation.
+func stdPrivateKey(k PrivKey) (interface{}, error) {
+	if k == nil {
+		return nil, ErrNilPrivateKey
+	}
+	raw, err := k.Raw()
+	if err != nil {
+		return nil, err
+	}
+	switch k.Type() {
+	case pb.KeyType_RSA:
+		return x509.ParsePKCS1PrivateKey(raw)
+	case pb.KeyType_ECDSA:
+		return x509.ParseECPrivateKey(raw)
+	case pb.KeyType_Ed25519:
+		if len(raw) < ed25519.PrivateKeySize {
+			return nil, errors.New("invalid ed25519 private key")
+		}
+		return ed25519.PrivateKey(raw[:ed25519.PrivateKeySize]), nil
+	case pb.KeyType_Secp256k1:
+		priv, _ := btcec.PrivKeyFromBytes(raw)
+		return priv, nil
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+// stdPublicKey returns the standard library (or btcec) representation of k.
+func stdPublicKey(k PubKey) (interface{}, error) {
+	if k == nil {
+		return nil, ErrNilPublicKey
+	}
+	raw, err := k.Raw()
+	if err != nil {
+		return nil, err
+	}
+	switch k.Type() {
+	case pb.KeyType_RSA, pb.KeyType_ECDSA:
+		return x509.ParsePKIXPublicKey(raw)
+	case pb.KeyType_Ed25519:
+		return ed25519.PublicKey(raw), nil
+	case pb.KeyType_Secp256k1:
+		return btcec.ParsePubKey(raw)
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+// fromStdPrivateKey is the inverse of stdPrivateKey. RSA keys are subject to
+// the minimum RSA key size.
+func fromStdPrivateKey(k interface{}) (PrivKey, error) {
+	switch k := k.(type) {
+	case *rsa.PrivateKey:
+		return UnmarshalRsaPrivateKey(x509.MarshalPKCS1PrivateKey(k))
+	case *ecdsa.PrivateKey:
+		priv, _, err := ECDSAKeyPairFromKey(k)
+		return priv, err
+	case ed25519.PrivateKey:
+		return UnmarshalEd25519PrivateKey(k)
+	case *btcec.PrivateKey:
+		return UnmarshalSecp256k1PrivateKey(k.Serialize())
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+// fromStdPublicKey is the inverse of stdPublicKey.
+func fromStdPublicKey(k interface{}) (PubKey, error) {
+	switch k := k.(type) {
+	case *rsa.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return UnmarshalRsaPublicKey(der)
+	case *ecdsa.PublicKey:
+		return ECDSAPublicKeyFromPubKey(*k)
+	case ed25519.PublicKey:
+		return UnmarshalEd25519PublicKey(k)
+	case *btcec.PublicKey:
+		return UnmarshalSecp256k1PublicKey(k.SerializeCompressed())
+	default:
+		return nil, ErrBadKeyType
+	}
+}
+
+// MarshalPrivateKeyPEM encodes a private key as a PKCS #8 "PRIVATE KEY" PEM
+// block, as produced by `openssl genpkey`. Secp256k1 keys are encoded with
+// the id-ecPublicKey algorithm and the secp256k1 named curve.
+func MarshalPrivateKeyPEM(k PrivKey) ([]byte, error) {
+	std, err := stdPrivateKey(k)
+	if err != nil {
+		return nil, err
+	}
+	var der []byte
+	if sk, ok := std.(*btcec.PrivateKey); ok {
+		der, err = marshalSecp256k1PKCS8(sk)
+	} else {
+		der, err = x509.MarshalPKCS8PrivateKey(std)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: der}), nil
+}
+
+// UnmarshalPrivateKeyPEM decodes the first private key PEM block in data. It
+// supports PKCS #8 ("PRIVATE KEY"), SEC 1 ("EC PRIVATE KEY") and PKCS #1
+// ("RSA PRIVATE KEY") blocks. Encrypted PEM blocks aren't supported.
+func UnmarshalPrivateKeyPEM(data []byte) (PrivKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, ErrNoPEMBlock
+		}
+		var (
+			std interface{}
+			err error
+		)
+		switch block.Type {
+		case pemTypePrivateKey:
+			std, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				if sk, err2 := parseSecp256k1PKCS8(block.Bytes); err2 == nil {
+					std, err = sk, nil
+				}
+			}
+		case pemTypeECPrivateKey:
+			std, err = x509.ParseECPrivateKey(block.Bytes)
+			if err != nil {
+				if sk, err2 := parseSecp256k1SEC1(block.Bytes, false); err2 == nil {
+					std, err = sk, nil
+				}
+			}
+		case pemTypeRSAPrivateKey:
+			std, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		default:
+			// skip other blocks, such as EC PARAMETERS
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", block.Type, err)
+		}
+		return fromStdPrivateKey(std)
+	}
+}
+
+// MarshalPublicKeyPEM encodes a public key as a PKIX "PUBLIC KEY" PEM block.
+func MarshalPublicKeyPEM(k PubKey) ([]byte, error) {
+	std, err := stdPublicKey(k)
+	if err != nil {
+		return nil, err
+	}
+	var der []byte
+	if pk, ok := std.(*btcec.PublicKey); ok {
+		der, err = marshalSecp256k1PKIX(pk)
+	} else {
+		der, err = x509.MarshalPKIXPublicKey(std)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der}), nil
+}
+
+// UnmarshalPublicKeyPEM decodes the first PKIX "PUBLIC KEY" PEM block in data.
+func UnmarshalPublicKeyPEM(data []byte) (PubKey, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, ErrNoPEMBlock
+		}
+		if block.Type != pemTypePublicKey {
+			continue
+		}
+		std, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			if pk, err2 := parseSecp256k1PKIX(block.Bytes); err2 == nil {
+				std, err = pk, nil
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", block.Type, err)
+		}
+		return fromStdPublicKey(std)
+	}
+}
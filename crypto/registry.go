@@ -0,0 +1,41 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+)
+
+// ErrKeyTypeRegistered is returned by RegisterKeyType when an unmarshaller
+// for the key type is already registered.
+var ErrKeyTypeRegistered = errors.New("key type already registered")
+
+// RegisterKeyType registers the unmarshallers for an external key type, e.g.
+// an experimental or post-quantum signature scheme, so that keys of that type
+// round-trip through UnmarshalPublicKey, UnmarshalPrivateKey and their Marshal
+// counterparts, and can be used to sign envelopes and derive peer IDs.
+//
+// The keys returned by the unmarshallers must report typ from their Type
+// method. The private key unmarshaller may be nil for verification-only key
+// types.
+//
+// RegisterKeyType is not safe for concurrent use, and should be called from an
+// init function. Registering one of the built-in key types or a key type that
+// has already been registered returns ErrKeyTypeRegistered.
+func RegisterKeyType(typ pb.KeyType, pub PubKeyUnmarshaller, priv PrivKeyUnmarshaller) error {
+	if pub == nil {
+		return fmt.Errorf("key type %s: public key unmarshaller must not be nil", typ)
+	}
+	if _, ok := PubKeyUnmarshallers[typ]; ok {
+		return fmt.Errorf("key type %s: %w", typ, ErrKeyTypeRegistered)
+	}
+	if _, ok := PrivKeyUnmarshallers[typ]; ok {
+		return fmt.Errorf("key type %s: %w", typ, ErrKeyTypeRegistered)
+	}
+	PubKeyUnmarshallers[typ] = pub
+	if priv != nil {
+		PrivKeyUnmarshallers[typ] = priv
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package crypto_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/crypto"
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+)
+
+const experimentalKeyType pb.KeyType = 0x7f
+
+type experimentalPubKey struct{ PubKey }
+
+func (k experimentalPubKey) Type() pb.KeyType { return experimentalKeyType }
+
+type experimentalPrivKey struct{ PrivKey }
+
+func (k experimentalPrivKey) Type() pb.KeyType { return experimentalKeyType }
+
+func (k experimentalPrivKey) GetPublic() PubKey {
+	return experimentalPubKey{k.PrivKey.GetPublic()}
+}
+
+func TestRegisterKeyType(t *testing.T) {
+	err := RegisterKeyType(experimentalKeyType,
+		func(data []byte) (PubKey, error) {
+			k, err := UnmarshalEd25519PublicKey(data)
+			return experimentalPubKey{k}, err
+		},
+		func(data []byte) (PrivKey, error) {
+			k, err := UnmarshalEd25519PrivateKey(data)
+			return experimentalPrivKey{k}, err
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterKeyType(experimentalKeyType, UnmarshalEd25519PublicKey, nil); !errors.Is(err, ErrKeyTypeRegistered) {
+		t.Fatalf("expected ErrKeyTypeRegistered, got %v", err)
+	}
+	if err := RegisterKeyType(pb.KeyType_Ed25519, UnmarshalEd25519PublicKey, nil); !errors.Is(err, ErrKeyTypeRegistered) {
+		t.Fatalf("expected ErrKeyTypeRegistered for a built-in type, got %v", err)
+	}
+
+	edPriv, _, err := GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv := experimentalPrivKey{edPriv}
+
+	privBytes, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, err := UnmarshalPrivateKey(privBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv2.Type() != experimentalKeyType {
+		t.Fatalf("expected key type %d, got %d", experimentalKeyType, priv2.Type())
+	}
+
+	pubBytes, err := MarshalPublicKey(priv.GetPublic())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := UnmarshalPublicKey(pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.Type() != experimentalKeyType {
+		t.Fatalf("expected key type %d, got %d", experimentalKeyType, pub.Type())
+	}
+
+	msg := []byte("hello")
+	sig, err := priv2.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := pub.Verify(msg, sig); err != nil || !ok {
+		t.Fatalf("expected signature to verify: %v", err)
+	}
+}
@@ -1,8 +1,14 @@
 package crypto
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+
+	"github.com/gogo/protobuf/proto"
 )
 
 // WeakRsaKeyEnv is an environment variable which, when set, lowers the
@@ -10,16 +16,136 @@ import (
 // test situations.
 const WeakRsaKeyEnv = "LIBP2P_ALLOW_WEAK_RSA_KEYS"
 
+// MinRsaKeyBitsFloor is the lowest value accepted by SetMinRSAKeyBits and
+// WithMinRSAKeyBits.
+const MinRsaKeyBitsFloor = 512
+
+// MinRsaKeyBits is the minimum size of RSA keys accepted by
+// GenerateRSAKeyPair and the RSA key unmarshallers. It is lowered to 512 if
+// WeakRsaKeyEnv is set.
+//
+// To change it while keys may be in use, call SetMinRSAKeyBits rather than
+// assigning it directly.
 var MinRsaKeyBits = 2048
 
+// defaultMinRsaKeyBits is the value of MinRsaKeyBits after init, restored by
+// ResetMinRSAKeyBits.
+var defaultMinRsaKeyBits int
+
+// minRsaKeyBitsLk guards the accesses to MinRsaKeyBits made through
+// SetMinRSAKeyBits, ResetMinRSAKeyBits and minRSAKeyBits.
+var minRsaKeyBitsLk sync.RWMutex
+
 // ErrRsaKeyTooSmall is returned when trying to generate or parse an RSA key
-// that's smaller than MinRsaKeyBits bits. In test
+// that's smaller than MinRsaKeyBits bits. In test situations, the minimum can
+// be lowered with WeakRsaKeyEnv or SetMinRSAKeyBits.
 var ErrRsaKeyTooSmall error
 
 func init() {
 	if _, ok := os.LookupEnv(WeakRsaKeyEnv); ok {
 		MinRsaKeyBits = 512
 	}
+	defaultMinRsaKeyBits = MinRsaKeyBits
 
 	ErrRsaKeyTooSmall = fmt.Errorf("rsa keys must be >= %d bits to be useful", MinRsaKeyBits)
 }
+
+// SetMinRSAKeyBits sets MinRsaKeyBits, e.g. to allow smaller keys in test
+// networks or when interoperating with legacy deployments. It returns an
+// error if bits is lower than MinRsaKeyBitsFloor.
+//
+// SetMinRSAKeyBits is safe for concurrent use, but should be called from an
+// init function or before the node is started, so that the same keys are
+// accepted throughout its lifetime. To relax the limit for a single
+// operation, use WithMinRSAKeyBits instead.
+func SetMinRSAKeyBits(bits int) error {
+	if bits < MinRsaKeyBitsFloor {
+		return fmt.Errorf("minimum rsa key size must be >= %d bits, got %d", MinRsaKeyBitsFloor, bits)
+	}
+	minRsaKeyBitsLk.Lock()
+	MinRsaKeyBits = bits
+	minRsaKeyBitsLk.Unlock()
+	return nil
+}
+
+// ResetMinRSAKeyBits restores MinRsaKeyBits to its initial value: 2048, or
+// 512 if WeakRsaKeyEnv is set.
+func ResetMinRSAKeyBits() {
+	minRsaKeyBitsLk.Lock()
+	MinRsaKeyBits = defaultMinRsaKeyBits
+	minRsaKeyBitsLk.Unlock()
+}
+
+func minRSAKeyBits() int {
+	minRsaKeyBitsLk.RLock()
+	defer minRsaKeyBitsLk.RUnlock()
+	return MinRsaKeyBits
+}
+
+type minRsaKeyBitsCtxKey struct{}
+
+// WithMinRSAKeyBits returns a context overriding the minimum size of RSA keys
+// accepted by UnmarshalPublicKeyContext and UnmarshalPrivateKeyContext. Values
+// lower than MinRsaKeyBitsFloor are raised to MinRsaKeyBitsFloor.
+func WithMinRSAKeyBits(ctx context.Context, bits int) context.Context {
+	if bits < MinRsaKeyBitsFloor {
+		bits = MinRsaKeyBitsFloor
+	}
+	return context.WithValue(ctx, minRsaKeyBitsCtxKey{}, bits)
+}
+
+// GetMinRSAKeyBits returns the minimum size of RSA keys in effect for the
+// given context: the value set with WithMinRSAKeyBits if any, MinRsaKeyBits
+// otherwise.
+func GetMinRSAKeyBits(ctx context.Context) int {
+	if bits, ok := ctx.Value(minRsaKeyBitsCtxKey{}).(int); ok {
+		return bits
+	}
+	return minRSAKeyBits()
+}
+
+// UnmarshalPublicKeyContext behaves like UnmarshalPublicKey, enforcing the
+// minimum RSA key size returned by GetMinRSAKeyBits(ctx).
+func UnmarshalPublicKeyContext(ctx context.Context, data []byte) (PubKey, error) {
+	pmes := new(pb.PublicKey)
+	err := proto.Unmarshal(data, pmes)
+	if err != nil {
+		return nil, err
+	}
+	if pmes.GetType() != pb.KeyType_RSA {
+		return PublicKeyFromProto(pmes)
+	}
+	pk, err := unmarshalRsaPublicKey(pmes.GetData(), GetMinRSAKeyBits(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if tpk, ok := pk.(*RsaPublicKey); ok {
+		tpk.cached, _ = pmes.Marshal()
+	}
+	return pk, nil
+}
+
+// UnmarshalPrivateKeyContext behaves like UnmarshalPrivateKey, enforcing the
+// minimum RSA key size returned by GetMinRSAKeyBits(ctx).
+func UnmarshalPrivateKeyContext(ctx context.Context, data []byte) (PrivKey, error) {
+	pmes := new(pb.PrivateKey)
+	err := proto.Unmarshal(data, pmes)
+	if err != nil {
+		return nil, err
+	}
+	if pmes.GetType() != pb.KeyType_RSA {
+		um, ok := PrivKeyUnmarshallers[pmes.GetType()]
+		if !ok {
+			return nil, ErrBadKeyType
+		}
+		return um(pmes.GetData())
+	}
+	return unmarshalRsaPrivateKey(pmes.GetData(), GetMinRSAKeyBits(ctx))
+}
+
+func checkRsaKeySize(bits, minBits int) error {
+	if bits < minBits {
+		return ErrRsaKeyTooSmall
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"io"
 
 	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+	"github.com/libp2p/go-libp2p-core/crypto/sensitive"
 	"github.com/libp2p/go-libp2p-core/internal/catch"
 
 	"github.com/minio/sha256-simd"
@@ -31,8 +32,8 @@ type RsaPublicKey struct {
 
 // GenerateRSAKeyPair generates a new rsa private and public key
 func GenerateRSAKeyPair(bits int, src io.Reader) (PrivKey, PubKey, error) {
-	if bits < MinRsaKeyBits {
-		return nil, nil, ErrRsaKeyTooSmall
+	if err := checkRsaKeySize(bits, minRSAKeyBits()); err != nil {
+		return nil, nil, err
 	}
 	priv, err := rsa.GenerateKey(src, bits)
 	if err != nil {
@@ -118,20 +119,28 @@ func (sk *RsaPrivateKey) Equals(k Key) bool {
 }
 
 // UnmarshalRsaPrivateKey returns a private key from the input x509 bytes
-func UnmarshalRsaPrivateKey(b []byte) (key PrivKey, err error) {
+func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
+	return unmarshalRsaPrivateKey(b, minRSAKeyBits())
+}
+
+func unmarshalRsaPrivateKey(b []byte, minBits int) (key PrivKey, err error) {
 	defer func() { catch.HandlePanic(recover(), &err, "RSA private-key unmarshaling") }()
 	sk, err := x509.ParsePKCS1PrivateKey(b)
 	if err != nil {
 		return nil, err
 	}
-	if sk.N.BitLen() < MinRsaKeyBits {
-		return nil, ErrRsaKeyTooSmall
+	if err := checkRsaKeySize(sk.N.BitLen(), minBits); err != nil {
+		return nil, err
 	}
 	return &RsaPrivateKey{sk: *sk}, nil
 }
 
 // UnmarshalRsaPublicKey returns a public key from the input x509 bytes
-func UnmarshalRsaPublicKey(b []byte) (key PubKey, err error) {
+func UnmarshalRsaPublicKey(b []byte) (PubKey, error) {
+	return unmarshalRsaPublicKey(b, minRSAKeyBits())
+}
+
+func unmarshalRsaPublicKey(b []byte, minBits int) (key PubKey, err error) {
 	defer func() { catch.HandlePanic(recover(), &err, "RSA public-key unmarshaling") }()
 	pub, err := x509.ParsePKIXPublicKey(b)
 	if err != nil {
@@ -141,9 +150,28 @@ func UnmarshalRsaPublicKey(b []byte) (key PubKey, err error) {
 	if !ok {
 		return nil, errors.New("not actually an rsa public key")
 	}
-	if pk.N.BitLen() < MinRsaKeyBits {
-		return nil, ErrRsaKeyTooSmall
+	if err := checkRsaKeySize(pk.N.BitLen(), minBits); err != nil {
+		return nil, err
 	}
 
 	return &RsaPublicKey{k: *pk}, nil
 }
+
+// Zeroize wipes the key material exposed by crypto/rsa from memory: the
+// private exponent, the primes and the precomputed CRT values. Since Go 1.20,
+// crypto/rsa also keeps copies of the key material in unexported precomputed
+// values, which can't be reached and are not wiped.
+func (sk *RsaPrivateKey) Zeroize() {
+	sensitive.WipeBigInt(sk.sk.D)
+	for _, p := range sk.sk.Primes {
+		sensitive.WipeBigInt(p)
+	}
+	sensitive.WipeBigInt(sk.sk.Precomputed.Dp)
+	sensitive.WipeBigInt(sk.sk.Precomputed.Dq)
+	sensitive.WipeBigInt(sk.sk.Precomputed.Qinv)
+	for _, crt := range sk.sk.Precomputed.CRTValues {
+		sensitive.WipeBigInt(crt.Exp)
+		sensitive.WipeBigInt(crt.Coeff)
+		sensitive.WipeBigInt(crt.R)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build !openssl
+// +build !openssl
+
+package crypto
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestRsaZeroize(t *testing.T) {
+	priv, _, err := GenerateRSAKeyPair(2048, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Zeroize(priv) {
+		t.Fatal("expected RSA keys to support zeroization")
+	}
+
+	sk := priv.(*RsaPrivateKey).sk
+	secrets := []*big.Int{sk.D, sk.Precomputed.Dp, sk.Precomputed.Dq, sk.Precomputed.Qinv}
+	secrets = append(secrets, sk.Primes...)
+	for _, crt := range sk.Precomputed.CRTValues {
+		secrets = append(secrets, crt.Exp, crt.Coeff, crt.R)
+	}
+	for i, n := range secrets {
+		if n.Sign() != 0 {
+			t.Fatalf("expected key material %d to be wiped", i)
+		}
+	}
+	if sk.N.Sign() == 0 {
+		t.Fatal("expected the public modulus to be kept")
+	}
+}
@@ -22,8 +22,8 @@ type RsaPublicKey struct {
 
 // GenerateRSAKeyPair generates a new rsa private and public key
 func GenerateRSAKeyPair(bits int, _ io.Reader) (PrivKey, PubKey, error) {
-	if bits < MinRsaKeyBits {
-		return nil, nil, ErrRsaKeyTooSmall
+	if err := checkRsaKeySize(bits, minRSAKeyBits()); err != nil {
+		return nil, nil, err
 	}
 
 	key, err := openssl.GenerateRSAKey(bits)
@@ -40,12 +40,16 @@ func (sk *RsaPrivateKey) GetPublic() PubKey {
 
 // UnmarshalRsaPrivateKey returns a private key from the input x509 bytes
 func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
+	return unmarshalRsaPrivateKey(b, minRSAKeyBits())
+}
+
+func unmarshalRsaPrivateKey(b []byte, minBits int) (PrivKey, error) {
 	key, err := unmarshalOpensslPrivateKey(b)
 	if err != nil {
 		return nil, err
 	}
-	if 8*key.key.Size() < MinRsaKeyBits {
-		return nil, ErrRsaKeyTooSmall
+	if err := checkRsaKeySize(8*key.key.Size(), minBits); err != nil {
+		return nil, err
 	}
 	if key.Type() != RSA {
 		return nil, errors.New("not actually an rsa public key")
@@ -55,12 +59,16 @@ func UnmarshalRsaPrivateKey(b []byte) (PrivKey, error) {
 
 // UnmarshalRsaPublicKey returns a public key from the input x509 bytes
 func UnmarshalRsaPublicKey(b []byte) (PubKey, error) {
+	return unmarshalRsaPublicKey(b, minRSAKeyBits())
+}
+
+func unmarshalRsaPublicKey(b []byte, minBits int) (PubKey, error) {
 	key, err := unmarshalOpensslPublicKey(b)
 	if err != nil {
 		return nil, err
 	}
-	if 8*key.key.Size() < MinRsaKeyBits {
-		return nil, ErrRsaKeyTooSmall
+	if err := checkRsaKeySize(8*key.key.Size(), minBits); err != nil {
+		return nil, err
 	}
 	if key.Type() != RSA {
 		return nil, errors.New("not actually an rsa public key")
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"context"
 	"crypto/rand"
 	"testing"
 )
@@ -122,3 +123,62 @@ func TestRSAMarshalLoop(t *testing.T) {
 		t.Fatal("keys are not equal")
 	}
 }
+
+func TestRSAMinKeyBitsContext(t *testing.T) {
+	if err := SetMinRSAKeyBits(MinRsaKeyBitsFloor - 1); err == nil {
+		t.Fatal("expected key size below the floor to be rejected")
+	}
+
+	if err := SetMinRSAKeyBits(1024); err != nil {
+		t.Fatal(err)
+	}
+	defer ResetMinRSAKeyBits()
+	priv, pub, err := GenerateRSAKeyPair(1024, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetMinRSAKeyBits(2048); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = GenerateRSAKeyPair(1024, rand.Reader)
+	if err != ErrRsaKeyTooSmall {
+		t.Fatalf("expected ErrRsaKeyTooSmall, got %v", err)
+	}
+	pubBytes, err := MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privBytes, err := MarshalPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := UnmarshalPublicKeyContext(ctx, pubBytes); err != ErrRsaKeyTooSmall {
+		t.Fatalf("expected ErrRsaKeyTooSmall, got %v", err)
+	}
+
+	ctx = WithMinRSAKeyBits(ctx, 1024)
+	if bits := GetMinRSAKeyBits(ctx); bits != 1024 {
+		t.Fatalf("expected 1024, got %d", bits)
+	}
+	pub2, err := UnmarshalPublicKeyContext(ctx, pubBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equals(pub2) {
+		t.Fatal("unmarshalled public key doesn't match")
+	}
+	priv2, err := UnmarshalPrivateKeyContext(ctx, privBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !priv.Equals(priv2) {
+		t.Fatal("unmarshalled private key doesn't match")
+	}
+
+	_, err = UnmarshalPublicKeyContext(WithMinRSAKeyBits(ctx, 2048), pubBytes)
+	if err != ErrRsaKeyTooSmall {
+		t.Fatalf("expected ErrRsaKeyTooSmall, got %v", err)
+	}
+}
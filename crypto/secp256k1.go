@@ -125,3 +125,8 @@ func (k *Secp256k1PublicKey) Verify(data []byte, sigStr []byte) (success bool, e
 	hash := sha256.Sum256(data)
 	return sig.Verify(hash[:], (*btcec.PublicKey)(k)), nil
 }
+
+// Zeroize wipes the key material from memory.
+func (k *Secp256k1PrivateKey) Zeroize() {
+	(*btcec.PrivateKey)(k).Zero()
+}
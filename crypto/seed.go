@@ -0,0 +1,98 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/minio/sha256-simd"
+	"golang.org/x/crypto/hkdf"
+)
+
+// MinSeedSize is the minimum size, in bytes, of the seeds accepted by
+// GenerateKeyPairFromSeed.
+const MinSeedSize = 32
+
+// ErrSeedTooShort is returned by GenerateKeyPairFromSeed when the seed is
+// shorter than MinSeedSize.
+var ErrSeedTooShort = fmt.Errorf("seed must be at least %d bytes long", MinSeedSize)
+
+// GenerateKeyPairFromSeed deterministically derives a key pair of the given
+// type from a seed. The same seed and key type always yield the same key.
+//
+// Unlike GenerateKeyPairWithReader, whose output for a given reader depends on
+// implementation details of each key type (and of the Go version), the
+// derivation is fully specified:
+//
+//   - key material is expanded from the seed using HKDF-SHA256, with no salt
+//     and the info string "libp2p-<key type>-key-from-seed", where <key type>
+//     is the lowercase name of the protobuf key type (e.g. "ed25519");
+//   - Ed25519 uses the first 32 bytes of the HKDF output as the RFC 8032
+//     private key seed;
+//   - Secp256k1 and ECDSA (always on P-256, regardless of ECDSACurve) read
+//     32-byte big-endian candidates from the HKDF output until one is a
+//     valid scalar (0 < k < N), which is used as the private key.
+//
+// RSA keys can't be derived deterministically and are not supported.
+func GenerateKeyPairFromSeed(typ int, seed []byte) (PrivKey, PubKey, error) {
+	if len(seed) < MinSeedSize {
+		return nil, nil, ErrSeedTooShort
+	}
+
+	switch typ {
+	case Ed25519:
+		var edSeed [ed25519.SeedSize]byte
+		if _, err := io.ReadFull(seedReader(seed, "ed25519"), edSeed[:]); err != nil {
+			return nil, nil, err
+		}
+		priv := ed25519.NewKeyFromSeed(edSeed[:])
+		return &Ed25519PrivateKey{k: priv}, &Ed25519PublicKey{k: priv.Public().(ed25519.PublicKey)}, nil
+	case Secp256k1:
+		d, err := scalarFromSeed(seedReader(seed, "secp256k1"), btcec.S256().N)
+		if err != nil {
+			return nil, nil, err
+		}
+		buf := make([]byte, btcec.PrivKeyBytesLen)
+		privk, _ := btcec.PrivKeyFromBytes(d.FillBytes(buf))
+		k := (*Secp256k1PrivateKey)(privk)
+		return k, k.GetPublic(), nil
+	case ECDSA:
+		curve := elliptic.P256()
+		d, err := scalarFromSeed(seedReader(seed, "ecdsa"), curve.Params().N)
+		if err != nil {
+			return nil, nil, err
+		}
+		priv := &ecdsa.PrivateKey{D: d}
+		priv.PublicKey.Curve = curve
+		priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+		return &ECDSAPrivateKey{priv: priv}, &ECDSAPublicKey{&priv.PublicKey}, nil
+	case RSA:
+		return nil, nil, errors.New("rsa keys cannot be generated from a seed")
+	default:
+		return nil, nil, ErrBadKeyType
+	}
+}
+
+func seedReader(seed []byte, keyType string) io.Reader {
+	return hkdf.New(sha256.New, seed, nil, []byte("libp2p-"+keyType+"-key-from-seed"))
+}
+
+// scalarFromSeed reads candidates from r until one is a valid private scalar
+// for a curve of order n.
+func scalarFromSeed(r io.Reader, n *big.Int) (*big.Int, error) {
+	buf := make([]byte, (n.BitLen()+7)/8)
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		d := new(big.Int).SetBytes(buf)
+		if d.Sign() > 0 && d.Cmp(n) < 0 {
+			return d, nil
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGenerateKeyPairFromSeed(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, MinSeedSize)
+	other := bytes.Repeat([]byte{0x43}, MinSeedSize)
+
+	for _, typ := range []int{Ed25519, Secp256k1, ECDSA} {
+		priv, pub, err := GenerateKeyPairFromSeed(typ, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !priv.GetPublic().Equals(pub) {
+			t.Fatal("public key doesn't match private key")
+		}
+
+		priv2, _, err := GenerateKeyPairFromSeed(typ, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !priv.Equals(priv2) {
+			t.Fatalf("expected key of type %d to be deterministic", typ)
+		}
+
+		priv3, _, err := GenerateKeyPairFromSeed(typ, other)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if priv.Equals(priv3) {
+			t.Fatalf("expected different seeds to yield different keys of type %d", typ)
+		}
+
+		sig, err := priv.Sign([]byte("hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := pub.Verify([]byte("hello"), sig); err != nil || !ok {
+			t.Fatalf("signature verification failed for key type %d", typ)
+		}
+	}
+
+	if _, _, err := GenerateKeyPairFromSeed(Ed25519, seed[:MinSeedSize-1]); err != ErrSeedTooShort {
+		t.Fatalf("expected ErrSeedTooShort, got %v", err)
+	}
+	if _, _, err := GenerateKeyPairFromSeed(RSA, seed); err == nil {
+		t.Fatal("expected an error for rsa keys")
+	}
+}
+
+// TestGenerateKeyPairFromSeedVector pins the derivation, which must not change.
+// The expected keys were computed independently of this package, following
+// the derivation documented on GenerateKeyPairFromSeed.
+func TestGenerateKeyPairFromSeedVector(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, MinSeedSize)
+
+	for _, tc := range []struct {
+		typ       int
+		priv, pub string
+	}{
+		{
+			typ: Ed25519,
+			pub: "3a01f97c3ec7494db9390495a8ca89a2485a5ce2cb29ae3a6e4d79f34c9a1710",
+		},
+		{
+			// private scalar, compressed public key
+			typ:  Secp256k1,
+			priv: "73196db87ce5875f3c2cb1676eca4a373e8a84e45b47ba2916e9b359ae7f31aa",
+			pub:  "03f5f3ee6bc0c933d7d066b116ba663cb979d449a472491d6891164b1ccfdb7b14",
+		},
+		{
+			// private scalar, uncompressed P-256 public key
+			typ:  ECDSA,
+			priv: "d54da68ed2c08b7be732b1946debad5e90108d4eef9270f59e830def44faf06d",
+			pub: "04667e2ef0da9a49c5f2c88b1e709d0b18add7bf031a589a862657bc55d6056c11" +
+				"fed9d0652fed1fba552b32e4737b9cd037c36db4a11b1fce4c6944fabf79bba1",
+		},
+	} {
+		priv, pub, err := GenerateKeyPairFromSeed(tc.typ, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var privRaw, pubRaw []byte
+		switch k := priv.(type) {
+		case *ECDSAPrivateKey:
+			privRaw = k.priv.D.FillBytes(make([]byte, 32))
+			pubRaw = append([]byte{4}, k.priv.X.FillBytes(make([]byte, 32))...)
+			pubRaw = append(pubRaw, k.priv.Y.FillBytes(make([]byte, 32))...)
+		default:
+			if privRaw, err = priv.Raw(); err != nil {
+				t.Fatal(err)
+			}
+			if pubRaw, err = pub.Raw(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if tc.priv != "" && hex.EncodeToString(privRaw) != tc.priv {
+			t.Errorf("key type %d: expected private key %s, got %x", tc.typ, tc.priv, privRaw)
+		}
+		if hex.EncodeToString(pubRaw) != tc.pub {
+			t.Errorf("key type %d: expected public key %s, got %x", tc.typ, tc.pub, pubRaw)
+		}
+	}
+}
+
+func TestGenerateKeyPairFromSeedIgnoresECDSACurve(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, MinSeedSize)
+	_, pub, err := GenerateKeyPairFromSeed(ECDSA, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(c elliptic.Curve) { ECDSACurve = c }(ECDSACurve)
+	ECDSACurve = elliptic.P384()
+	_, pub2, err := GenerateKeyPairFromSeed(ECDSA, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equals(pub2) {
+		t.Fatal("expected the derived ecdsa key not to depend on ECDSACurve")
+	}
+}
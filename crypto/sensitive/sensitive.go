@@ -0,0 +1,42 @@
+// Package sensitive provides helpers for handling secret material, such as raw
+// private key bytes, in memory.
+//
+// Go doesn't offer guarantees about memory that has been copied by the runtime
+// (e.g. when a slice grows or the garbage collector moves data), so wiping is
+// best-effort: it shortens the time secrets spend in memory, but can't remove
+// every copy.
+package sensitive
+
+import (
+	"crypto/subtle"
+	"math/big"
+	"runtime"
+)
+
+// Wipe overwrites b with zeros.
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	// Make sure the writes aren't optimized away.
+	runtime.KeepAlive(b)
+}
+
+// WipeBigInt overwrites the digits of n with zeros and sets n to zero.
+func WipeBigInt(n *big.Int) {
+	if n == nil {
+		return
+	}
+	words := n.Bits()
+	for i := range words {
+		words[i] = 0
+	}
+	runtime.KeepAlive(words)
+	n.SetInt64(0)
+}
+
+// Equal compares a and b in constant time, i.e. in time that depends on the
+// length of the slices but not on their contents.
+func Equal(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
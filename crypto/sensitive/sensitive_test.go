@@ -0,0 +1,39 @@
+package sensitive
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+func TestWipe(t *testing.T) {
+	b := []byte{1, 2, 3}
+	Wipe(b)
+	if !bytes.Equal(b, make([]byte, 3)) {
+		t.Fatalf("expected zeroed bytes, got %v", b)
+	}
+}
+
+func TestWipeBigInt(t *testing.T) {
+	n := new(big.Int).Lsh(big.NewInt(0xdeadbeef), 200)
+	words := n.Bits()
+	WipeBigInt(n)
+	if n.Sign() != 0 {
+		t.Fatalf("expected zero, got %s", n)
+	}
+	for _, w := range words {
+		if w != 0 {
+			t.Fatal("expected digits to be wiped")
+		}
+	}
+	WipeBigInt(nil)
+}
+
+func TestEqual(t *testing.T) {
+	if !Equal([]byte("secret"), []byte("secret")) {
+		t.Fatal("expected equal slices to compare equal")
+	}
+	if Equal([]byte("secret"), []byte("secreT")) || Equal([]byte("secret"), []byte("secre")) {
+		t.Fatal("expected different slices to compare unequal")
+	}
+}
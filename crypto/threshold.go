@@ -0,0 +1,46 @@
+package crypto
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrThresholdNotMet is returned by ThresholdSigners when fewer than the
+// threshold number of participants took part in a signature.
+var ErrThresholdNotMet = errors.New("threshold of signers not met")
+
+// Signer produces signatures verifiable with a public key. Every PrivKey is a
+// Signer; other implementations don't hold the private key in memory, e.g.
+// hardware-backed keys or ThresholdSigners.
+type Signer interface {
+	// Sign signs the given bytes.
+	Sign([]byte) ([]byte, error)
+
+	// GetPublic returns the public key the signatures are verifiable with.
+	GetPublic() PubKey
+}
+
+var _ Signer = PrivKey(nil)
+
+// ThresholdSigner is a Signer backed by a t-of-n threshold or multi-party
+// signing scheme: the private key is split into n shares held by different
+// participants, any t of which cooperate to produce a single signature
+// valid under the peer's public key.
+//
+// This allows highly-available deployments to run several instances of the
+// same peer identity, and sign routing records, without any instance holding
+// the raw private key. The scheme must produce signatures of the usual form
+// for the key type (e.g. FROST for Ed25519 keys), so that verifiers can't
+// tell them apart from single-party signatures.
+type ThresholdSigner interface {
+	Signer
+
+	// SignContext is like Sign, but gives up when ctx is done, as
+	// collecting signature shares usually involves network round trips.
+	// It returns ErrThresholdNotMet if not enough participants responded.
+	SignContext(ctx context.Context, data []byte) ([]byte, error)
+
+	// Threshold returns the number of participants required to sign (t)
+	// and the total number of participants (n).
+	Threshold() (t, n int)
+}
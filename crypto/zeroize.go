@@ -0,0 +1,22 @@
+package crypto
+
+// Zeroizer is implemented by private keys that can wipe their key material
+// from memory. After Zeroize has been called, the key must not be used.
+//
+// Wiping is best-effort, see the crypto/sensitive package. Some keys can
+// only wipe part of their key material, e.g. RSA keys don't wipe the copies
+// crypto/rsa keeps internally, see RsaPrivateKey.Zeroize.
+type Zeroizer interface {
+	Zeroize()
+}
+
+// Zeroize wipes the key material of the given private key from memory, if
+// the key implements Zeroizer. It returns false if the key doesn't support
+// zeroization.
+func Zeroize(k PrivKey) bool {
+	z, ok := k.(Zeroizer)
+	if ok {
+		z.Zeroize()
+	}
+	return ok
+}
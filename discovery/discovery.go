@@ -3,11 +3,23 @@ package discovery
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
 )
 
+// ErrSignedPeerRecordsNotSupported is returned by discovery implementations that
+// don't support signed peer records when the RequireSignedPeerRecords option is
+// given.
+var ErrSignedPeerRecordsNotSupported = errors.New("discovery: signed peer records not supported")
+
+// ErrFilterNotSupported is returned by discovery implementations that can't
+// apply one of the filtering options given to FindPeers, e.g. because
+// advertisements don't carry the information needed to apply it.
+var ErrFilterNotSupported = errors.New("discovery: filter not supported")
+
 // Advertiser is an interface for advertising services
 type Advertiser interface {
 	// Advertise advertises a service
@@ -17,6 +29,11 @@ type Advertiser interface {
 // Discoverer is an interface for peer discovery
 type Discoverer interface {
 	// FindPeers discovers peers providing a service
+	//
+	// Implementations should apply the filtering options (WithProtocols,
+	// WithAddrProtocols, WithReachability) as close to the source as
+	// possible, e.g. in the rendezvous server or DHT query, rather than
+	// fetching all peers and discarding them locally.
 	FindPeers(ctx context.Context, ns string, opts ...Option) (<-chan peer.AddrInfo, error)
 }
 
@@ -25,3 +42,14 @@ type Discovery interface {
 	Advertiser
 	Discoverer
 }
+
+// SignedDiscoverer is implemented by discoverers that can return the signed
+// peer records of the discovered peers, e.g. to add them to a
+// peerstore.CertifiedAddrBook.
+type SignedDiscoverer interface {
+	// FindSignedPeers discovers peers providing a service, and returns the
+	// signed peer.PeerRecord of each, wrapped in a record.Envelope. The
+	// envelopes have been verified, and peers without a signed record are
+	// skipped.
+	FindSignedPeers(ctx context.Context, ns string, opts ...Option) (<-chan *record.Envelope, error)
+}
@@ -1,6 +1,14 @@
 package discovery
 
-import "time"
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p-core/record"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
 
 // DiscoveryOpt is a single discovery option.
 type Option func(opts *Options) error
@@ -10,6 +18,28 @@ type Options struct {
 	Ttl   time.Duration
 	Limit int
 
+	// SignedPeerRecord is the signed peer record to attach to an
+	// advertisement. See WithSignedPeerRecord.
+	SignedPeerRecord *record.Envelope
+
+	// RequireSignedPeerRecords restricts discovery results to peers whose
+	// addresses come from a valid signed peer record. See
+	// RequireSignedPeerRecords.
+	RequireSignedPeerRecords bool
+
+	// Protocols restricts discovery results to peers supporting all of the
+	// given protocols. See WithProtocols.
+	Protocols []protocol.ID
+
+	// AddrProtocols restricts discovery results to the addresses using one
+	// of the given multiaddr protocols. See WithAddrProtocols.
+	AddrProtocols []int
+
+	// Reachability restricts discovery results to peers with the given
+	// reachability. ReachabilityUnknown doesn't restrict the results. See
+	// WithReachability.
+	Reachability network.Reachability
+
 	// Other (implementation-specific) options
 	Other map[interface{}]interface{}
 }
@@ -39,3 +69,100 @@ func Limit(limit int) Option {
 		return nil
 	}
 }
+
+// WithSignedPeerRecord is an option that attaches the advertiser's signed
+// peer.PeerRecord to an advertisement, so that discoverers can verify the
+// advertised addresses.
+//
+// The envelope must contain a peer.PeerRecord signed by the advertising peer.
+func WithSignedPeerRecord(env *record.Envelope) Option {
+	return func(opts *Options) error {
+		opts.SignedPeerRecord = env
+		return nil
+	}
+}
+
+// RequireSignedPeerRecords is an option that instructs FindPeers to only return
+// peers whose addresses were obtained from a signed peer.PeerRecord, after
+// verifying its signature. Peers that advertised without a signed record are
+// skipped.
+//
+// Implementations that can't verify signed records must return
+// ErrSignedPeerRecordsNotSupported.
+func RequireSignedPeerRecords() Option {
+	return func(opts *Options) error {
+		opts.RequireSignedPeerRecords = true
+		return nil
+	}
+}
+
+// WithProtocols is an option that instructs FindPeers to only return peers
+// supporting all of the given protocols, as advertised by the peers.
+//
+// Implementations that can't filter by protocol must return
+// ErrFilterNotSupported.
+func WithProtocols(protos ...protocol.ID) Option {
+	return func(opts *Options) error {
+		opts.Protocols = append(opts.Protocols, protos...)
+		return nil
+	}
+}
+
+// WithAddrProtocols is an option that instructs FindPeers to only return the
+// addresses using at least one of the given multiaddr protocols, e.g.
+// ma.P_IP6 or ma.P_QUIC. Peers left without addresses are skipped.
+func WithAddrProtocols(codes ...int) Option {
+	return func(opts *Options) error {
+		opts.AddrProtocols = append(opts.AddrProtocols, codes...)
+		return nil
+	}
+}
+
+// WithReachability is an option that instructs FindPeers to only return peers
+// with the given reachability, as advertised by the peers, e.g. to find
+// publicly reachable peers to use as relays.
+//
+// Implementations that can't filter by reachability must return
+// ErrFilterNotSupported.
+func WithReachability(r network.Reachability) Option {
+	return func(opts *Options) error {
+		opts.Reachability = r
+		return nil
+	}
+}
+
+// MatchProtocols returns true if the given protocols, supported by a peer,
+// include all of the protocols required by the WithProtocols option.
+func (opts *Options) MatchProtocols(protos []protocol.ID) bool {
+	for _, want := range opts.Protocols {
+		found := false
+		for _, p := range protos {
+			if p == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterAddrs returns the addresses allowed by the WithAddrProtocols option.
+// All addresses are allowed if the option wasn't given.
+func (opts *Options) FilterAddrs(addrs []ma.Multiaddr) []ma.Multiaddr {
+	if len(opts.AddrProtocols) == 0 {
+		return addrs
+	}
+	filtered := make([]ma.Multiaddr, 0, len(addrs))
+	for _, a := range addrs {
+		for _, code := range opts.AddrProtocols {
+			if _, err := a.ValueForProtocol(code); err == nil {
+				filtered = append(filtered, a)
+				break
+			}
+		}
+	}
+	return filtered
+}
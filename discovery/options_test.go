@@ -0,0 +1,71 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestMatchProtocols(t *testing.T) {
+	supported := []protocol.ID{"/a/1.0.0", "/b/1.0.0", "/c/1.0.0"}
+	for _, tc := range []struct {
+		name   string
+		want   []protocol.ID
+		protos []protocol.ID
+		exp    bool
+	}{
+		{"no filter", nil, supported, true},
+		{"no filter, no protocols", nil, nil, true},
+		{"all supported", []protocol.ID{"/c/1.0.0", "/a/1.0.0"}, supported, true},
+		{"one missing", []protocol.ID{"/a/1.0.0", "/d/1.0.0"}, supported, false},
+		{"no protocols", []protocol.ID{"/a/1.0.0"}, nil, false},
+	} {
+		var opts Options
+		if err := opts.Apply(WithProtocols(tc.want...)); err != nil {
+			t.Fatal(err)
+		}
+		if got := opts.MatchProtocols(tc.protos); got != tc.exp {
+			t.Errorf("%s: expected %t, got %t", tc.name, tc.exp, got)
+		}
+	}
+}
+
+func TestFilterAddrs(t *testing.T) {
+	tcp4 := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	tcp6 := ma.StringCast("/ip6/::1/tcp/1")
+	quic := ma.StringCast("/ip4/1.2.3.4/udp/1/quic")
+	addrs := []ma.Multiaddr{tcp4, tcp6, quic}
+
+	for _, tc := range []struct {
+		name  string
+		codes []int
+		addrs []ma.Multiaddr
+		exp   []ma.Multiaddr
+	}{
+		{"no filter", nil, addrs, addrs},
+		{"no filter, no addrs", nil, nil, nil},
+		{"one protocol", []int{ma.P_IP6}, addrs, []ma.Multiaddr{tcp6}},
+		{"valueless protocol", []int{ma.P_QUIC}, addrs, []ma.Multiaddr{quic}},
+		{"any protocol", []int{ma.P_QUIC, ma.P_IP6}, addrs, []ma.Multiaddr{tcp6, quic}},
+		{"no match", []int{ma.P_DNS4}, addrs, nil},
+		{"no addrs", []int{ma.P_TCP}, nil, nil},
+	} {
+		var opts Options
+		if err := opts.Apply(WithAddrProtocols(tc.codes...)); err != nil {
+			t.Fatal(err)
+		}
+		got := opts.FilterAddrs(tc.addrs)
+		if len(got) != len(tc.exp) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.exp, got)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tc.exp[i]) {
+				t.Errorf("%s: expected %v, got %v", tc.name, tc.exp, got)
+				break
+			}
+		}
+	}
+}
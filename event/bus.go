@@ -5,7 +5,8 @@ import (
 	"reflect"
 )
 
-// SubscriptionOpt represents a subscriber option. Use the options exposed by the implementation of choice.
+// SubscriptionOpt represents a subscriber option. Use the options exposed by the implementation of choice,
+// or the options of this package, such as WithBuffer and WithLastEvent, supported by some implementations.
 type SubscriptionOpt = func(interface{}) error
 
 // EmitterOpt represents an emitter option. Use the options exposed by the implementation of choice.
@@ -95,3 +96,18 @@ type Bus interface {
 	// no pointer types will be returned.
 	GetAllEventTypes() []reflect.Type
 }
+
+// TypeOf returns the event type of ev, as used by the eventbus for wiring and
+// returned by GetAllEventTypes. It accepts both event values, as received from
+// a Subscription, and (typed nil) pointers to events, as passed to Subscribe
+// and Emitter.
+//
+// It is mainly useful for subscribers to WildcardSubscription, e.g. to log or
+// count events by type.
+func TypeOf(ev interface{}) reflect.Type {
+	typ := reflect.TypeOf(ev)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
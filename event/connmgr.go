@@ -0,0 +1,25 @@
+package event
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// EvtConnDraining should be emitted by the connection manager when it decides
+// to close a connection during a trim, before closing it. Protocols using
+// long-lived streams on the connection should finish or migrate their
+// in-flight requests and refrain from opening new streams on it, so that the
+// close doesn't interrupt them.
+//
+// The connection is closed at Deadline at the latest, or earlier if all of
+// its streams are closed before then.
+type EvtConnDraining struct {
+	// Peer is the remote peer of the connection.
+	Peer peer.ID
+	// Conn is the connection being drained.
+	Conn network.Conn
+	// Deadline is the time at which the connection will be closed.
+	Deadline time.Time
+}
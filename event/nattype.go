@@ -1,6 +1,13 @@
 package event
 
-import "github.com/libp2p/go-libp2p-core/network"
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
 
 // EvtNATDeviceTypeChanged is an event struct to be emitted when the type of the NAT device changes for a Transport Protocol.
 //
@@ -16,3 +23,69 @@ type EvtNATDeviceTypeChanged struct {
 	// how they impact Connectivity and Hole Punching.
 	NatDeviceType network.NATDeviceType
 }
+
+// PortMappingStatus is the outcome of a port mapping request, see
+// EvtPortMappingChanged.
+type PortMappingStatus int
+
+const (
+	// PortMappingEstablished means that the port mapping was created or
+	// renewed.
+	PortMappingEstablished PortMappingStatus = iota
+	// PortMappingFailed means that the NAT device refused or didn't answer
+	// the port mapping request.
+	PortMappingFailed
+	// PortMappingRemoved means that a previously established port mapping
+	// was removed, or expired without being renewed.
+	PortMappingRemoved
+)
+
+func (s PortMappingStatus) String() string {
+	switch s {
+	case PortMappingEstablished:
+		return "Established"
+	case PortMappingFailed:
+		return "Failed"
+	case PortMappingRemoved:
+		return "Removed"
+	default:
+		return "unrecognized"
+	}
+}
+
+// EvtPortMappingChanged is an event struct to be emitted when the status of a
+// port mapping on the NAT device, requested through e.g. UPnP, NAT-PMP or
+// PCP, changes.
+type EvtPortMappingChanged struct {
+	// TransportProtocol is the Transport Protocol of the mapped port.
+	TransportProtocol network.NATTransportProtocol
+	// Method is the protocol used to request the mapping, e.g. "UPnP" or
+	// "NAT-PMP".
+	Method string
+	// InternalAddr is the local listen address the mapping is for.
+	InternalAddr ma.Multiaddr
+	// ExternalAddr is the address the NAT device maps to InternalAddr. It is
+	// only set if Status is PortMappingEstablished.
+	ExternalAddr ma.Multiaddr
+	// Status is the outcome of the mapping request.
+	Status PortMappingStatus
+	// Lifetime is the duration the mapping was granted for, if
+	// established. It is zero if the mapping doesn't expire.
+	Lifetime time.Duration
+}
+
+// EvtHolePunchCompleted is an event struct to be emitted when an attempt to
+// establish a direct connection to a peer through hole punching completes,
+// successfully or not.
+type EvtHolePunchCompleted struct {
+	// Peer is the remote peer.
+	Peer peer.ID
+	// Success is true if a direct connection was established.
+	Success bool
+	// DirectAddr is the remote address of the direct connection, if any.
+	DirectAddr ma.Multiaddr
+	// Attempts is the number of hole punching rounds attempted.
+	Attempts int
+	// Duration is the time spent, from the first round to the outcome.
+	Duration time.Duration
+}
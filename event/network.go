@@ -1,6 +1,8 @@
 package event
 
 import (
+	"time"
+
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 )
@@ -53,3 +55,57 @@ type EvtPeerConnectednessChanged struct {
 	// Connectedness is the new connectedness state.
 	Connectedness network.Connectedness
 }
+
+// ConnectednessCause describes why the connectedness to a peer changed.
+type ConnectednessCause int
+
+const (
+	// ConnectednessCauseUnknown is used when the cause of a transition is not
+	// known.
+	ConnectednessCauseUnknown ConnectednessCause = iota
+	// ConnectednessCauseDial is used when we started dialing the peer, or
+	// when a dial succeeded.
+	ConnectednessCauseDial
+	// ConnectednessCauseDialFailed is used when dialing the peer failed.
+	ConnectednessCauseDialFailed
+	// ConnectednessCauseInbound is used when the peer connected to us.
+	ConnectednessCauseInbound
+	// ConnectednessCauseRemoteClose is used when the remote peer closed the
+	// connection, or the connection failed.
+	ConnectednessCauseRemoteClose
+	// ConnectednessCauseLocalClose is used when we closed the connection,
+	// e.g. because the connection manager trimmed it.
+	ConnectednessCauseLocalClose
+	// ConnectednessCauseUpgrade is used when a limited (e.g. relayed)
+	// connection was supplemented by a direct one.
+	ConnectednessCauseUpgrade
+)
+
+func (c ConnectednessCause) String() string {
+	str := [...]string{"Unknown", "Dial", "DialFailed", "Inbound", "RemoteClose", "LocalClose", "Upgrade"}
+	if c < 0 || int(c) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[c]
+}
+
+// EvtPeerConnectednessTransition is emitted every time the connectedness to a
+// given peer moves from one network.Connectedness state to another, including
+// the Connecting, Limited and Draining states that aren't reported by
+// EvtPeerConnectednessChanged.
+//
+// The same caveats as for EvtPeerConnectednessChanged apply: transitions are
+// observed asynchronously, and both sides of a connection may observe
+// different transitions.
+type EvtPeerConnectednessTransition struct {
+	// Peer is the remote peer who's connectedness has changed.
+	Peer peer.ID
+	// From is the previous connectedness state.
+	From network.Connectedness
+	// To is the new connectedness state.
+	To network.Connectedness
+	// Time is the time at which the transition happened.
+	Time time.Time
+	// Cause is the reason for the transition.
+	Cause ConnectednessCause
+}
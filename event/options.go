@@ -0,0 +1,66 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOptionNotSupported is returned by subscription options that the Bus
+// implementation doesn't support.
+var ErrOptionNotSupported = errors.New("event bus option not supported")
+
+// BufferedSubscriptionSettings is implemented by the subscription settings of
+// Bus implementations whose subscription channels can be buffered, to
+// support the WithBuffer option.
+type BufferedSubscriptionSettings interface {
+	// SetBufferSize sets the capacity of the subscription channel.
+	SetBufferSize(n int)
+}
+
+// ReplaySubscriptionSettings is implemented by the subscription settings of
+// Bus implementations that retain the last event emitted of every type, to
+// support the WithLastEvent option.
+type ReplaySubscriptionSettings interface {
+	// SetReplayLastEvent sets whether the last event emitted of every
+	// subscribed type is delivered on subscription.
+	SetReplayLastEvent(bool)
+}
+
+// WithBuffer is a subscription option setting the capacity of the
+// subscription channel, so that bursts of events don't block emitters while
+// the subscriber catches up.
+//
+// Subscribe fails with ErrOptionNotSupported if the Bus implementation
+// doesn't support it.
+func WithBuffer(n int) SubscriptionOpt {
+	return func(settings interface{}) error {
+		if n < 0 {
+			return fmt.Errorf("negative subscription buffer size %d", n)
+		}
+		s, ok := settings.(BufferedSubscriptionSettings)
+		if !ok {
+			return fmt.Errorf("WithBuffer: %w", ErrOptionNotSupported)
+		}
+		s.SetBufferSize(n)
+		return nil
+	}
+}
+
+// WithLastEvent is a subscription option making the subscription immediately
+// deliver the last event emitted of every subscribed type, if any, before any
+// new event. This avoids startup races for subscribers to events describing a
+// state, such as EvtLocalReachabilityChanged or EvtLocalAddressesUpdated,
+// which would otherwise not learn the current state until it changes again.
+//
+// Subscribe fails with ErrOptionNotSupported if the Bus implementation
+// doesn't support it.
+func WithLastEvent() SubscriptionOpt {
+	return func(settings interface{}) error {
+		s, ok := settings.(ReplaySubscriptionSettings)
+		if !ok {
+			return fmt.Errorf("WithLastEvent: %w", ErrOptionNotSupported)
+		}
+		s.SetReplayLastEvent(true)
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package event
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// EvtPeerAddressesAdded should be emitted by the peerstore when addresses are
+// added for a peer, with AddAddrs or SetAddrs, so that components such as
+// routers and user interfaces don't need to poll or wrap the peerstore.
+type EvtPeerAddressesAdded struct {
+	// Peer is the peer whose addresses were added.
+	Peer peer.ID
+	// Addrs enumerates the addresses that weren't known before.
+	Addrs []ma.Multiaddr
+	// TTL is the time-to-live the addresses were added with.
+	TTL time.Duration
+}
+
+// EvtPeerKeyAdded should be emitted by the peerstore when a key is added for
+// a peer, with AddPubKey or AddPrivKey, unless the key was already known.
+type EvtPeerKeyAdded struct {
+	// Peer is the peer whose key was added.
+	Peer peer.ID
+	// PubKey is the public key of the peer. When a private key is added,
+	// this is its public counterpart; private keys are never emitted.
+	PubKey crypto.PubKey
+	// Private is true if a private key was added.
+	Private bool
+}
+
+// EvtPeerRemoved should be emitted by the peerstore when all data stored for
+// a peer is removed, with RemovePeer.
+type EvtPeerRemoved struct {
+	// Peer is the peer that was removed.
+	Peer peer.ID
+}
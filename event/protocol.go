@@ -1,11 +1,16 @@
 package event
 
 import (
+	network "github.com/libp2p/go-libp2p-core/network"
 	peer "github.com/libp2p/go-libp2p-core/peer"
 	protocol "github.com/libp2p/go-libp2p-core/protocol"
 )
 
 // EvtPeerProtocolsUpdated should be emitted when a peer we're connected to adds or removes protocols from their stack.
+//
+// It is typically emitted by the identify service, after the peer's ProtoBook
+// entry has been updated, so subscribers can react to protocol changes without
+// polling the peerstore.
 type EvtPeerProtocolsUpdated struct {
 	// Peer is the peer whose protocols were updated.
 	Peer peer.ID
@@ -24,3 +29,30 @@ type EvtLocalProtocolsUpdated struct {
 	// Removed enumerates the protocols that were removed locally.
 	Removed []protocol.ID
 }
+
+// EvtStreamProtocolNegotiated should be emitted every time a protocol is successfully negotiated on a stream,
+// for both inbound and outbound streams.
+type EvtStreamProtocolNegotiated struct {
+	// Peer is the remote peer of the stream.
+	Peer peer.ID
+	// Stream is the stream on which the protocol was negotiated.
+	Stream network.Stream
+	// Protocol is the negotiated protocol.
+	Protocol protocol.ID
+	// Direction indicates whether the stream was opened by the remote peer (DirInbound) or by us (DirOutbound).
+	Direction network.Direction
+}
+
+// EvtStreamProtocolNegotiationFailed should be emitted every time protocol negotiation fails on a stream,
+// for both inbound and outbound streams.
+type EvtStreamProtocolNegotiationFailed struct {
+	// Peer is the remote peer of the stream.
+	Peer peer.ID
+	// Proposed enumerates the protocols that were proposed on the stream: the ones we offered for outbound
+	// streams, or the ones the remote peer offered for inbound streams (if known).
+	Proposed []protocol.ID
+	// Direction indicates whether the stream was opened by the remote peer (DirInbound) or by us (DirOutbound).
+	Direction network.Direction
+	// Reason is the reason why negotiation failed.
+	Reason error
+}
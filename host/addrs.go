@@ -0,0 +1,153 @@
+package host
+
+import (
+	"fmt"
+	"net"
+
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// AddrsFactory computes the addresses a Host advertises from its listen
+// addresses, e.g. to add the address of a port mapping or to hide addresses of
+// internal networks. The returned addresses are the ones returned by
+// Host.Addrs and included in the signed peer records of the Host.
+type AddrsFactory func(addrs []ma.Multiaddr) []ma.Multiaddr
+
+// DefaultAddrsFactory advertises the listen addresses as is.
+func DefaultAddrsFactory(addrs []ma.Multiaddr) []ma.Multiaddr {
+	return addrs
+}
+
+// ChainAddrsFactories returns an AddrsFactory applying the given factories in
+// order, each to the output of the previous one.
+func ChainAddrsFactories(factories ...AddrsFactory) AddrsFactory {
+	return func(addrs []ma.Multiaddr) []ma.Multiaddr {
+		for _, f := range factories {
+			addrs = f(addrs)
+		}
+		return addrs
+	}
+}
+
+// AnnounceAddrs returns an AddrsFactory advertising the given addresses instead
+// of the listen addresses.
+func AnnounceAddrs(announce ...ma.Multiaddr) AddrsFactory {
+	return func([]ma.Multiaddr) []ma.Multiaddr {
+		return append([]ma.Multiaddr(nil), announce...)
+	}
+}
+
+// AppendAddrs returns an AddrsFactory advertising the given addresses in
+// addition to the listen addresses.
+func AppendAddrs(extra ...ma.Multiaddr) AddrsFactory {
+	return func(addrs []ma.Multiaddr) []ma.Multiaddr {
+		out := make([]ma.Multiaddr, 0, len(addrs)+len(extra))
+		out = append(out, addrs...)
+		for _, e := range extra {
+			if !containsAddr(out, e) {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+}
+
+// NoAnnounceAddrs returns an AddrsFactory not advertising the given addresses.
+func NoAnnounceAddrs(hidden ...ma.Multiaddr) AddrsFactory {
+	return func(addrs []ma.Multiaddr) []ma.Multiaddr {
+		return filterAddrs(addrs, func(a ma.Multiaddr) bool {
+			return !containsAddr(hidden, a)
+		})
+	}
+}
+
+// CIDRFilter matches the addresses whose IP is in one of its networks.
+// Addresses without an IP component, e.g. /dns4 addresses, never match.
+type CIDRFilter []*net.IPNet
+
+// ParseCIDRFilter parses CIDR notations, e.g. "10.0.0.0/8" or "fc00::/7", into
+// a CIDRFilter.
+func ParseCIDRFilter(cidrs ...string) (CIDRFilter, error) {
+	f := make(CIDRFilter, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", c, err)
+		}
+		f = append(f, ipnet)
+	}
+	return f, nil
+}
+
+// Matches returns true if the IP of addr is in one of the filter's networks.
+func (f CIDRFilter) Matches(addr ma.Multiaddr) bool {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return false
+	}
+	for _, ipnet := range f {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Announce returns an AddrsFactory only advertising the addresses matching
+// the filter.
+func (f CIDRFilter) Announce() AddrsFactory {
+	return func(addrs []ma.Multiaddr) []ma.Multiaddr {
+		return filterAddrs(addrs, f.Matches)
+	}
+}
+
+// NoAnnounce returns an AddrsFactory not advertising the addresses matching
+// the filter.
+func (f CIDRFilter) NoAnnounce() AddrsFactory {
+	return func(addrs []ma.Multiaddr) []ma.Multiaddr {
+		return filterAddrs(addrs, func(a ma.Multiaddr) bool {
+			return !f.Matches(a)
+		})
+	}
+}
+
+// AddrsFactoryHost is implemented by Host implementations whose advertised
+// addresses can be shaped with an AddrsFactory after construction.
+//
+// To test whether a given Host supports it, use the GetAddrsFactoryHost
+// helper.
+type AddrsFactoryHost interface {
+	// SetAddrsFactory sets the AddrsFactory computing the addresses
+	// returned by Addrs and included in the signed peer records of the
+	// host. The host publishes an updated peer record if its addresses
+	// change as a result.
+	SetAddrsFactory(AddrsFactory)
+}
+
+// GetAddrsFactoryHost is a helper to "upcast" a Host to an AddrsFactoryHost
+// by using type assertion. Returns (nil, false) if the Host doesn't support
+// setting its AddrsFactory.
+func GetAddrsFactoryHost(h Host) (ah AddrsFactoryHost, ok bool) {
+	ah, ok = h.(AddrsFactoryHost)
+	return ah, ok
+}
+
+func filterAddrs(addrs []ma.Multiaddr, keep func(ma.Multiaddr) bool) []ma.Multiaddr {
+	out := make([]ma.Multiaddr, 0, len(addrs))
+	for _, a := range addrs {
+		if keep(a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func containsAddr(addrs []ma.Multiaddr, addr ma.Multiaddr) bool {
+	for _, a := range addrs {
+		if a.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package host
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var (
+	public   = ma.StringCast("/ip4/1.2.3.4/tcp/4001")
+	private  = ma.StringCast("/ip4/10.0.0.1/tcp/4001")
+	ula      = ma.StringCast("/ip6/fd00::1/udp/4001/quic")
+	loopback = ma.StringCast("/ip6/::1/tcp/4001")
+	dns      = ma.StringCast("/dns4/example.com/tcp/4001")
+	mapped   = ma.StringCast("/ip4/5.6.7.8/tcp/1234")
+)
+
+func requireAddrs(t *testing.T, got []ma.Multiaddr, exp ...ma.Multiaddr) {
+	t.Helper()
+	if len(got) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	for i := range got {
+		if !got[i].Equal(exp[i]) {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+}
+
+func TestAddrsFactories(t *testing.T) {
+	listen := []ma.Multiaddr{public, private, loopback}
+
+	requireAddrs(t, DefaultAddrsFactory(listen), listen...)
+
+	requireAddrs(t, AnnounceAddrs(mapped, dns)(listen), mapped, dns)
+	requireAddrs(t, AnnounceAddrs()(listen))
+
+	requireAddrs(t, AppendAddrs(mapped)(listen), public, private, loopback, mapped)
+	// addresses already advertised aren't duplicated
+	requireAddrs(t, AppendAddrs(public, mapped, mapped)(listen), public, private, loopback, mapped)
+	requireAddrs(t, AppendAddrs(mapped)(nil), mapped)
+
+	requireAddrs(t, NoAnnounceAddrs(private, mapped)(listen), public, loopback)
+	requireAddrs(t, NoAnnounceAddrs()(listen), listen...)
+
+	// the listen addresses are left untouched
+	requireAddrs(t, listen, public, private, loopback)
+}
+
+func TestAnnounceAddrsCopies(t *testing.T) {
+	f := AnnounceAddrs(mapped, dns)
+	addrs := f(nil)
+	addrs[0] = public
+	requireAddrs(t, f(nil), mapped, dns)
+}
+
+func TestChainAddrsFactories(t *testing.T) {
+	listen := []ma.Multiaddr{public, private, loopback}
+
+	requireAddrs(t, ChainAddrsFactories()(listen), listen...)
+
+	// factories apply in order
+	f := ChainAddrsFactories(NoAnnounceAddrs(private), AppendAddrs(mapped, private))
+	requireAddrs(t, f(listen), public, loopback, mapped, private)
+	f = ChainAddrsFactories(AppendAddrs(mapped, private), NoAnnounceAddrs(private))
+	requireAddrs(t, f(listen), public, loopback, mapped)
+
+	f = ChainAddrsFactories(AnnounceAddrs(mapped), AppendAddrs(dns))
+	requireAddrs(t, f(listen), mapped, dns)
+	f = ChainAddrsFactories(AppendAddrs(dns), AnnounceAddrs(mapped))
+	requireAddrs(t, f(listen), mapped)
+}
+
+func TestParseCIDRFilter(t *testing.T) {
+	f, err := ParseCIDRFilter("10.0.0.0/8", "fc00::/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f) != 2 || f[0].String() != "10.0.0.0/8" || f[1].String() != "fc00::/7" {
+		t.Fatalf("unexpected filter %v", f)
+	}
+
+	f, err = ParseCIDRFilter()
+	if err != nil || len(f) != 0 {
+		t.Fatalf("expected an empty filter, got %v, %v", f, err)
+	}
+
+	for _, cidr := range []string{"", "10.0.0.0", "10.0.0.0/33", "fc00::/129", "example.com/8"} {
+		if _, err := ParseCIDRFilter("10.0.0.0/8", cidr); err == nil {
+			t.Fatalf("expected an error parsing %q", cidr)
+		}
+	}
+}
+
+func TestCIDRFilter(t *testing.T) {
+	f, err := ParseCIDRFilter("10.0.0.0/8", "fc00::/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		addr ma.Multiaddr
+		exp  bool
+	}{
+		{public, false},
+		{private, true},
+		{ma.StringCast("/ip4/10.255.255.255"), true},
+		{ma.StringCast("/ip4/11.0.0.0"), false},
+		{ula, true},
+		{loopback, false},
+		// addresses without an IP never match
+		{dns, false},
+	} {
+		if got := f.Matches(tc.addr); got != tc.exp {
+			t.Errorf("expected Matches(%s) to be %t", tc.addr, tc.exp)
+		}
+	}
+	if (CIDRFilter{}).Matches(private) {
+		t.Error("expected an empty filter not to match")
+	}
+
+	listen := []ma.Multiaddr{public, private, ula, loopback, dns}
+	requireAddrs(t, f.Announce()(listen), private, ula)
+	requireAddrs(t, f.NoAnnounce()(listen), public, loopback, dns)
+	requireAddrs(t, CIDRFilter{}.Announce()(listen))
+	requireAddrs(t, CIDRFilter{}.NoAnnounce()(listen), listen...)
+
+	// filters compose with the other factories
+	loopbackFilter, err := ParseCIDRFilter("::1/128")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := ChainAddrsFactories(f.NoAnnounce(), loopbackFilter.NoAnnounce(), AppendAddrs(mapped))
+	requireAddrs(t, chain(listen), public, dns, mapped)
+}
@@ -0,0 +1,81 @@
+package host
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// DialBackVerdict is the outcome of a dial-back probe.
+type DialBackVerdict int
+
+const (
+	// DialBackUnknown means the probe was inconclusive, e.g. because the
+	// server couldn't dial the address's transport.
+	DialBackUnknown DialBackVerdict = iota
+	// DialBackReachable means the server successfully dialed the address.
+	DialBackReachable
+	// DialBackUnreachable means the server failed to dial the address.
+	DialBackUnreachable
+	// DialBackRefused means the server refused to dial the address, e.g.
+	// because of rate limiting or because the address isn't public.
+	DialBackRefused
+)
+
+func (v DialBackVerdict) String() string {
+	str := [...]string{"Unknown", "Reachable", "Unreachable", "Refused"}
+	if v < 0 || int(v) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[v]
+}
+
+// DialBackResult is the result of a dial-back probe of an address.
+type DialBackResult struct {
+	// Addr is the probed address.
+	Addr ma.Multiaddr
+
+	// Server is the peer that performed the dial-back.
+	Server peer.ID
+
+	// Verdict is the outcome of the probe.
+	Verdict DialBackVerdict
+
+	// RTT is the round-trip time measured on the dial-back connection, if
+	// the address is reachable.
+	RTT time.Duration
+}
+
+// DialBackVerifier is implemented by Host implementations that can ask other
+// peers to dial them back on a specific address, as done by AutoNAT v2 style
+// protocols.
+//
+// Unlike the reachability reported by autonat.AutoNAT, which applies to the
+// host as a whole, dial-back probes verify individual observed addresses, so
+// that confidence in an address can be established before advertising it,
+// e.g. in signed peer records.
+//
+// To test whether a given Host supports dial-back probes, use the
+// GetDialBackVerifier helper.
+type DialBackVerifier interface {
+	// VerifyAddr asks a server to dial the host back on addr, which must be
+	// one of the host's listen or observed addresses. If server is empty,
+	// the implementation picks one of the connected peers supporting the
+	// protocol.
+	//
+	// An error is returned if no server could be asked, or if ctx is done
+	// before the probe completes. A probe that completes without confirming
+	// that addr is reachable isn't an error, and reports its Verdict.
+	VerifyAddr(ctx context.Context, server peer.ID, addr ma.Multiaddr) (DialBackResult, error)
+}
+
+// GetDialBackVerifier is a helper to "upcast" a Host to a DialBackVerifier by
+// using type assertion. Returns (nil, false) if the Host doesn't support
+// dial-back probes.
+func GetDialBackVerifier(h Host) (dv DialBackVerifier, ok bool) {
+	dv, ok = h.(DialBackVerifier)
+	return dv, ok
+}
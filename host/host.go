@@ -12,6 +12,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/ping"
 	"github.com/libp2p/go-libp2p-core/protocol"
 
 	ma "github.com/multiformats/go-multiaddr"
@@ -62,6 +63,12 @@ type Host interface {
 	// NewStream opens a new stream to given peer p, and writes a p2p/protocol
 	// header with given ProtocolID. If there is no connection to p, attempts
 	// to create one. If ProtocolID is "", writes no header.
+	//
+	// The protocols are tried in order of preference. The behavior can be
+	// tuned with context options: network.WithNegotiationTimeout bounds
+	// negotiation, network.WithNoProtocolFallback restricts negotiation to the
+	// first protocol, and network.WithForceDirectDial forces a direct
+	// connection.
 	// (Threadsafe)
 	NewStream(ctx context.Context, p peer.ID, pids ...protocol.ID) (network.Stream, error)
 
@@ -87,3 +94,153 @@ type IntrospectableHost interface {
 	// hasn't been registered.
 	IntrospectionEndpoint() introspection.Endpoint
 }
+
+// PausableHost is implemented by Host implementations that can temporarily
+// stop serving, e.g. during maintenance windows or to apply backpressure.
+type PausableHost interface {
+	// Pause stops the host from accepting inbound streams and from dialing
+	// out. While paused, inbound streams are reset and calls to NewStream or
+	// Connect fail with network.ErrPaused. Existing connections are kept open.
+	//
+	// If notifyPeers is true, the host makes a best-effort attempt to inform
+	// connected peers that it is pausing, so they can back off instead of
+	// retrying. Pause blocks until notifications are sent or ctx is done.
+	//
+	// Calling Pause on an already paused host is a no-op.
+	Pause(ctx context.Context, notifyPeers bool) error
+
+	// Resume restores normal service after a call to Pause. Calling Resume
+	// on a host that isn't paused is a no-op.
+	Resume() error
+
+	// IsPaused returns true if the host is currently paused.
+	IsPaused() bool
+}
+
+// ShutdownHook is called by GracefulHost.Shutdown for every connected peer,
+// e.g. to send a goodbye message, before the host stops. Hooks must return
+// when ctx is done.
+type ShutdownHook func(ctx context.Context, p peer.ID)
+
+// GracefulHost is implemented by Host implementations that can shut down
+// without dropping in-flight requests.
+type GracefulHost interface {
+	// Shutdown stops the host gracefully:
+	//
+	//  1. it stops accepting new inbound streams and connections, and fails
+	//     calls to NewStream and Connect with network.ErrPaused;
+	//  2. it calls the registered shutdown hooks for every connected peer;
+	//  3. it waits for open streams to be closed;
+	//  4. it calls Close.
+	//
+	// If ctx is done before the open streams are closed, they are reset and
+	// the host is closed immediately, and the context error is returned.
+	Shutdown(ctx context.Context) error
+
+	// AddShutdownHook registers a hook to be called during Shutdown.
+	AddShutdownHook(ShutdownHook)
+}
+
+// HandlerInfo describes a stream handler registered on a Host.
+type HandlerInfo struct {
+	// Protocol is the protocol ID the handler was registered under.
+	Protocol protocol.ID
+
+	// Match is true if the handler was registered with
+	// SetStreamHandlerMatch, in which case it may handle protocols other
+	// than Protocol.
+	Match bool
+}
+
+// HandlerRegistry is implemented by Host implementations that can enumerate
+// their stream handlers, so that plugin systems can inspect and hot-swap
+// protocol handlers.
+//
+// To test whether a given Host supports handler introspection, use the
+// GetHandlerRegistry helper.
+type HandlerRegistry interface {
+	// Handlers returns the registered stream handlers, in the order in which
+	// they are consulted for incoming streams.
+	Handlers() []HandlerInfo
+
+	// RemoveStreamHandlerMatch removes the handler registered under pid
+	// with SetStreamHandlerMatch, if any, and returns whether a handler was
+	// removed. Unlike RemoveStreamHandler, it leaves a handler registered
+	// under pid with SetStreamHandler in place.
+	RemoveStreamHandlerMatch(pid protocol.ID) bool
+}
+
+// GetHandlerRegistry is a helper to "upcast" a Host to a HandlerRegistry by
+// using type assertion. Returns (nil, false) if the Host doesn't support
+// handler introspection.
+func GetHandlerRegistry(h Host) (hr HandlerRegistry, ok bool) {
+	hr, ok = h.(HandlerRegistry)
+	return hr, ok
+}
+
+// ProtocolInvalidator is implemented by Host implementations that cache what
+// they know about the protocols supported by remote peers, e.g. in the
+// identify service, beyond the peerstore's ProtoBook.
+//
+// Use the InvalidateProtocols helper rather than calling this interface
+// directly, so that Hosts that don't implement it are handled too.
+type ProtocolInvalidator interface {
+	// InvalidateProtocols forgets the protocols known to be supported by
+	// p, both in the peerstore's ProtoBook and in any other cache, so that
+	// they are negotiated again on the next stream, and re-learned e.g. by
+	// running identify again on an existing connection. This is needed
+	// when a remote peer is upgraded while a long-lived connection to it
+	// remains open.
+	InvalidateProtocols(p peer.ID) error
+}
+
+// InvalidateProtocols forgets the protocols known to be supported by p. If h
+// is a ProtocolInvalidator, its caches are cleared as well; otherwise only the
+// ProtoBook of its peerstore is.
+func InvalidateProtocols(h Host, p peer.ID) error {
+	if pi, ok := h.(ProtocolInvalidator); ok {
+		return pi.InvalidateProtocols(p)
+	}
+	return h.Peerstore().SetProtocols(p)
+}
+
+// PingHost is implemented by Host implementations that run the ping
+// protocol.
+//
+// To test whether a given Host runs the ping protocol, use the GetPinger
+// helper.
+type PingHost interface {
+	// Pinger returns the Pinger of the host.
+	Pinger() ping.Pinger
+}
+
+// GetPinger returns the Pinger of h if it is a PingHost. Returns (nil, false)
+// otherwise.
+func GetPinger(h Host) (ping.Pinger, bool) {
+	if ph, ok := h.(PingHost); ok {
+		return ph.Pinger(), true
+	}
+	return nil, false
+}
+
+// MiddlewareHost is implemented by Host implementations that support stream
+// middleware wrapping all protocol handlers.
+//
+// To test whether a given Host supports stream middleware, use the
+// GetMiddlewareHost helper.
+type MiddlewareHost interface {
+	// UseStreamMiddleware adds middlewares wrapping every stream handler,
+	// including the handlers set later, in the order given, after the
+	// middlewares added previously. The middlewares see inbound streams
+	// once their protocol has been negotiated, so Stream.Protocol can be
+	// used, e.g. with network.ForProtocols.
+	UseStreamMiddleware(middlewares ...network.StreamMiddleware)
+}
+
+// GetMiddlewareHost is a helper to "upcast" a Host to a MiddlewareHost by
+// using type assertion. Returns (nil, false) if the Host doesn't support
+// stream middleware.
+func GetMiddlewareHost(h Host) (mh MiddlewareHost, ok bool) {
+	mh, ok = h.(MiddlewareHost)
+	return mh, ok
+}
@@ -0,0 +1,201 @@
+// Package cbor implements the subset of DAG-CBOR needed to serialize records:
+// unsigned integers, byte strings, text strings, arrays and maps, with the
+// canonical (shortest) encoding of lengths and integers.
+//
+// Encoders are responsible for writing map keys in the DAG-CBOR order, i.e.
+// sorted by length first and then bytewise.
+package cbor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+const (
+	majorUint  = 0
+	majorBytes = 2
+	majorText  = 3
+	majorArray = 4
+	majorMap   = 5
+)
+
+var (
+	// ErrTruncated is returned when the data ends before the current item.
+	ErrTruncated = errors.New("cbor: unexpected end of data")
+	// ErrNonCanonical is returned for items that aren't encoded in the
+	// shortest form, or use indefinite lengths.
+	ErrNonCanonical = errors.New("cbor: non-canonical encoding")
+	// ErrTrailingData is returned by Finish if data is left after the
+	// decoded items.
+	ErrTrailingData = errors.New("cbor: trailing data")
+)
+
+func appendHeader(b []byte, major byte, n uint64) []byte {
+	m := major << 5
+	switch {
+	case n < 24:
+		return append(b, m|byte(n))
+	case n <= 0xff:
+		return append(b, m|24, byte(n))
+	case n <= 0xffff:
+		return append(b, m|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		return append(b, m|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		b = append(b, m|27)
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], n)
+		return append(b, buf[:]...)
+	}
+}
+
+// AppendUint appends an unsigned integer to b.
+func AppendUint(b []byte, n uint64) []byte {
+	return appendHeader(b, majorUint, n)
+}
+
+// AppendBytes appends a byte string to b.
+func AppendBytes(b []byte, v []byte) []byte {
+	return append(appendHeader(b, majorBytes, uint64(len(v))), v...)
+}
+
+// AppendText appends a text string to b.
+func AppendText(b []byte, s string) []byte {
+	return append(appendHeader(b, majorText, uint64(len(s))), s...)
+}
+
+// AppendArrayHeader appends the header of an array of n items to b. The
+// items must be appended next.
+func AppendArrayHeader(b []byte, n int) []byte {
+	return appendHeader(b, majorArray, uint64(n))
+}
+
+// AppendMapHeader appends the header of a map of n entries to b. The keys
+// and values must be appended next, alternately.
+func AppendMapHeader(b []byte, n int) []byte {
+	return appendHeader(b, majorMap, uint64(n))
+}
+
+// Decoder reads items from a byte slice.
+type Decoder struct {
+	data []byte
+}
+
+// NewDecoder returns a Decoder reading from data.
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+func (d *Decoder) header(major byte) (uint64, error) {
+	if len(d.data) == 0 {
+		return 0, ErrTruncated
+	}
+	if got := d.data[0] >> 5; got != major {
+		return 0, fmt.Errorf("cbor: expected major type %d, got %d", major, got)
+	}
+	info := d.data[0] & 0x1f
+	d.data = d.data[1:]
+
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, ErrNonCanonical
+	}
+	if len(d.data) < size {
+		return 0, ErrTruncated
+	}
+	var n uint64
+	for _, c := range d.data[:size] {
+		n = n<<8 | uint64(c)
+	}
+	d.data = d.data[size:]
+
+	// the shortest encoding must be used
+	var least uint64
+	switch size {
+	case 1:
+		least = 24
+	case 2:
+		least = 0x100
+	case 4:
+		least = 0x10000
+	case 8:
+		least = 0x100000000
+	}
+	if n < least {
+		return 0, ErrNonCanonical
+	}
+	return n, nil
+}
+
+func (d *Decoder) length(major byte) (int, error) {
+	n, err := d.header(major)
+	if err != nil {
+		return 0, err
+	}
+	// every item takes at least one byte, so this bounds allocations
+	if n > uint64(len(d.data)) {
+		return 0, ErrTruncated
+	}
+	return int(n), nil
+}
+
+// Uint reads an unsigned integer.
+func (d *Decoder) Uint() (uint64, error) {
+	return d.header(majorUint)
+}
+
+// Bytes reads a byte string. The returned slice aliases the decoded data.
+func (d *Decoder) Bytes() ([]byte, error) {
+	n, err := d.length(majorBytes)
+	if err != nil {
+		return nil, err
+	}
+	v := d.data[:n:n]
+	d.data = d.data[n:]
+	return v, nil
+}
+
+// Text reads a text string.
+func (d *Decoder) Text() (string, error) {
+	n, err := d.length(majorText)
+	if err != nil {
+		return "", err
+	}
+	if !utf8.Valid(d.data[:n]) {
+		return "", errors.New("cbor: invalid utf-8 in text string")
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s, nil
+}
+
+// ArrayHeader reads the header of an array, and returns its number of items.
+func (d *Decoder) ArrayHeader() (int, error) {
+	return d.length(majorArray)
+}
+
+// MapHeader reads the header of a map, and returns its number of entries.
+func (d *Decoder) MapHeader() (int, error) {
+	return d.length(majorMap)
+}
+
+// Finish returns ErrTrailingData if there is data left to decode.
+func (d *Decoder) Finish() error {
+	if len(d.data) != 0 {
+		return ErrTrailingData
+	}
+	return nil
+}
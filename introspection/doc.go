@@ -2,6 +2,13 @@
 // WILL change. For now, it is the simplest implementation to power the
 // proof-of-concept of the libp2p introspection framework.
 //
-// Package introspect contains the abstract skeleton of the introspection system
-// of go-libp2p, and holds the introspection data schema.
+// Package introspection contains the abstract skeleton of the introspection
+// system of go-libp2p, and holds the introspection data schema.
+//
+// The Introspector interface is implemented by components capable of capturing
+// the state of the system, and the Endpoint interface by servers exposing that
+// state to debugging UIs. The schema lives in the pb subpackage: pb.Runtime
+// describes the running process, pb.Connection and pb.Stream the open
+// connections and streams along with their pb.Traffic, and pb.State ties them
+// all together. Hosts expose both through host.IntrospectableHost.
 package introspection
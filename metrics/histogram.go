@@ -0,0 +1,159 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// DefaultLatencyBuckets are the upper bounds of the buckets used by
+// NewLatencyHistogram when none are given.
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// HistogramSnapshot is a point-in-time snapshot of a LatencyHistogram.
+//
+// Counts are cumulative, as in Prometheus histograms: Counts[i] is the number
+// of observations less than or equal to Buckets[i]. Observations greater than
+// the last bucket are only included in Count.
+type HistogramSnapshot struct {
+	Buckets []time.Duration
+	Counts  []uint64
+	Count   uint64
+	Sum     time.Duration
+}
+
+// Quantile returns an upper bound of the q-quantile (0 <= q <= 1) of the
+// observations, namely the smallest bucket containing it. It returns zero if
+// there are no observations, and -1 if the quantile falls beyond the last
+// bucket.
+func (s HistogramSnapshot) Quantile(q float64) time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(s.Count)))
+	if rank < 1 {
+		rank = 1
+	}
+	i := sort.Search(len(s.Counts), func(i int) bool { return s.Counts[i] >= rank })
+	if i == len(s.Counts) {
+		return -1
+	}
+	return s.Buckets[i]
+}
+
+// LatencyHistogram records the distribution of durations in fixed buckets. It
+// is safe for concurrent use.
+type LatencyHistogram struct {
+	mu      sync.Mutex
+	buckets []time.Duration
+	counts  []uint64
+	count   uint64
+	sum     time.Duration
+}
+
+// NewLatencyHistogram creates a LatencyHistogram with the given bucket upper
+// bounds, or DefaultLatencyBuckets if none are given. The buckets are sorted.
+func NewLatencyHistogram(buckets ...time.Duration) *LatencyHistogram {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := make([]time.Duration, len(buckets))
+	copy(b, buckets)
+	sort.Slice(b, func(i, j int) bool { return b[i] < b[j] })
+	return &LatencyHistogram{
+		buckets: b,
+		counts:  make([]uint64, len(b)),
+	}
+}
+
+// Observe records a duration.
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	i := sort.Search(len(h.buckets), func(i int) bool { return d <= h.buckets[i] })
+	h.mu.Lock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += d
+	h.mu.Unlock()
+}
+
+// Snapshot returns the current state of the histogram.
+func (h *LatencyHistogram) Snapshot() HistogramSnapshot {
+	s := HistogramSnapshot{
+		Buckets: make([]time.Duration, len(h.buckets)),
+		Counts:  make([]uint64, len(h.counts)),
+	}
+	copy(s.Buckets, h.buckets)
+	h.mu.Lock()
+	var cum uint64
+	for i, c := range h.counts {
+		cum += c
+		s.Counts[i] = cum
+	}
+	s.Count = h.count
+	s.Sum = h.sum
+	h.mu.Unlock()
+	return s
+}
+
+// Snapshot is a point-in-time export of all the metrics of a Reporter.
+type Snapshot struct {
+	Time time.Time
+
+	Totals     Stats
+	ByPeer     map[peer.ID]Stats
+	ByProtocol map[protocol.ID]Stats
+
+	// The latency fields are only set for HistogramReporters.
+	LatencyByPeer     map[peer.ID]HistogramSnapshot
+	LatencyByProtocol map[protocol.ID]HistogramSnapshot
+}
+
+// HistogramReporter is implemented by Reporters that also track latency
+// distributions per protocol and per peer, e.g. of request/response round
+// trips, so they can be exported to systems like Prometheus.
+type HistogramReporter interface {
+	Reporter
+
+	// LogLatency records a latency observed on a stream of the given
+	// protocol with the given peer.
+	LogLatency(time.Duration, protocol.ID, peer.ID)
+
+	GetLatencyForPeer(peer.ID) HistogramSnapshot
+	GetLatencyForProtocol(protocol.ID) HistogramSnapshot
+
+	// Snapshot exports all metrics at once.
+	Snapshot() Snapshot
+}
+
+// TakeSnapshot exports all metrics of r. If r is a HistogramReporter, its
+// Snapshot method is used; otherwise, only the bandwidth fields are set.
+func TakeSnapshot(r Reporter) Snapshot {
+	if hr, ok := r.(HistogramReporter); ok {
+		return hr.Snapshot()
+	}
+	return Snapshot{
+		Time:       time.Now(),
+		Totals:     r.GetBandwidthTotals(),
+		ByPeer:     r.GetBandwidthByPeer(),
+		ByProtocol: r.GetBandwidthByProtocol(),
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	h := NewLatencyHistogram(100*time.Millisecond, 10*time.Millisecond, time.Second)
+
+	if s := h.Snapshot(); s.Count != 0 || s.Quantile(0.5) != 0 {
+		t.Fatalf("expected empty histogram, got %+v", s)
+	}
+
+	for _, d := range []time.Duration{
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+		50 * time.Millisecond,
+		500 * time.Millisecond,
+		2 * time.Second,
+	} {
+		h.Observe(d)
+	}
+
+	s := h.Snapshot()
+	if s.Count != 5 {
+		t.Errorf("expected 5 observations, got %d", s.Count)
+	}
+	if s.Sum != 2565*time.Millisecond {
+		t.Errorf("unexpected sum %s", s.Sum)
+	}
+	expected := []uint64{2, 3, 4}
+	for i, c := range expected {
+		if s.Counts[i] != c {
+			t.Errorf("bucket %s: expected %d, got %d", s.Buckets[i], c, s.Counts[i])
+		}
+	}
+	if q := s.Quantile(0.4); q != 10*time.Millisecond {
+		t.Errorf("expected p40 <= 10ms, got %s", q)
+	}
+	if q := s.Quantile(0.6); q != 100*time.Millisecond {
+		t.Errorf("expected p60 <= 100ms, got %s", q)
+	}
+	if q := s.Quantile(0.5); q != 100*time.Millisecond {
+		t.Errorf("expected p50 (the 3rd of 5 observations) <= 100ms, got %s", q)
+	}
+	if q := s.Quantile(0); q != 10*time.Millisecond {
+		t.Errorf("expected p0 <= 10ms, got %s", q)
+	}
+	if q := s.Quantile(1); q != -1 {
+		t.Errorf("expected p100 beyond the last bucket, got %s", q)
+	}
+
+	// snapshots don't share state with the histogram
+	s.Buckets[0] = time.Hour
+	if b := h.Snapshot().Buckets[0]; b != 10*time.Millisecond {
+		t.Errorf("modifying a snapshot changed the histogram buckets to %s", b)
+	}
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	bwc := NewBandwidthCounter()
+	bwc.LogSentMessageStream(100, "/test/1.0.0", "peer")
+	s := TakeSnapshot(bwc)
+	if s.Time.IsZero() {
+		t.Error("expected snapshot time to be set")
+	}
+	if _, ok := s.ByProtocol["/test/1.0.0"]; !ok {
+		t.Error("expected protocol bandwidth in snapshot")
+	}
+	if s.LatencyByPeer != nil || s.LatencyByProtocol != nil {
+		t.Error("expected no latency metrics for a BandwidthCounter")
+	}
+}
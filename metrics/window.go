@@ -0,0 +1,273 @@
+package metrics
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// PeerStats are the bandwidth metrics of a peer.
+type PeerStats struct {
+	Peer peer.ID
+	Stats
+}
+
+// WindowedReporter is implemented by Reporters that also track the bandwidth
+// of each peer over a rolling window, so that connection managers can shed
+// the heaviest peers of the last minute rather than of all time.
+//
+// The Stats returned for a window hold the bytes transferred during the
+// window in TotalIn and TotalOut, and the average rates over the window in
+// RateIn and RateOut.
+type WindowedReporter interface {
+	Reporter
+
+	// GetBandwidthForPeerWindow returns the bandwidth metrics of the given
+	// peer over the last window.
+	GetBandwidthForPeerWindow(p peer.ID, window time.Duration) Stats
+
+	// TopPeers returns the n peers that transferred the most bytes, in
+	// both directions, over the last window, from the heaviest to the
+	// lightest. All peers are returned if n <= 0.
+	TopPeers(n int, window time.Duration) []PeerStats
+}
+
+// windowResolution is the granularity of the windows of a
+// WindowedBandwidthCounter.
+const windowResolution = time.Second
+
+// windowShards is the number of independently locked shards the peers of a
+// WindowedBandwidthCounter are spread over, so that logging messages of
+// different peers rarely contends on a lock.
+const windowShards = 32
+
+// WindowedBandwidthCounter is a BandwidthCounter that also tracks the
+// bandwidth of each peer over rolling windows, up to a maximum window, with a
+// one second resolution.
+//
+// Peers only use memory for the seconds they were active in during the
+// maximum window, and are forgotten once idle for longer than it.
+type WindowedBandwidthCounter struct {
+	*BandwidthCounter
+
+	slots int64
+	clock func() time.Time
+
+	shards [windowShards]windowShard
+}
+
+var _ WindowedReporter = (*WindowedBandwidthCounter)(nil)
+
+type windowShard struct {
+	mu    sync.Mutex
+	peers map[peer.ID]*peerWindow
+	// rotated is the last slot the shard was swept for idle peers in.
+	rotated int64
+}
+
+// peerWindow holds the byte counts of the slots (time / windowResolution) a
+// peer was active in, oldest first. Slots older than the maximum window are
+// dropped as new ones are added.
+type peerWindow struct {
+	slots []windowSlot
+}
+
+type windowSlot struct {
+	stamp   int64
+	in, out uint64
+}
+
+// NewWindowedBandwidthCounter creates a new WindowedBandwidthCounter
+// answering queries for windows of up to maxWindow. Longer windows are
+// truncated to maxWindow.
+func NewWindowedBandwidthCounter(maxWindow time.Duration) *WindowedBandwidthCounter {
+	slots := int64((maxWindow + windowResolution - 1) / windowResolution)
+	if slots < 1 {
+		slots = 1
+	}
+	bwc := &WindowedBandwidthCounter{
+		BandwidthCounter: NewBandwidthCounter(),
+		slots:            slots,
+		clock:            time.Now,
+	}
+	for i := range bwc.shards {
+		bwc.shards[i].peers = make(map[peer.ID]*peerWindow)
+	}
+	return bwc
+}
+
+// LogSentMessageStream records the size of an outgoing message over a single logical stream.
+// Bandwidth is associated with the given protocol.ID and peer.ID.
+func (bwc *WindowedBandwidthCounter) LogSentMessageStream(size int64, proto protocol.ID, p peer.ID) {
+	bwc.BandwidthCounter.LogSentMessageStream(size, proto, p)
+	bwc.mark(p, 0, uint64(size))
+}
+
+// LogRecvMessageStream records the size of an incoming message over a single logical stream.
+// Bandwidth is associated with the given protocol.ID and peer.ID.
+func (bwc *WindowedBandwidthCounter) LogRecvMessageStream(size int64, proto protocol.ID, p peer.ID) {
+	bwc.BandwidthCounter.LogRecvMessageStream(size, proto, p)
+	bwc.mark(p, uint64(size), 0)
+}
+
+func (bwc *WindowedBandwidthCounter) shard(p peer.ID) *windowShard {
+	// FNV-1a
+	h := uint32(2166136261)
+	for i := 0; i < len(p); i++ {
+		h ^= uint32(p[i])
+		h *= 16777619
+	}
+	return &bwc.shards[h%windowShards]
+}
+
+func (bwc *WindowedBandwidthCounter) mark(p peer.ID, in, out uint64) {
+	now := bwc.clock().UnixNano() / int64(windowResolution)
+	sh := bwc.shard(p)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if now > sh.rotated {
+		sh.rotated = now
+		sh.expire(now - bwc.slots)
+	}
+	w, ok := sh.peers[p]
+	if !ok {
+		w = &peerWindow{}
+		sh.peers[p] = w
+	}
+	w.add(now, in, out, bwc.slots)
+}
+
+// expire forgets the peers idle since the given slot, inclusive.
+func (sh *windowShard) expire(cutoff int64) {
+	for p, w := range sh.peers {
+		if w.last() <= cutoff {
+			delete(sh.peers, p)
+		}
+	}
+}
+
+func (w *peerWindow) add(now int64, in, out uint64, slots int64) {
+	if n := len(w.slots); n > 0 && w.slots[n-1].stamp == now {
+		w.slots[n-1].in += in
+		w.slots[n-1].out += out
+		return
+	}
+	drop := 0
+	for drop < len(w.slots) && w.slots[drop].stamp <= now-slots {
+		drop++
+	}
+	if drop > 0 {
+		w.slots = append(w.slots[:0], w.slots[drop:]...)
+	}
+	w.slots = append(w.slots, windowSlot{stamp: now, in: in, out: out})
+}
+
+func (w *peerWindow) last() int64 {
+	if len(w.slots) == 0 {
+		return 0
+	}
+	return w.slots[len(w.slots)-1].stamp
+}
+
+// GetBandwidthForPeerWindow returns the bandwidth metrics of the given peer
+// over the last window.
+func (bwc *WindowedBandwidthCounter) GetBandwidthForPeerWindow(p peer.ID, window time.Duration) Stats {
+	now, n, window := bwc.span(window)
+
+	sh := bwc.shard(p)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if w, ok := sh.peers[p]; ok {
+		return w.stats(now, n, window)
+	}
+	return Stats{}
+}
+
+// TopPeers returns the n peers that transferred the most bytes over the last
+// window, from the heaviest to the lightest. All peers are returned if n <= 0.
+// Peers that didn't transfer anything during the window are omitted.
+func (bwc *WindowedBandwidthCounter) TopPeers(n int, window time.Duration) []PeerStats {
+	now, slots, window := bwc.span(window)
+
+	var out []PeerStats
+	for i := range bwc.shards {
+		sh := &bwc.shards[i]
+		sh.mu.Lock()
+		for p, w := range sh.peers {
+			s := w.stats(now, slots, window)
+			if s.TotalIn+s.TotalOut > 0 {
+				out = append(out, PeerStats{Peer: p, Stats: s})
+			}
+		}
+		sh.mu.Unlock()
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		ti, tj := out[i].TotalIn+out[i].TotalOut, out[j].TotalIn+out[j].TotalOut
+		if ti != tj {
+			return ti > tj
+		}
+		return bytes.Compare([]byte(out[i].Peer), []byte(out[j].Peer)) < 0
+	})
+	if n > 0 && len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+// span returns the current slot index and the number of slots of the given
+// window, truncated to the maximum window.
+func (bwc *WindowedBandwidthCounter) span(window time.Duration) (now int64, n int64, truncated time.Duration) {
+	now = bwc.clock().UnixNano() / int64(windowResolution)
+	n = int64((window + windowResolution - 1) / windowResolution)
+	if n > bwc.slots {
+		n = bwc.slots
+	}
+	if n < 1 {
+		n = 1
+	}
+	return now, n, time.Duration(n) * windowResolution
+}
+
+func (w *peerWindow) stats(now, n int64, window time.Duration) Stats {
+	var s Stats
+	for i := len(w.slots) - 1; i >= 0 && w.slots[i].stamp > now-n; i-- {
+		if w.slots[i].stamp <= now {
+			s.TotalIn += int64(w.slots[i].in)
+			s.TotalOut += int64(w.slots[i].out)
+		}
+	}
+	s.RateIn = float64(s.TotalIn) / window.Seconds()
+	s.RateOut = float64(s.TotalOut) / window.Seconds()
+	return s
+}
+
+// Reset clears all stats.
+func (bwc *WindowedBandwidthCounter) Reset() {
+	bwc.BandwidthCounter.Reset()
+
+	for i := range bwc.shards {
+		sh := &bwc.shards[i]
+		sh.mu.Lock()
+		sh.peers = make(map[peer.ID]*peerWindow)
+		sh.mu.Unlock()
+	}
+}
+
+// TrimIdle trims all timers idle since the given time.
+func (bwc *WindowedBandwidthCounter) TrimIdle(since time.Time) {
+	bwc.BandwidthCounter.TrimIdle(since)
+
+	cutoff := since.UnixNano()/int64(windowResolution) - 1
+	for i := range bwc.shards {
+		sh := &bwc.shards[i]
+		sh.mu.Lock()
+		sh.expire(cutoff)
+		sh.mu.Unlock()
+	}
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestWindowedBandwidthCounter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	bwc := NewWindowedBandwidthCounter(time.Minute)
+	bwc.clock = func() time.Time { return now }
+
+	heavyThenIdle, steady := peer.ID("heavy"), peer.ID("steady")
+
+	bwc.LogRecvMessageStream(10000, "/proto", heavyThenIdle)
+	for i := 0; i < 90; i++ {
+		now = now.Add(time.Second)
+		bwc.LogSentMessageStream(100, "/proto", steady)
+	}
+
+	if s := bwc.GetBandwidthForPeerWindow(steady, 10*time.Second); s.TotalOut != 1000 || s.RateOut != 100 {
+		t.Fatalf("unexpected 10s stats for steady peer: %+v", s)
+	}
+	// windows are truncated to the maximum window
+	if s := bwc.GetBandwidthForPeerWindow(steady, time.Hour); s.TotalOut != 6000 {
+		t.Fatalf("expected the window to be truncated to a minute, got %+v", s)
+	}
+	if s := bwc.GetBandwidthForPeerWindow(heavyThenIdle, time.Minute); s.TotalIn != 0 {
+		t.Fatalf("expected traffic older than the window to be ignored, got %+v", s)
+	}
+
+	// all-time, the heavy peer is the heaviest, but not over the last minute
+	top := bwc.TopPeers(1, time.Minute)
+	if len(top) != 1 || top[0].Peer != steady {
+		t.Fatalf("expected the steady peer to be the top peer, got %+v", top)
+	}
+
+	bwc.LogRecvMessageStream(10000, "/proto", heavyThenIdle)
+	top = bwc.TopPeers(0, time.Minute)
+	if len(top) != 2 || top[0].Peer != heavyThenIdle || top[1].Peer != steady {
+		t.Fatalf("expected the heavy peer to be the top peer, got %+v", top)
+	}
+
+	bwc.TrimIdle(now)
+	if len(bwc.TopPeers(0, time.Minute)) != 2 {
+		t.Fatal("expected peers active at the cutoff to be kept")
+	}
+	bwc.TrimIdle(now.Add(time.Second))
+	if len(bwc.TopPeers(0, time.Minute)) != 0 {
+		t.Fatal("expected idle peers to be trimmed")
+	}
+}
+
+func TestWindowedBandwidthCounterExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	bwc := NewWindowedBandwidthCounter(time.Minute)
+	bwc.clock = func() time.Time { return now }
+
+	idle := peer.ID("idle")
+	sh := bwc.shard(idle)
+	// find another peer in the same shard
+	var active peer.ID
+	for i := 0; ; i++ {
+		active = peer.ID(fmt.Sprintf("active-%d", i))
+		if bwc.shard(active) == sh {
+			break
+		}
+	}
+
+	bwc.LogRecvMessageStream(100, "/proto", idle)
+	for i := 0; i < 3; i++ {
+		bwc.LogRecvMessageStream(100, "/proto", active)
+	}
+	if n := len(sh.peers[active].slots); n != 1 {
+		t.Fatalf("expected messages of the same second to share a slot, got %d slots", n)
+	}
+
+	// the idle peer is forgotten once the shard rotates past the maximum
+	// window
+	now = now.Add(59 * time.Second)
+	bwc.LogRecvMessageStream(100, "/proto", active)
+	if _, ok := sh.peers[idle]; !ok {
+		t.Fatal("expected a peer active within the window to be kept")
+	}
+	now = now.Add(time.Second)
+	bwc.LogRecvMessageStream(100, "/proto", active)
+	if _, ok := sh.peers[idle]; ok {
+		t.Fatal("expected a peer idle for the maximum window to be expired")
+	}
+	if n := len(sh.peers[active].slots); n != 2 {
+		t.Fatalf("expected slots older than the window to be dropped, got %d slots", n)
+	}
+	if s := bwc.GetBandwidthForPeerWindow(active, time.Minute); s.TotalIn != 200 {
+		t.Fatalf("unexpected stats for the active peer: %+v", s)
+	}
+}
+
+func BenchmarkWindowedBandwidthCounter(b *testing.B) {
+	bwc := NewWindowedBandwidthCounter(time.Minute)
+	peers := make([]peer.ID, 64)
+	for i := range peers {
+		peers[i] = peer.ID(fmt.Sprintf("peer-%d", i))
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			bwc.LogSentMessageStream(100, "/proto", peers[i%len(peers)])
+			i++
+		}
+	})
+}
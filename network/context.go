@@ -13,12 +13,16 @@ var DialPeerTimeout = 60 * time.Second
 type noDialCtxKey struct{}
 type dialPeerTimeoutCtxKey struct{}
 type forceDirectDialCtxKey struct{}
-type useTransientCtxKey struct{}
+type allowLimitedConnCtxKey struct{}
+type dialSourceCtxKey struct{}
+type skipNegotiationCtxKey struct{}
+type negotiationTimeoutCtxKey struct{}
+type noProtocolFallbackCtxKey struct{}
 type simConnectCtxKey struct{ isClient bool }
 
 var noDial = noDialCtxKey{}
 var forceDirectDial = forceDirectDialCtxKey{}
-var useTransient = useTransientCtxKey{}
+var allowLimitedConn = allowLimitedConnCtxKey{}
 var simConnectIsServer = simConnectCtxKey{}
 var simConnectIsClient = simConnectCtxKey{isClient: true}
 
@@ -94,17 +98,111 @@ func WithDialPeerTimeout(ctx context.Context, timeout time.Duration) context.Con
 	return context.WithValue(ctx, dialPeerTimeoutCtxKey{}, timeout)
 }
 
+// WithAllowLimitedConn constructs a new context with an option that instructs
+// the network that it is acceptable to use a limited connection (see
+// Stats.Limited) when opening a new stream. Without it, NewStream fails with
+// ErrLimitedConn if the only connections to the peer are limited.
+//
+// Protocols that only exchange a few small messages, such as identify or hole
+// punching coordination, should opt in; bulk transfers should not.
+func WithAllowLimitedConn(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, allowLimitedConn, reason)
+}
+
+// GetAllowLimitedConn returns true if the allow limited conn option is set in
+// the context.
+func GetAllowLimitedConn(ctx context.Context) (allowLimited bool, reason string) {
+	v := ctx.Value(allowLimitedConn)
+	if v != nil {
+		return true, v.(string)
+	}
+	return false, ""
+}
+
 // WithUseTransient constructs a new context with an option that instructs the network
 // that it is acceptable to use a transient connection when opening a new stream.
+//
+// Deprecated: use WithAllowLimitedConn.
 func WithUseTransient(ctx context.Context, reason string) context.Context {
-	return context.WithValue(ctx, useTransient, reason)
+	return WithAllowLimitedConn(ctx, reason)
 }
 
 // GetUseTransient returns true if the use transient option is set in the context.
+//
+// Deprecated: use GetAllowLimitedConn.
 func GetUseTransient(ctx context.Context) (usetransient bool, reason string) {
-	v := ctx.Value(useTransient)
-	if v != nil {
-		return true, v.(string)
+	return GetAllowLimitedConn(ctx)
+}
+
+// WithDialSource constructs a new context with an option that attributes any
+// dials made with it to the given source, for the purpose of DialBudget
+// accounting.
+func WithDialSource(ctx context.Context, src DialSource) context.Context {
+	return context.WithValue(ctx, dialSourceCtxKey{}, src)
+}
+
+// GetDialSource returns the dial source set in the context, or
+// DialSourceUnknown if none was set.
+func GetDialSource(ctx context.Context) DialSource {
+	if src, ok := ctx.Value(dialSourceCtxKey{}).(DialSource); ok {
+		return src
+	}
+	return DialSourceUnknown
+}
+
+// WithSkipNegotiation constructs a new context with an option that instructs the
+// host to open a stream for a pre-agreed protocol without waiting for
+// multistream negotiation to complete, saving a round trip.
+//
+// The option only applies when a single protocol is requested and the remote
+// peer is known to support it (e.g. it is listed in the peer's ProtoBook
+// entry). Otherwise, and if the remote peer rejects the protocol, the host
+// falls back to regular negotiation.
+// EXPERIMENTAL
+func WithSkipNegotiation(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, skipNegotiationCtxKey{}, reason)
+}
+
+// GetSkipNegotiation returns true if the skip negotiation option is set in the context.
+// EXPERIMENTAL
+func GetSkipNegotiation(ctx context.Context) (skip bool, reason string) {
+	if v, ok := ctx.Value(skipNegotiationCtxKey{}).(string); ok {
+		return true, v
+	}
+	return false, ""
+}
+
+// WithNegotiationTimeout returns a new context with an option that bounds the
+// time spent on protocol negotiation when opening a new stream. If the remote
+// peer doesn't complete negotiation in time, the stream is reset and NewStream
+// returns an error.
+//
+// The timeout only applies to negotiation; dialing is bounded by the
+// DialPeer timeout.
+func WithNegotiationTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, negotiationTimeoutCtxKey{}, timeout)
+}
+
+// GetNegotiationTimeout returns the negotiation timeout set in the context, if
+// any.
+func GetNegotiationTimeout(ctx context.Context) (timeout time.Duration, ok bool) {
+	timeout, ok = ctx.Value(negotiationTimeoutCtxKey{}).(time.Duration)
+	return timeout, ok
+}
+
+// WithNoProtocolFallback constructs a new context with an option that instructs
+// the host to only attempt the first (most preferred) protocol passed to
+// NewStream, and to fail instead of falling back to the other protocols if the
+// remote peer doesn't support it.
+func WithNoProtocolFallback(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, noProtocolFallbackCtxKey{}, reason)
+}
+
+// GetNoProtocolFallback returns true if the no protocol fallback option is set
+// in the context.
+func GetNoProtocolFallback(ctx context.Context) (nofallback bool, reason string) {
+	if v, ok := ctx.Value(noProtocolFallbackCtxKey{}).(string); ok {
+		return true, v
 	}
 	return false, ""
 }
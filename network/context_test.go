@@ -57,3 +57,48 @@ func TestSimultaneousConnect(t *testing.T) {
 		require.Equal(t, reason, "foo")
 	})
 }
+
+func TestDialSource(t *testing.T) {
+	require.Equal(t, DialSourceUnknown, GetDialSource(context.Background()))
+	ctx := WithDialSource(context.Background(), DialSourceMDNS)
+	require.Equal(t, DialSourceMDNS, GetDialSource(ctx))
+}
+
+func TestSkipNegotiation(t *testing.T) {
+	skip, _ := GetSkipNegotiation(context.Background())
+	require.False(t, skip)
+	ctx := WithSkipNegotiation(context.Background(), "hot path")
+	skip, reason := GetSkipNegotiation(ctx)
+	require.True(t, skip)
+	require.Equal(t, "hot path", reason)
+}
+
+func TestNegotiationTimeout(t *testing.T) {
+	_, ok := GetNegotiationTimeout(context.Background())
+	require.False(t, ok)
+	ctx := WithNegotiationTimeout(context.Background(), 5*time.Second)
+	timeout, ok := GetNegotiationTimeout(ctx)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Second, timeout)
+}
+
+func TestNoProtocolFallback(t *testing.T) {
+	nofallback, _ := GetNoProtocolFallback(context.Background())
+	require.False(t, nofallback)
+	nofallback, reason := GetNoProtocolFallback(WithNoProtocolFallback(context.Background(), "strict"))
+	require.True(t, nofallback)
+	require.Equal(t, "strict", reason)
+}
+
+func TestAllowLimitedConn(t *testing.T) {
+	allow, _ := GetAllowLimitedConn(context.Background())
+	require.False(t, allow)
+	allow, reason := GetAllowLimitedConn(WithAllowLimitedConn(context.Background(), "identify"))
+	require.True(t, allow)
+	require.Equal(t, "identify", reason)
+
+	// the deprecated transient option is the same option
+	allow, reason = GetAllowLimitedConn(WithUseTransient(context.Background(), "legacy"))
+	require.True(t, allow)
+	require.Equal(t, "legacy", reason)
+}
@@ -0,0 +1,147 @@
+package network
+
+import (
+	"bytes"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// ConnDeduplicator chooses which of two connections to the same peer to keep,
+// e.g. when both peers dialed each other simultaneously.
+//
+// Network implementations consult the ConnDeduplicator whenever they hold
+// more than one connection to a peer, close the connection that isn't kept
+// once its open streams are done, and open new streams on the kept
+// connection only. This allows embedders to implement policies such as
+// preferring QUIC over TCP, or direct connections over relayed ones.
+//
+// Both peers run their own ConnDeduplicator, so a policy must be symmetric:
+// given the same two connections, seen from either end, both peers must keep
+// the same one. Otherwise, each peer may close a different connection and
+// none is left. In particular, the Direction of a connection differs between
+// its two ends, and its Opened time may differ slightly.
+type ConnDeduplicator interface {
+	// Keep returns the connection to keep, a or b.
+	Keep(a, b Conn) Conn
+}
+
+// ConnDeduplicatorFunc adapts a function to the ConnDeduplicator interface.
+type ConnDeduplicatorFunc func(a, b Conn) Conn
+
+var _ ConnDeduplicator = ConnDeduplicatorFunc(nil)
+
+// Keep calls f(a, b).
+func (f ConnDeduplicatorFunc) Keep(a, b Conn) Conn {
+	return f(a, b)
+}
+
+// DefaultConnDeduplicator keeps connections that aren't limited (see
+// Stats.Limited) over limited ones. Between two connections that are both
+// limited or both not, it keeps the one dialed by the peer with the smaller
+// peer ID, which both peers agree on. Between two connections dialed by the
+// same peer, it keeps the one with the smaller ConnState.SessionID if both
+// have one, and otherwise the one with the smaller listener multiaddr.
+//
+// Without session IDs, two connections to the same listener multiaddr can
+// only be told apart by the dialer's multiaddrs, which differ between the two
+// ends if the dialer is behind a NAT, so the ends may not agree. Network
+// implementations should expose session IDs to avoid this.
+var DefaultConnDeduplicator ConnDeduplicator = ConnDeduplicatorFunc(func(a, b Conn) Conn {
+	if la, lb := a.Stat().Limited, b.Stat().Limited; la != lb {
+		if la {
+			return b
+		}
+		return a
+	}
+	return keepDialedBySmallerID(a, b)
+})
+
+// PreferTransports returns a ConnDeduplicator that keeps the connection over
+// the most preferred transport, identified by the multiaddr protocol codes
+// in order of decreasing preference, e.g. ma.P_QUIC before ma.P_TCP. A
+// connection's transport is the first of the given protocols its remote
+// address includes; connections over none of them come last. Ties are broken
+// by DefaultConnDeduplicator.
+func PreferTransports(codes ...int) ConnDeduplicator {
+	rank := func(c Conn) int {
+		for i, code := range codes {
+			if _, err := c.RemoteMultiaddr().ValueForProtocol(code); err == nil {
+				return i
+			}
+		}
+		return len(codes)
+	}
+	return ConnDeduplicatorFunc(func(a, b Conn) Conn {
+		ra, rb := rank(a), rank(b)
+		switch {
+		case ra < rb:
+			return a
+		case rb < ra:
+			return b
+		default:
+			return DefaultConnDeduplicator.Keep(a, b)
+		}
+	})
+}
+
+// keepDialedBySmallerID keeps the connection dialed by the peer with the
+// smaller peer ID. If both were dialed by the same peer, it compares what both
+// ends see the same way first: the session IDs of the connections, then the
+// listener addresses, and only then the dialer addresses, so that the result
+// doesn't depend on the order of a and b.
+func keepDialedBySmallerID(a, b Conn) Conn {
+	da, db := dialer(a), dialer(b)
+	switch {
+	case da < db:
+		return a
+	case db < da:
+		return b
+	}
+	if sa, sb := sessionID(a), sessionID(b); sa != "" && sb != "" && sa != sb {
+		if sa < sb {
+			return a
+		}
+		return b
+	}
+	dialA, listenA := endpoints(a)
+	dialB, listenB := endpoints(b)
+	if c := bytes.Compare(listenA, listenB); c != 0 {
+		if c < 0 {
+			return a
+		}
+		return b
+	}
+	if bytes.Compare(dialB, dialA) < 0 {
+		return b
+	}
+	return a
+}
+
+func sessionID(c Conn) string {
+	state, _ := GetConnState(c)
+	return state.SessionID
+}
+
+func dialer(c Conn) string {
+	if c.Stat().Direction == DirOutbound {
+		return string(c.LocalPeer())
+	}
+	return string(c.RemotePeer())
+}
+
+// endpoints returns the binary multiaddrs of the dialing and the listening
+// ends of the connection.
+func endpoints(c Conn) (dialer, listener []byte) {
+	local, remote := addrBytes(c.LocalMultiaddr()), addrBytes(c.RemoteMultiaddr())
+	if c.Stat().Direction == DirOutbound {
+		return local, remote
+	}
+	return remote, local
+}
+
+func addrBytes(a ma.Multiaddr) []byte {
+	if a == nil {
+		return nil
+	}
+	return a.Bytes()
+}
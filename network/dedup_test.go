@@ -0,0 +1,127 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+type dedupConn struct {
+	Conn
+	name          string
+	local, remote peer.ID
+	dir           Direction
+	limited       bool
+	laddr, raddr  ma.Multiaddr
+	session       string
+}
+
+func (c *dedupConn) LocalPeer() peer.ID            { return c.local }
+func (c *dedupConn) RemotePeer() peer.ID           { return c.remote }
+func (c *dedupConn) LocalMultiaddr() ma.Multiaddr  { return c.laddr }
+func (c *dedupConn) RemoteMultiaddr() ma.Multiaddr { return c.raddr }
+func (c *dedupConn) ConnState() ConnState          { return ConnState{SessionID: c.session} }
+func (c *dedupConn) Stat() ConnStats {
+	return ConnStats{Stats: Stats{Direction: c.dir, Limited: c.limited}}
+}
+
+// flip returns the connection as seen from the remote end.
+func (c *dedupConn) flip() *dedupConn {
+	dir := DirInbound
+	if c.dir == DirInbound {
+		dir = DirOutbound
+	}
+	return &dedupConn{name: c.name, local: c.remote, remote: c.local, dir: dir, limited: c.limited, laddr: c.raddr, raddr: c.laddr, session: c.session}
+}
+
+func TestConnDeduplicator(t *testing.T) {
+	small, large := peer.ID("a"), peer.ID("b")
+	tcp := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	quic := ma.StringCast("/ip4/1.2.3.4/udp/1/quic")
+	localTCP := ma.StringCast("/ip4/5.6.7.8/tcp/2")
+	localQUIC := ma.StringCast("/ip4/5.6.7.8/udp/2/quic")
+
+	// seen from the peer with the smaller ID
+	dialedBySmall := &dedupConn{name: "small", local: small, remote: large, dir: DirOutbound, laddr: localTCP, raddr: tcp}
+	dialedByLarge := &dedupConn{name: "large", local: small, remote: large, dir: DirInbound, laddr: localQUIC, raddr: quic}
+
+	keep := func(d ConnDeduplicator, a, b *dedupConn) string {
+		k := d.Keep(a, b).(*dedupConn).name
+		// the other end must agree, whatever the order
+		require.Equal(t, k, d.Keep(b.flip(), a.flip()).(*dedupConn).name)
+		return k
+	}
+
+	require.Equal(t, "small", keep(DefaultConnDeduplicator, dialedBySmall, dialedByLarge))
+	require.Equal(t, "small", keep(DefaultConnDeduplicator, dialedByLarge, dialedBySmall))
+	require.Equal(t, "large", keep(PreferTransports(ma.P_QUIC, ma.P_TCP), dialedBySmall, dialedByLarge))
+
+	dialedBySmall.limited = true
+	require.Equal(t, "large", keep(DefaultConnDeduplicator, dialedBySmall, dialedByLarge))
+}
+
+func TestConnDeduplicatorSameDialer(t *testing.T) {
+	small, large := peer.ID("a"), peer.ID("b")
+	listen := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+
+	// both dialed by the peer with the smaller ID, seen from its end
+	first := &dedupConn{name: "first", local: small, remote: large, dir: DirOutbound,
+		laddr: ma.StringCast("/ip4/5.6.7.8/tcp/1000"), raddr: listen}
+	second := &dedupConn{name: "second", local: small, remote: large, dir: DirOutbound,
+		laddr: ma.StringCast("/ip4/5.6.7.8/tcp/2000"), raddr: listen}
+	// same dialer address, different listener addresses
+	third := &dedupConn{name: "third", local: small, remote: large, dir: DirOutbound,
+		laddr: ma.StringCast("/ip4/5.6.7.8/tcp/1000"), raddr: ma.StringCast("/ip4/1.2.3.4/tcp/2")}
+
+	for _, c := range []struct {
+		a, b *dedupConn
+		exp  string
+	}{
+		{first, second, "first"},
+		{first, third, "first"},
+		{second, third, "second"},
+	} {
+		for _, pair := range [][2]*dedupConn{{c.a, c.b}, {c.b, c.a}} {
+			a, b := pair[0], pair[1]
+			require.Equal(t, c.exp, DefaultConnDeduplicator.Keep(a, b).(*dedupConn).name, "%s, %s", a.name, b.name)
+			require.Equal(t, c.exp, DefaultConnDeduplicator.Keep(a.flip(), b.flip()).(*dedupConn).name, "%s, %s flipped", a.name, b.name)
+		}
+	}
+}
+
+func TestConnDeduplicatorNAT(t *testing.T) {
+	small, large := peer.ID("a"), peer.ID("b")
+	listen := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+
+	// both dialed by the peer with the smaller ID, from behind a NAT: the
+	// dialer sees its private addresses, the listener the mapped ones
+	natConn := func(name, private, mapped, session string) (dialerEnd, listenerEnd *dedupConn) {
+		dialerEnd = &dedupConn{name: name, local: small, remote: large, dir: DirOutbound,
+			laddr: ma.StringCast(private), raddr: listen, session: session}
+		listenerEnd = &dedupConn{name: name, local: large, remote: small, dir: DirInbound,
+			laddr: listen, raddr: ma.StringCast(mapped), session: session}
+		return dialerEnd, listenerEnd
+	}
+	// the NAT maps the ports in the opposite order
+	firstDialer, firstListener := natConn("first", "/ip4/192.168.0.2/tcp/1000", "/ip4/5.6.7.8/tcp/2000", "2")
+	secondDialer, secondListener := natConn("second", "/ip4/192.168.0.2/tcp/2000", "/ip4/5.6.7.8/tcp/1000", "1")
+
+	for _, pair := range [][2]*dedupConn{{firstDialer, secondDialer}, {secondDialer, firstDialer}} {
+		require.Equal(t, "second", DefaultConnDeduplicator.Keep(pair[0], pair[1]).(*dedupConn).name)
+	}
+	for _, pair := range [][2]*dedupConn{{firstListener, secondListener}, {secondListener, firstListener}} {
+		require.Equal(t, "second", DefaultConnDeduplicator.Keep(pair[0], pair[1]).(*dedupConn).name)
+	}
+
+	// without session IDs, connections to different listener addresses are
+	// still told apart by both ends
+	firstDialer.session, firstListener.session = "", ""
+	other := ma.StringCast("/ip4/1.2.3.4/tcp/2")
+	secondDialer.session, secondDialer.raddr = "", other
+	secondListener.session, secondListener.laddr = "", other
+	require.Equal(t, "first", DefaultConnDeduplicator.Keep(secondDialer, firstDialer).(*dedupConn).name)
+	require.Equal(t, "first", DefaultConnDeduplicator.Keep(secondListener, firstListener).(*dedupConn).name)
+}
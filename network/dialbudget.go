@@ -0,0 +1,101 @@
+package network
+
+import "context"
+
+// DialSource identifies the subsystem on whose behalf a dial is made.
+type DialSource string
+
+const (
+	// DialSourceUnknown is used for dials that haven't been attributed to a
+	// source.
+	DialSourceUnknown DialSource = ""
+	// DialSourceDHT is used for dials triggered by DHT lookups.
+	DialSourceDHT DialSource = "dht"
+	// DialSourceMDNS is used for dials to peers discovered over mDNS.
+	DialSourceMDNS DialSource = "mdns"
+	// DialSourceManual is used for dials explicitly requested by the
+	// application.
+	DialSourceManual DialSource = "manual"
+	// DialSourcePeerExchange is used for dials to peers learned through peer
+	// exchange (e.g. pubsub PX).
+	DialSourcePeerExchange DialSource = "px"
+)
+
+// DialBudgetLimits configures a DialBudget.
+type DialBudgetLimits struct {
+	// Total is the maximum number of concurrent dials across all sources.
+	Total int
+
+	// Default is the maximum number of concurrent dials a source may
+	// trigger, unless overridden in PerSource.
+	Default int
+
+	// PerSource overrides the concurrent dial limit of specific sources.
+	PerSource map[DialSource]int
+
+	// Reserved is the number of dial slots (out of Total) guaranteed to each
+	// source with pending dials, so that a busy source cannot starve the
+	// others.
+	Reserved int
+}
+
+// Limit returns the concurrent dial limit applying to the given source.
+func (l *DialBudgetLimits) Limit(src DialSource) int {
+	if lim, ok := l.PerSource[src]; ok {
+		return lim
+	}
+	return l.Default
+}
+
+// DialBudgetStat is a snapshot of the dial budget usage of a source.
+type DialBudgetStat struct {
+	// Limit is the concurrent dial limit of the source.
+	Limit int
+	// Active is the number of dials currently in progress.
+	Active int
+	// Queued is the number of dials waiting for a slot.
+	Queued int
+	// Granted is the total number of dial slots granted so far.
+	Granted uint64
+	// Rejected is the total number of dials that gave up waiting for a slot.
+	Rejected uint64
+}
+
+// DialBudget caps the number of concurrent dials each DialSource may trigger.
+//
+// Network implementations supporting dial budgets, see DialBudgetNetwork,
+// consult the budget before dialing, using the source attached to the context
+// with WithDialSource.
+type DialBudget interface {
+	// Reserve blocks until a dial slot is available for the given source, or
+	// the context is done, in which case the context error is returned.
+	//
+	// On success, the caller must call the returned function once the dial
+	// has completed to release the slot.
+	//
+	// Implementations must not starve sources: while a source has queued
+	// dials, it must be granted at least DialBudgetLimits.Reserved slots
+	// irrespective of the load other sources generate.
+	Reserve(ctx context.Context, src DialSource) (release func(), err error)
+
+	// Stat returns the budget usage of all sources seen so far.
+	Stat() map[DialSource]DialBudgetStat
+}
+
+// DialBudgetNetwork is implemented by Networks that support dial budgets.
+//
+// To test whether a given Network supports dial budgets, use the
+// GetDialBudgetNetwork helper.
+type DialBudgetNetwork interface {
+	// SetDialBudget sets the budget consulted before dialing. A nil budget
+	// removes all limits.
+	SetDialBudget(DialBudget)
+}
+
+// GetDialBudgetNetwork is a helper to "upcast" a Network to a
+// DialBudgetNetwork by using type assertion. Returns (nil, false) if the
+// Network doesn't support dial budgets.
+func GetDialBudgetNetwork(n Network) (dn DialBudgetNetwork, ok bool) {
+	dn, ok = n.(DialBudgetNetwork)
+	return dn, ok
+}
@@ -0,0 +1,47 @@
+package network
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// AddrDelay is an address to dial, along with the delay to wait, counted from
+// the start of the dial attempt, before dialing it.
+type AddrDelay struct {
+	Addr  ma.Multiaddr
+	Delay time.Duration
+}
+
+// DialRanker ranks the addresses of a peer for dialing.
+//
+// Network implementations dial the ranked addresses in order of increasing
+// delay, and stop dialing the remaining addresses as soon as a connection is
+// established. This allows embedders to implement policies such as dialing
+// QUIC before TCP, or happy eyeballs between IPv6 and IPv4 addresses.
+type DialRanker interface {
+	// Rank returns the addresses of p to dial, along with their delays.
+	// Addresses that are not returned are not dialed.
+	Rank(p peer.ID, addrs []ma.Multiaddr) []AddrDelay
+}
+
+// DialRankerFunc adapts a function to the DialRanker interface.
+type DialRankerFunc func(p peer.ID, addrs []ma.Multiaddr) []AddrDelay
+
+var _ DialRanker = DialRankerFunc(nil)
+
+// Rank calls f(p, addrs).
+func (f DialRankerFunc) Rank(p peer.ID, addrs []ma.Multiaddr) []AddrDelay {
+	return f(p, addrs)
+}
+
+// NoDelayDialRanker is a DialRanker that dials all addresses at once.
+var NoDelayDialRanker DialRanker = DialRankerFunc(func(_ peer.ID, addrs []ma.Multiaddr) []AddrDelay {
+	ranking := make([]AddrDelay, 0, len(addrs))
+	for _, a := range addrs {
+		ranking = append(ranking, AddrDelay{Addr: a})
+	}
+	return ranking
+})
@@ -20,14 +20,24 @@ var ErrNoRemoteAddrs = errors.New("no remote addresses")
 // option and no usable connection is available.
 var ErrNoConn = errors.New("no usable connection to peer")
 
+// ErrLimitedConn is returned when attempting to open a stream to a peer with only limited
+// connections, without specifying the AllowLimitedConn option.
+var ErrLimitedConn = errors.New("limited connection to peer")
+
 // ErrTransientConn is returned when attempting to open a stream to a peer with only a transient
 // connection, without specifying the UseTransient option.
-var ErrTransientConn = errors.New("transient connection to peer")
+//
+// Deprecated: use ErrLimitedConn.
+var ErrTransientConn = ErrLimitedConn
 
 // ErrResourceLimitExceeded is returned when attempting to perform an operation that would
 // exceed system resource limits.
 var ErrResourceLimitExceeded = temporaryError("resource limit exceeded")
 
+// ErrPaused is returned when attempting to open a stream or dial a peer while the
+// local host has been paused (see host.PausableHost).
+var ErrPaused = temporaryError("host is paused")
+
 // ErrResourceScopeClosed is returned when attemptig to reserve resources in a closed resource
 // scope.
 var ErrResourceScopeClosed = errors.New("resource scope closed")
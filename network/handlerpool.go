@@ -0,0 +1,104 @@
+package network
+
+import (
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// ErrHandlerPoolFull is returned by HandlerPool.Submit when a stream is
+// rejected because the handler queue is full.
+var ErrHandlerPoolFull = temporaryError("stream handler pool is full")
+
+// RejectionPolicy determines what a HandlerPool does with a stream when its
+// queue is full.
+type RejectionPolicy int
+
+const (
+	// RejectReset resets the stream and returns ErrHandlerPoolFull (default).
+	RejectReset RejectionPolicy = iota
+
+	// RejectBlock blocks the caller until the stream can be queued. This
+	// applies backpressure to the muxer, which stops accepting new streams.
+	RejectBlock
+
+	// RejectOldest resets the oldest queued stream to make room for the new
+	// one.
+	RejectOldest
+)
+
+func (p RejectionPolicy) String() string {
+	str := [...]string{"Reset", "Block", "Oldest"}
+	if p < 0 || int(p) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[p]
+}
+
+// HandlerPoolLimit bounds the concurrency of stream handlers.
+type HandlerPoolLimit struct {
+	// Workers is the maximum number of handlers running concurrently.
+	Workers int
+	// Queue is the maximum number of streams waiting for a worker.
+	Queue int
+}
+
+// HandlerPoolConfig configures a HandlerPool, e.g. one set on a Network with
+// HandlerPoolNetwork.SetHandlerPool.
+type HandlerPoolConfig struct {
+	// Global bounds all handlers, regardless of protocol.
+	Global HandlerPoolLimit
+
+	// PerProtocol bounds the handlers of specific protocols. These limits
+	// apply in addition to the Global limit.
+	PerProtocol map[protocol.ID]HandlerPoolLimit
+
+	// Policy determines what to do with streams that can't be queued.
+	Policy RejectionPolicy
+}
+
+// HandlerPoolStat is a snapshot of the state of a HandlerPool, or of the
+// share of it used by a protocol.
+type HandlerPoolStat struct {
+	// Running is the number of handlers currently running.
+	Running int
+	// Queued is the number of streams waiting for a worker.
+	Queued int
+	// Rejected is the total number of streams rejected so far.
+	Rejected uint64
+}
+
+// HandlerPool runs stream handlers on a bounded set of workers rather than on
+// one goroutine per stream, protecting the node against inbound stream
+// floods.
+//
+// Network implementations that support handler pools, see HandlerPoolNetwork,
+// submit every inbound stream to the pool once its protocol has been
+// negotiated.
+type HandlerPool interface {
+	// Submit schedules the handler to be run for the given stream. If the
+	// stream can't be queued, the pool's RejectionPolicy applies.
+	Submit(s Stream, h StreamHandler) error
+
+	// Stat returns the global state of the pool.
+	Stat() HandlerPoolStat
+
+	// ProtocolStat returns the state of the pool for the given protocol.
+	ProtocolStat(protocol.ID) HandlerPoolStat
+}
+
+// HandlerPoolNetwork is implemented by Networks that support handler pools.
+//
+// To test whether a given Network supports handler pools, use the
+// GetHandlerPoolNetwork helper.
+type HandlerPoolNetwork interface {
+	// SetHandlerPool sets the pool running the handlers of inbound streams.
+	// A nil pool runs every handler on its own goroutine.
+	SetHandlerPool(HandlerPool)
+}
+
+// GetHandlerPoolNetwork is a helper to "upcast" a Network to a
+// HandlerPoolNetwork by using type assertion. Returns (nil, false) if the
+// Network doesn't support handler pools.
+func GetHandlerPoolNetwork(n Network) (hn HandlerPoolNetwork, ok bool) {
+	hn, ok = n.(HandlerPoolNetwork)
+	return hn, ok
+}
@@ -0,0 +1,72 @@
+package network
+
+import (
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// StreamMiddleware wraps a StreamHandler, so that cross-cutting concerns such
+// as authentication, rate limiting or tracing can be applied to the streams of
+// all protocols without modifying each protocol's handler. The returned
+// handler may reset the stream instead of calling next.
+//
+//	func Trace(next network.StreamHandler) network.StreamHandler {
+//		return func(s network.Stream) {
+//			start := time.Now()
+//			next(s)
+//			log.Printf("%s: %s", s.Protocol(), time.Since(start))
+//		}
+//	}
+type StreamMiddleware func(next StreamHandler) StreamHandler
+
+// ChainStreamMiddleware returns a StreamMiddleware applying the given
+// middlewares in order: the first one is the outermost, and sees streams
+// first.
+func ChainStreamMiddleware(middlewares ...StreamMiddleware) StreamMiddleware {
+	return func(next StreamHandler) StreamHandler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// ForProtocols returns a StreamMiddleware applying m to the streams of the
+// given protocols only, and passing other streams straight to the handler.
+// It relies on Stream.Protocol, so it is only effective once protocols have
+// been negotiated, e.g. in host middleware.
+func ForProtocols(m StreamMiddleware, protos ...protocol.ID) StreamMiddleware {
+	return func(next StreamHandler) StreamHandler {
+		wrapped := m(next)
+		return func(s Stream) {
+			proto := s.Protocol()
+			for _, p := range protos {
+				if p == proto {
+					wrapped(s)
+					return
+				}
+			}
+			next(s)
+		}
+	}
+}
+
+// MiddlewareNetwork is implemented by Networks that support stream
+// middleware.
+//
+// To test whether a given Network supports stream middleware, use the
+// GetMiddlewareNetwork helper.
+type MiddlewareNetwork interface {
+	// UseStreamMiddleware adds middlewares wrapping the handler set with
+	// SetStreamHandler, in the order given, after the middlewares added
+	// previously. The middlewares see inbound streams before their protocol
+	// is negotiated; use host middleware to wrap protocol handlers.
+	UseStreamMiddleware(middlewares ...StreamMiddleware)
+}
+
+// GetMiddlewareNetwork is a helper to "upcast" a Network to a
+// MiddlewareNetwork by using type assertion. Returns (nil, false) if the
+// Network doesn't support stream middleware.
+func GetMiddlewareNetwork(n Network) (mn MiddlewareNetwork, ok bool) {
+	mn, ok = n.(MiddlewareNetwork)
+	return mn, ok
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+type protoStream struct {
+	Stream
+	proto protocol.ID
+}
+
+func (s *protoStream) Protocol() protocol.ID { return s.proto }
+
+func TestStreamMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) StreamMiddleware {
+		return func(next StreamHandler) StreamHandler {
+			return func(s Stream) {
+				calls = append(calls, name)
+				next(s)
+			}
+		}
+	}
+	handler := func(s Stream) { calls = append(calls, "handler") }
+
+	chained := ChainStreamMiddleware(record("a"), record("b"))(handler)
+	chained(&protoStream{proto: "/foo"})
+	require.Equal(t, []string{"a", "b", "handler"}, calls)
+
+	calls = nil
+	filtered := ForProtocols(record("a"), "/foo")(handler)
+	filtered(&protoStream{proto: "/foo"})
+	filtered(&protoStream{proto: "/bar"})
+	require.Equal(t, []string{"a", "handler", "handler"}, calls)
+}
@@ -89,6 +89,9 @@ type MuxedConn interface {
 // Multiplexer wraps a net.Conn with a stream multiplexing
 // implementation and returns a MuxedConn that supports opening
 // multiple streams over the underlying net.Conn
+//
+// Multiplexers may describe their capabilities by implementing
+// FeaturedMultiplexer.
 type Multiplexer interface {
 	// NewConn constructs a new connection
 	NewConn(c net.Conn, isServer bool, scope PeerScope) (MuxedConn, error)
@@ -0,0 +1,81 @@
+package network
+
+import (
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// MuxerFeatures describes the capabilities of a stream multiplexer, so that
+// higher layers can adapt their behavior to e.g. yamux, mplex or the native
+// streams of QUIC.
+type MuxerFeatures struct {
+	// FlowControl is true if the multiplexer applies per-stream flow
+	// control, so that a slow reader on one stream doesn't block the others.
+	FlowControl bool
+
+	// ResetErrorCodes is true if stream resets carry an error code to the
+	// remote peer.
+	ResetErrorCodes bool
+
+	// MaxStreams is the maximum number of concurrent streams the
+	// multiplexer allows per connection, or 0 if unlimited or unknown.
+	MaxStreams int
+}
+
+// FeaturedMultiplexer is implemented by Multiplexers that describe their
+// capabilities.
+//
+// To test whether a given Multiplexer describes its capabilities, use the
+// GetMuxerFeatures helper.
+type FeaturedMultiplexer interface {
+	Multiplexer
+
+	// Features returns the capabilities of the multiplexer.
+	Features() MuxerFeatures
+}
+
+// GetMuxerFeatures returns the capabilities of m if it is a
+// FeaturedMultiplexer. Returns (MuxerFeatures{}, false) otherwise.
+func GetMuxerFeatures(m Multiplexer) (MuxerFeatures, bool) {
+	if fm, ok := m.(FeaturedMultiplexer); ok {
+		return fm.Features(), true
+	}
+	return MuxerFeatures{}, false
+}
+
+// ConnState describes the properties negotiated when establishing a
+// connection.
+type ConnState struct {
+	// StreamMultiplexer is the ID of the negotiated stream multiplexer, e.g.
+	// "/yamux/1.0.0". It is empty for transports with native streams.
+	StreamMultiplexer protocol.ID
+
+	// MuxerFeatures are the capabilities of the stream multiplexer, or of
+	// the transport's native streams.
+	MuxerFeatures MuxerFeatures
+
+	// SessionID identifies the connection identically at both of its ends,
+	// e.g. a value derived from the transcript of the security handshake.
+	// It is empty if the transport doesn't provide one.
+	SessionID string
+}
+
+// ConnStateConn is implemented by Conns, and by the transport.CapableConns
+// they wrap, that expose the properties negotiated when establishing the
+// connection.
+//
+// To test whether a given Conn exposes its state, use the GetConnState
+// helper.
+type ConnStateConn interface {
+	// ConnState returns the properties negotiated when establishing the
+	// connection.
+	ConnState() ConnState
+}
+
+// GetConnState returns the properties negotiated when establishing c if it is
+// a ConnStateConn. Returns (ConnState{}, false) otherwise.
+func GetConnState(c Conn) (ConnState, bool) {
+	if csc, ok := c.(ConnStateConn); ok {
+		return csc.ConnState(), true
+	}
+	return ConnState{}, false
+}
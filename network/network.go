@@ -59,10 +59,22 @@ const (
 	// CannotConnect means recently attempted connecting but failed to connect.
 	// (should signal "made effort, failed")
 	CannotConnect
+
+	// Connecting means a connection attempt to the peer is in progress, and
+	// there is no open connection yet.
+	Connecting
+
+	// Limited means that all open connections to the peer are limited, e.g.
+	// relayed connections (see Stats.Limited).
+	Limited
+
+	// Draining means that the connections to the peer are being closed, e.g.
+	// by the connection manager. New streams should not be opened.
+	Draining
 )
 
 func (c Connectedness) String() string {
-	str := [...]string{"NotConnected", "Connected", "CanConnect", "CannotConnect"}
+	str := [...]string{"NotConnected", "Connected", "CanConnect", "CannotConnect", "Connecting", "Limited", "Draining"}
 	if c < 0 || int(c) >= len(str) {
 		return "(unrecognized)"
 	}
@@ -110,7 +122,22 @@ type Stats struct {
 	// Opened is the timestamp when this connection was opened.
 	Opened time.Time
 	// Transient indicates that this connection is transient and may be closed soon.
+	//
+	// Deprecated: use Limited. Implementations set both fields.
 	Transient bool
+	// Limited indicates that this connection is limited in duration or in
+	// the amount of data it may carry, and may be closed soon. In practice,
+	// these are connections through a circuit v2 relay. Streams are only
+	// opened on limited connections if the WithAllowLimitedConn option is
+	// given to NewStream.
+	Limited bool
+	// BytesIn and BytesOut are the number of bytes read from and written to
+	// the stream / conn so far. They are zero if the implementation doesn't
+	// track them.
+	BytesIn, BytesOut int64
+	// LastActive is the timestamp of the last read or write on the
+	// stream / conn, or the zero time if it isn't tracked.
+	LastActive time.Time
 	// Extra stores additional metadata about this connection.
 	Extra map[interface{}]interface{}
 }
@@ -133,6 +160,11 @@ type Network interface {
 
 	// NewStream returns a new stream to given peer p.
 	// If there is no connection to p, attempts to create one.
+	// Limited connections are only used if the context carries the
+	// WithAllowLimitedConn option; otherwise, if all connections to p are
+	// limited, NewStream fails with ErrLimitedConn. Networks with a
+	// StreamLimiter fail with ErrStreamRateLimited if the stream isn't
+	// allowed.
 	NewStream(context.Context, peer.ID) (Stream, error)
 
 	// Listen tells the network to start listening on given multiaddrs.
@@ -168,7 +200,14 @@ type Dialer interface {
 	// ClosePeer closes the connection to a given peer
 	ClosePeer(peer.ID) error
 
-	// Connectedness returns a state signaling connection capabilities
+	// Connectedness returns a state signaling connection capabilities.
+	//
+	// Connected is returned if there is at least one open, unlimited
+	// connection to the peer, and Limited if all open connections are limited
+	// (e.g. relayed). Without open connections, implementations that track
+	// recent dial outcomes return CanConnect after a graceful disconnect and
+	// CannotConnect after a failed dial, so that routing and connection
+	// management can prioritize dials; others return NotConnected.
 	Connectedness(peer.ID) Connectedness
 
 	// Peers returns the peers connected
@@ -0,0 +1,60 @@
+package network
+
+// StreamPriority is an advisory scheduling weight for a stream.
+type StreamPriority int
+
+const (
+	// PriorityDefault is the priority of streams that haven't been assigned
+	// one.
+	PriorityDefault StreamPriority = 0
+	// PriorityLow is for bulk transfers that should yield to other streams.
+	PriorityLow StreamPriority = -1
+	// PriorityHigh is for latency-sensitive protocols such as ping and
+	// identify.
+	PriorityHigh StreamPriority = 1
+)
+
+func (p StreamPriority) String() string {
+	switch p {
+	case PriorityLow:
+		return "Low"
+	case PriorityDefault:
+		return "Default"
+	case PriorityHigh:
+		return "High"
+	default:
+		return "(unrecognized)"
+	}
+}
+
+// PrioritizedStream is implemented by MuxedStreams (and Streams) whose
+// multiplexer can schedule the frames of the streams of a connection by
+// priority.
+//
+// Priorities are advisory: when several streams of the same connection have
+// data to send, the multiplexer should favor the streams with the highest
+// priority, without starving lower priority streams entirely. Priorities
+// don't affect the remote peer, and have no effect on other connections.
+//
+// To set the priority of a stream that may not support priorities, use the
+// SetStreamPriority helper.
+type PrioritizedStream interface {
+	MuxedStream
+
+	// SetPriority sets the scheduling priority of the stream. It may be
+	// called at any time; it applies to data written afterwards.
+	SetPriority(StreamPriority) error
+
+	// Priority returns the scheduling priority of the stream.
+	Priority() StreamPriority
+}
+
+// SetStreamPriority sets the priority of s if it is a PrioritizedStream. It
+// returns false, without error, if s doesn't support priorities.
+func SetStreamPriority(s MuxedStream, p StreamPriority) (bool, error) {
+	ps, ok := s.(PrioritizedStream)
+	if !ok {
+		return false, nil
+	}
+	return true, ps.SetPriority(p)
+}
@@ -0,0 +1,73 @@
+package network
+
+import (
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ErrStreamRateLimited is returned by NewStream, and used to reset inbound
+// streams, when a new stream exceeds the rate allowed by the StreamLimiter.
+var ErrStreamRateLimited = temporaryError("stream rate limit exceeded")
+
+// StreamRate is a token bucket rate limit on new streams.
+type StreamRate struct {
+	// Rate is the sustained number of new streams allowed per second. Zero
+	// means no limit.
+	Rate float64
+	// Burst is the number of new streams allowed at once, on top of the
+	// sustained rate.
+	Burst int
+}
+
+// StreamLimits configures a StreamLimiter.
+type StreamLimits struct {
+	// Global limits the new streams with all peers.
+	Global StreamRate
+
+	// PerPeer limits the new streams with each peer, unless overridden in
+	// Peers. These limits apply in addition to the Global limit.
+	PerPeer StreamRate
+
+	// Peers overrides the PerPeer limit of specific peers, e.g. trusted
+	// ones.
+	Peers map[peer.ID]StreamRate
+}
+
+// PeerLimit returns the per-peer limit applying to the given peer.
+func (l *StreamLimits) PeerLimit(p peer.ID) StreamRate {
+	if lim, ok := l.Peers[p]; ok {
+		return lim
+	}
+	return l.PerPeer
+}
+
+// StreamLimiter throttles the rate at which new streams are opened and
+// accepted, protecting services against stream floods.
+//
+// Network implementations supporting stream limiters consult the limiter in
+// NewStream, failing with ErrStreamRateLimited if the stream isn't allowed,
+// and before accepting inbound streams, resetting those that aren't allowed.
+type StreamLimiter interface {
+	// AllowStream returns true if a new stream with the given peer, in the
+	// given direction, is allowed now. The stream is counted against the
+	// limits if it is allowed.
+	AllowStream(p peer.ID, dir Direction) bool
+}
+
+// StreamLimiterNetwork is implemented by Networks that support stream
+// limiters.
+//
+// To test whether a given Network supports stream limiters, use the
+// GetStreamLimiterNetwork helper.
+type StreamLimiterNetwork interface {
+	// SetStreamLimiter sets the limiter consulted for new streams. A nil
+	// limiter removes all limits.
+	SetStreamLimiter(StreamLimiter)
+}
+
+// GetStreamLimiterNetwork is a helper to "upcast" a Network to a
+// StreamLimiterNetwork by using type assertion. Returns (nil, false) if the
+// Network doesn't support stream limiters.
+func GetStreamLimiterNetwork(n Network) (sn StreamLimiterNetwork, ok bool) {
+	sn, ok = n.(StreamLimiterNetwork)
+	return sn, ok
+}
@@ -1,7 +1,9 @@
 package peer
 
 import (
+	"bytes"
 	"fmt"
+	"sort"
 
 	ma "github.com/multiformats/go-multiaddr"
 )
@@ -22,26 +24,87 @@ func (pi AddrInfo) String() string {
 var ErrInvalidAddr = fmt.Errorf("invalid p2p multiaddr")
 
 // AddrInfosFromP2pAddrs converts a set of Multiaddrs to a set of AddrInfos.
+//
+// Addresses are grouped by peer; peers are returned in the order of their
+// first address, and duplicate addresses are removed.
 func AddrInfosFromP2pAddrs(maddrs ...ma.Multiaddr) ([]AddrInfo, error) {
-	m := make(map[ID][]ma.Multiaddr)
+	infos := make([]AddrInfo, 0, len(maddrs))
 	for _, maddr := range maddrs {
 		transport, id := SplitAddr(maddr)
 		if id == "" {
 			return nil, ErrInvalidAddr
 		}
-		if transport == nil {
-			if _, ok := m[id]; !ok {
-				m[id] = nil
+		info := AddrInfo{ID: id}
+		if transport != nil {
+			info.Addrs = []ma.Multiaddr{transport}
+		}
+		infos = append(infos, info)
+	}
+	return MergeAddrInfos(infos...), nil
+}
+
+// MergeAddrInfos merges AddrInfos of the same peer. Peers are returned in the
+// order in which they first appear, with the union of their addresses, in
+// order and without duplicates.
+//
+// Trailing /p2p components matching the peer's ID are stripped from the
+// addresses, so that /ip4/1.2.3.4/tcp/1 and /ip4/1.2.3.4/tcp/1/p2p/<id> are
+// considered the same address.
+func MergeAddrInfos(infos ...AddrInfo) []AddrInfo {
+	index := make(map[ID]int, len(infos))
+	out := make([]AddrInfo, 0, len(infos))
+	for _, info := range infos {
+		i, ok := index[info.ID]
+		if !ok {
+			i = len(out)
+			index[info.ID] = i
+			out = append(out, AddrInfo{ID: info.ID})
+		}
+		for _, addr := range info.Addrs {
+			if transport, id := SplitAddr(addr); id == info.ID {
+				if transport == nil {
+					continue
+				}
+				addr = transport
 			}
-		} else {
-			m[id] = append(m[id], transport)
+			out[i].Addrs = append(out[i].Addrs, addr)
 		}
 	}
-	ais := make([]AddrInfo, 0, len(m))
-	for id, maddrs := range m {
-		ais = append(ais, AddrInfo{ID: id, Addrs: maddrs})
+	for i := range out {
+		out[i].Addrs = UniqueAddrs(out[i].Addrs)
 	}
-	return ais, nil
+	return out
+}
+
+// UniqueAddrs returns the given addresses without duplicates, preserving the
+// order of their first occurrence. Nil addresses are dropped. The input slice
+// is not modified.
+func UniqueAddrs(addrs []ma.Multiaddr) []ma.Multiaddr {
+	if len(addrs) == 0 {
+		return addrs
+	}
+	seen := make(map[string]struct{}, len(addrs))
+	out := make([]ma.Multiaddr, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr == nil {
+			continue
+		}
+		key := string(addr.Bytes())
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, addr)
+	}
+	return out
+}
+
+// SortAddrs sorts the given addresses in place by their binary
+// representation, giving a deterministic order.
+func SortAddrs(addrs []ma.Multiaddr) {
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
 }
 
 // SplitAddr splits a p2p Multiaddr into a transport multiaddr and a peer ID.
@@ -87,7 +150,7 @@ func AddrInfoFromP2pAddr(m ma.Multiaddr) (*AddrInfo, error) {
 // AddrInfoToP2pAddrs converts an AddrInfo to a list of Multiaddrs.
 func AddrInfoToP2pAddrs(pi *AddrInfo) ([]ma.Multiaddr, error) {
 	var addrs []ma.Multiaddr
-	p2ppart, err := ma.NewComponent("p2p", Encode(pi.ID))
+	p2ppart, err := ma.NewComponent("p2p", Encode(pi.ID, EncodingBase58))
 	if err != nil {
 		return nil, err
 	}
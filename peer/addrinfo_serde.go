@@ -1,12 +1,20 @@
 package peer
 
 import (
+	"encoding"
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/libp2p/go-libp2p-core/internal/catch"
 	ma "github.com/multiformats/go-multiaddr"
 )
 
+var _ json.Marshaler = AddrInfo{}
+var _ json.Unmarshaler = (*AddrInfo)(nil)
+var _ encoding.TextMarshaler = AddrInfo{}
+var _ encoding.TextUnmarshaler = (*AddrInfo)(nil)
+
 // Helper struct for decoding as we can't unmarshal into an interface (Multiaddr).
 type addrInfoJson struct {
 	ID    ID
@@ -45,3 +53,45 @@ func (pi *AddrInfo) UnmarshalJSON(b []byte) (err error) {
 	pi.Addrs = addrs
 	return nil
 }
+
+// MarshalText returns the text encoding of the AddrInfo: a comma-separated
+// list of its addresses, each suffixed with /p2p/<peer-id>. An AddrInfo
+// without addresses is encoded as a bare /p2p/<peer-id> multiaddr.
+func (pi AddrInfo) MarshalText() (res []byte, err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p addr info marshal") }()
+
+	addrs, err := AddrInfoToP2pAddrs(&pi)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, len(addrs))
+	for i, addr := range addrs {
+		strs[i] = addr.String()
+	}
+	return []byte(strings.Join(strs, ",")), nil
+}
+
+// UnmarshalText restores the AddrInfo from its text encoding. All addresses
+// must refer to the same peer.
+func (pi *AddrInfo) UnmarshalText(b []byte) (err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p addr info unmarshal") }()
+
+	parts := strings.Split(string(b), ",")
+	maddrs := make([]ma.Multiaddr, 0, len(parts))
+	for _, part := range parts {
+		maddr, err := ma.NewMultiaddr(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		maddrs = append(maddrs, maddr)
+	}
+	infos, err := AddrInfosFromP2pAddrs(maddrs...)
+	if err != nil {
+		return err
+	}
+	if len(infos) != 1 {
+		return fmt.Errorf("expected addresses of a single peer, got %d peers", len(infos))
+	}
+	*pi = infos[0]
+	return nil
+}
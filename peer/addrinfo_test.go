@@ -150,3 +150,76 @@ func TestAddrInfoJSON(t *testing.T) {
 		t.Fatalf("expected addrs to match %v, got %v", maddrFull, addrInfo.Addrs)
 	}
 }
+
+func TestAddrInfoText(t *testing.T) {
+	maddrOther := ma.StringCast("/ip6/::1/udp/1234/quic")
+	ai := AddrInfo{ID: testID, Addrs: []ma.Multiaddr{maddrTpt, maddrOther}}
+	out, err := ai.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addrInfo AddrInfo
+	if err := addrInfo.UnmarshalText(out); err != nil {
+		t.Fatal(err)
+	}
+	if addrInfo.ID != testID {
+		t.Fatalf("expected ID to equal %s, got %s", testID.Pretty(), addrInfo.ID.Pretty())
+	}
+	if len(addrInfo.Addrs) != 2 || !addrInfo.Addrs[0].Equal(maddrTpt) || !addrInfo.Addrs[1].Equal(maddrOther) {
+		t.Fatalf("expected addrs to match %v, got %v", ai.Addrs, addrInfo.Addrs)
+	}
+
+	out, err = AddrInfo{ID: testID}.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != maddrPeer.String() {
+		t.Fatalf("expected %s, got %s", maddrPeer, out)
+	}
+	if err := addrInfo.UnmarshalText(out); err != nil {
+		t.Fatal(err)
+	}
+	if addrInfo.ID != testID || len(addrInfo.Addrs) != 0 {
+		t.Fatalf("expected bare peer ID, got %s", addrInfo)
+	}
+
+	if err := addrInfo.UnmarshalText([]byte(maddrTpt.String())); err == nil {
+		t.Fatal("expected an error for an address without a peer ID")
+	}
+}
+
+func TestMergeAddrInfos(t *testing.T) {
+	maddrOther := ma.StringCast("/ip6/::1/udp/1234/quic")
+	otherID, err := Decode("QmSoLer265NRgSp2LA3dPaeykiS1J6DifTC88f5uVQKNAd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos := MergeAddrInfos(
+		AddrInfo{ID: testID, Addrs: []ma.Multiaddr{maddrTpt}},
+		AddrInfo{ID: otherID},
+		AddrInfo{ID: testID, Addrs: []ma.Multiaddr{maddrFull, maddrOther, maddrPeer}},
+	)
+	if len(infos) != 2 || infos[0].ID != testID || infos[1].ID != otherID {
+		t.Fatalf("unexpected merge result: %v", infos)
+	}
+	if len(infos[0].Addrs) != 2 || !infos[0].Addrs[0].Equal(maddrTpt) || !infos[0].Addrs[1].Equal(maddrOther) {
+		t.Fatalf("expected deduplicated addrs, got %v", infos[0].Addrs)
+	}
+	if len(infos[1].Addrs) != 0 {
+		t.Fatalf("expected no addrs, got %v", infos[1].Addrs)
+	}
+}
+
+func TestUniqueAndSortAddrs(t *testing.T) {
+	b := ma.StringCast("/ip4/1.2.3.4/tcp/2")
+	a := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	addrs := UniqueAddrs([]ma.Multiaddr{b, a, nil, b})
+	if len(addrs) != 2 || !addrs[0].Equal(b) || !addrs[1].Equal(a) {
+		t.Fatalf("unexpected unique addrs: %v", addrs)
+	}
+	SortAddrs(addrs)
+	if !addrs[0].Equal(a) || !addrs[1].Equal(b) {
+		t.Fatalf("unexpected sorted addrs: %v", addrs)
+	}
+}
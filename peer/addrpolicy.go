@@ -0,0 +1,128 @@
+package peer
+
+import (
+	"errors"
+	"net"
+
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// ErrNoAllowedAddrs is returned by AddrInfo.Validate when none of the
+// addresses of an AddrInfo with addresses is allowed by the policy.
+var ErrNoAllowedAddrs = errors.New("no address allowed by policy")
+
+// AddrPolicy decides which addresses are acceptable, e.g. for a DHT server
+// storing the addresses of other peers.
+//
+// Addresses are judged by their first IP component. Addresses without one,
+// e.g. /dns4 addresses, can't be judged without resolving them, and are
+// allowed by the policies of this package.
+type AddrPolicy interface {
+	// AllowAddr returns true if addr is acceptable.
+	AllowAddr(addr ma.Multiaddr) bool
+}
+
+// AddrPolicyFunc adapts a function to the AddrPolicy interface.
+type AddrPolicyFunc func(addr ma.Multiaddr) bool
+
+var _ AddrPolicy = AddrPolicyFunc(nil)
+
+// AllowAddr calls f(addr).
+func (f AddrPolicyFunc) AllowAddr(addr ma.Multiaddr) bool {
+	return f(addr)
+}
+
+var (
+	// PublicOnly only allows publicly routable addresses, rejecting
+	// private (LAN), loopback, link-local, unspecified, multicast and
+	// reserved addresses.
+	PublicOnly AddrPolicy = AddrPolicyFunc(func(addr ma.Multiaddr) bool {
+		return allowIP(addr, false)
+	})
+
+	// AllowPrivate allows publicly routable and private (LAN) addresses,
+	// rejecting loopback, link-local, unspecified, multicast and reserved
+	// addresses.
+	AllowPrivate AddrPolicy = AddrPolicyFunc(func(addr ma.Multiaddr) bool {
+		return allowIP(addr, true)
+	})
+
+	// DenyMulticast allows all addresses but multicast ones.
+	DenyMulticast AddrPolicy = AddrPolicyFunc(func(addr ma.Multiaddr) bool {
+		ip, ok := firstIP(addr)
+		return !ok || !ip.IsMulticast()
+	})
+)
+
+// AllAddrPolicies returns an AddrPolicy allowing the addresses allowed by all
+// of the given policies.
+func AllAddrPolicies(policies ...AddrPolicy) AddrPolicy {
+	return AddrPolicyFunc(func(addr ma.Multiaddr) bool {
+		for _, p := range policies {
+			if !p.AllowAddr(addr) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Validate returns a copy of the AddrInfo with only the addresses allowed by
+// policy, in order. A nil policy allows all addresses. pi is not modified.
+//
+// An error is returned if the ID is empty, and ErrNoAllowedAddrs is returned
+// along with the filtered copy if pi has addresses but none is allowed.
+func (pi AddrInfo) Validate(policy AddrPolicy) (AddrInfo, error) {
+	out := AddrInfo{ID: pi.ID}
+	if err := pi.ID.Validate(); err != nil {
+		return out, err
+	}
+	for _, addr := range pi.Addrs {
+		if addr == nil || (policy != nil && !policy.AllowAddr(addr)) {
+			continue
+		}
+		out.Addrs = append(out.Addrs, addr)
+	}
+	if len(pi.Addrs) > 0 && len(out.Addrs) == 0 {
+		return out, ErrNoAllowedAddrs
+	}
+	return out, nil
+}
+
+func allowIP(addr ma.Multiaddr, allowPrivate bool) bool {
+	ip, ok := firstIP(addr)
+	if !ok {
+		return true
+	}
+	if ip.IsUnspecified() || ip.IsLoopback() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return !inRanges(ip4, manet.Unroutable4) && (allowPrivate || !inRanges(ip4, manet.Private4))
+	}
+	return !inRanges(ip, manet.Unroutable6) && (allowPrivate || !inRanges(ip, manet.Private6))
+}
+
+func firstIP(addr ma.Multiaddr) (ip net.IP, ok bool) {
+	ma.ForEach(addr, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_IP6ZONE:
+			return true
+		case ma.P_IP4, ma.P_IP6:
+			ip, ok = net.IP(c.RawValue()), true
+		}
+		return false
+	})
+	return ip, ok
+}
+
+func inRanges(ip net.IP, ranges []*net.IPNet) bool {
+	for _, r := range ranges {
+		if r.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
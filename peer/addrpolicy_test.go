@@ -0,0 +1,59 @@
+package peer_test
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	. "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestAddrInfoValidate(t *testing.T) {
+	addrs := map[string][]bool{
+		// address: allowed by {PublicOnly, AllowPrivate, DenyMulticast}
+		"/ip4/1.2.3.4/tcp/4001":           {true, true, true},
+		"/ip6/2001:4860::1/udp/4001/quic": {true, true, true},
+		"/dns4/example.com/tcp/4001":      {true, true, true},
+		"/ip4/192.168.1.2/tcp/4001":       {false, true, true},
+		"/ip6/fd00::1/tcp/4001":           {false, true, true},
+		"/ip4/127.0.0.1/tcp/4001":         {false, false, true},
+		"/ip4/0.0.0.0/tcp/4001":           {false, false, true},
+		"/ip6/fe80::1/tcp/4001":           {false, false, true},
+		"/ip4/169.254.1.1/tcp/4001":       {false, false, true},
+		"/ip4/198.51.100.1/tcp/4001":      {false, false, true},
+		"/ip4/224.0.0.251/udp/5353":       {false, false, false},
+		"/ip6/ff02::fb/udp/5353":          {false, false, false},
+	}
+	policies := []AddrPolicy{PublicOnly, AllowPrivate, DenyMulticast}
+	for s, allowed := range addrs {
+		addr := ma.StringCast(s)
+		for i, p := range policies {
+			if p.AllowAddr(addr) != allowed[i] {
+				t.Errorf("policy %d: expected AllowAddr(%s) to be %t", i, s, allowed[i])
+			}
+		}
+	}
+
+	pub, priv := ma.StringCast("/ip4/1.2.3.4/tcp/4001"), ma.StringCast("/ip4/10.0.0.1/tcp/4001")
+	info := AddrInfo{ID: testID, Addrs: []ma.Multiaddr{priv, pub}}
+	valid, err := info.Validate(PublicOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valid.Addrs) != 1 || !valid.Addrs[0].Equal(pub) {
+		t.Fatalf("expected only the public address, got %v", valid.Addrs)
+	}
+	if len(info.Addrs) != 2 {
+		t.Fatal("expected Validate not to modify the AddrInfo")
+	}
+
+	if _, err := (AddrInfo{ID: testID, Addrs: []ma.Multiaddr{priv}}).Validate(PublicOnly); err != ErrNoAllowedAddrs {
+		t.Fatalf("expected ErrNoAllowedAddrs, got %v", err)
+	}
+	if _, err := (AddrInfo{ID: testID}).Validate(PublicOnly); err != nil {
+		t.Fatalf("expected an AddrInfo without addresses to be valid, got %v", err)
+	}
+	if _, err := (AddrInfo{Addrs: []ma.Multiaddr{pub}}).Validate(nil); err != ErrEmptyPeerID {
+		t.Fatalf("expected ErrEmptyPeerID, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package peer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/internal/catch"
+	pb "github.com/libp2p/go-libp2p-core/peer/pb"
+	"github.com/libp2p/go-libp2p-core/record"
+
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+var _ record.Record = (*PeerRecordDelta)(nil)
+
+func init() {
+	record.RegisterType(&PeerRecordDelta{})
+}
+
+// PeerRecordDeltaEnvelopeDomain is the domain string used for peer record
+// deltas contained in a Envelope.
+const PeerRecordDeltaEnvelopeDomain = "libp2p-peer-record-delta"
+
+// PeerRecordDeltaEnvelopePayloadType is the type hint used to identify peer
+// record deltas in a Envelope.
+//
+// There is no registered multicodec for deltas yet, so a path-like identifier
+// is used instead.
+var PeerRecordDeltaEnvelopePayloadType = []byte("/libp2p/peer-record-delta")
+
+// ErrDeltaBaseMismatch is returned when applying a PeerRecordDelta to a
+// PeerRecord that is not the delta's base record.
+var ErrDeltaBaseMismatch = errors.New("peer record delta does not apply to the given record")
+
+// PeerRecordDelta describes the address changes between two PeerRecords of the
+// same peer. Peers whose addresses change frequently, e.g. mobile peers, can
+// share deltas instead of full PeerRecords to save bandwidth.
+//
+// A delta references the Seq of the record it is based on, and can only be
+// applied to that record:
+//
+//	delta, err := peer.DiffPeerRecords(lastRec, newRec)
+//	envelope, err := record.Seal(delta, myPrivateKey)
+//
+// and on the receiving side, after checking the signer of the delta:
+//
+//	if err := delta.VerifySigner(envelope); err != nil {
+//		return err
+//	}
+//	newRec, err := delta.Apply(lastRec)
+//
+// Receivers that don't have the base record must request the full PeerRecord.
+//
+// Deltas treat addresses as a set: they don't encode the order of the
+// addresses of the updated record, nor duplicate addresses. The record
+// returned by Apply has the same set of addresses as the updated record, but
+// not necessarily in the same order, so it may not be Equal to it.
+type PeerRecordDelta struct {
+	// PeerID is the ID of the peer the delta belongs to.
+	PeerID ID
+
+	// BaseSeq is the Seq of the PeerRecord the delta applies to.
+	BaseSeq uint64
+
+	// Seq is the Seq of the PeerRecord resulting from applying the delta.
+	Seq uint64
+
+	// Added contains the addresses to add to the base record.
+	Added []ma.Multiaddr
+
+	// Removed contains the addresses to remove from the base record.
+	Removed []ma.Multiaddr
+}
+
+// DiffPeerRecords returns a PeerRecordDelta that transforms base into updated.
+// Both records must belong to the same peer and advertise the same protocols,
+// and updated must be newer than base. If updated only reorders the addresses
+// of base, the delta is empty.
+func DiffPeerRecords(base, updated *PeerRecord) (*PeerRecordDelta, error) {
+	if base.PeerID != updated.PeerID {
+		return nil, fmt.Errorf("cannot diff records of different peers: %s, %s", base.PeerID, updated.PeerID)
+	}
+	if updated.Seq <= base.Seq {
+		return nil, fmt.Errorf("updated record seq %d is not newer than base seq %d", updated.Seq, base.Seq)
+	}
+	if !protocolsEqual(base.Protocols, updated.Protocols) {
+		return nil, fmt.Errorf("cannot diff records with different protocols, share the full record instead")
+	}
+	return &PeerRecordDelta{
+		PeerID:  base.PeerID,
+		BaseSeq: base.Seq,
+		Seq:     updated.Seq,
+		Added:   subtractAddrs(updated.Addrs, base.Addrs),
+		Removed: subtractAddrs(base.Addrs, updated.Addrs),
+	}, nil
+}
+
+// Apply returns the PeerRecord resulting from applying the delta to base.
+// Addresses of base that weren't removed keep their order, and are followed by
+// the added addresses. The protocols of base are kept as is. base is not
+// modified.
+//
+// ErrDeltaBaseMismatch is returned if base doesn't belong to the delta's peer
+// or doesn't have the delta's BaseSeq.
+//
+// Apply doesn't check who issued the delta: callers must check VerifySigner
+// on the Envelope containing the delta first, otherwise any key could update
+// the addresses of any peer.
+func (d *PeerRecordDelta) Apply(base *PeerRecord) (*PeerRecord, error) {
+	if base.PeerID != d.PeerID || base.Seq != d.BaseSeq {
+		return nil, ErrDeltaBaseMismatch
+	}
+	kept := subtractAddrs(base.Addrs, d.Removed)
+	addrs := append(kept, subtractAddrs(d.Added, kept)...)
+	return &PeerRecord{PeerID: d.PeerID, Seq: d.Seq, Addrs: addrs, Protocols: base.Protocols}, nil
+}
+
+// VerifySigner checks that the PeerRecordDelta, contained in the given
+// Envelope, is about the peer the Envelope is attributed to, i.e. the peer of
+// the Envelope's Issuer.
+func (d *PeerRecordDelta) VerifySigner(envelope *record.Envelope) error {
+	if !d.PeerID.MatchesPublicKey(envelope.Issuer()) {
+		return ErrSignerMismatch
+	}
+	return nil
+}
+
+// Domain is used when signing and validating PeerRecordDeltas contained in
+// Envelopes. It is constant for all PeerRecordDelta instances.
+func (d *PeerRecordDelta) Domain() string {
+	return PeerRecordDeltaEnvelopeDomain
+}
+
+// Codec is a binary identifier for the PeerRecordDelta type. It is constant
+// for all PeerRecordDelta instances.
+func (d *PeerRecordDelta) Codec() []byte {
+	return PeerRecordDeltaEnvelopePayloadType
+}
+
+// UnmarshalRecord parses a PeerRecordDelta from a byte slice.
+// This method is called automatically when consuming a record.Envelope
+// whose PayloadType indicates that it contains a PeerRecordDelta.
+func (d *PeerRecordDelta) UnmarshalRecord(bytes []byte) (err error) {
+	if d == nil {
+		return fmt.Errorf("cannot unmarshal PeerRecordDelta to nil receiver")
+	}
+
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p peer record delta unmarshal") }()
+
+	var msg pb.PeerRecordDelta
+	if err := proto.Unmarshal(bytes, &msg); err != nil {
+		return err
+	}
+
+	var id ID
+	if err := id.UnmarshalBinary(msg.PeerId); err != nil {
+		return err
+	}
+	*d = PeerRecordDelta{
+		PeerID:  id,
+		BaseSeq: msg.BaseSeq,
+		Seq:     msg.Seq,
+		Added:   addrsFromProtobuf(msg.Added),
+		Removed: addrsFromProtobuf(msg.Removed),
+	}
+	return nil
+}
+
+// MarshalRecord serializes a PeerRecordDelta to a byte slice.
+// This method is called automatically when constructing a record.Envelope
+// using record.Seal.
+func (d *PeerRecordDelta) MarshalRecord() (res []byte, err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p peer record delta marshal") }()
+
+	idBytes, err := d.PeerID.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&pb.PeerRecordDelta{
+		PeerId:  idBytes,
+		BaseSeq: d.BaseSeq,
+		Seq:     d.Seq,
+		Added:   addrsToProtobuf(d.Added),
+		Removed: addrsToProtobuf(d.Removed),
+	})
+}
+
+// subtractAddrs returns the addresses of a that are not in b, preserving order.
+func subtractAddrs(a, b []ma.Multiaddr) []ma.Multiaddr {
+	exclude := make(map[string]struct{}, len(b))
+	for _, addr := range b {
+		exclude[string(addr.Bytes())] = struct{}{}
+	}
+	var out []ma.Multiaddr
+	for _, addr := range a {
+		if _, ok := exclude[string(addr.Bytes())]; !ok {
+			out = append(out, addr)
+		}
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package peer_test
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	. "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestPeerRecordDelta(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+
+	a := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	b := ma.StringCast("/ip4/1.2.3.4/udp/1/quic")
+	c := ma.StringCast("/ip6/::1/tcp/1")
+	base := &PeerRecord{PeerID: id, Seq: 1, Addrs: []ma.Multiaddr{a, b}}
+	updated := &PeerRecord{PeerID: id, Seq: 2, Addrs: []ma.Multiaddr{b, c}}
+
+	delta, err := DiffPeerRecords(base, updated)
+	test.AssertNilError(t, err)
+	if len(delta.Added) != 1 || !delta.Added[0].Equal(c) || len(delta.Removed) != 1 || !delta.Removed[0].Equal(a) {
+		t.Fatalf("unexpected delta: added %v, removed %v", delta.Added, delta.Removed)
+	}
+
+	envelope, err := record.Seal(delta, priv)
+	test.AssertNilError(t, err)
+	envBytes, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	_, untypedRecord, err := record.ConsumeEnvelope(envBytes, PeerRecordDeltaEnvelopeDomain)
+	test.AssertNilError(t, err)
+	delta2, ok := untypedRecord.(*PeerRecordDelta)
+	if !ok {
+		t.Fatal("unmarshaled record is not a *PeerRecordDelta")
+	}
+	test.AssertNilError(t, delta2.VerifySigner(envelope))
+
+	// another key can't sign deltas of the peer
+	otherPriv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	forged, err := record.Seal(delta, otherPriv)
+	test.AssertNilError(t, err)
+	if err := delta.VerifySigner(forged); err != ErrSignerMismatch {
+		t.Fatalf("expected ErrSignerMismatch, got %v", err)
+	}
+
+	applied, err := delta2.Apply(base)
+	test.AssertNilError(t, err)
+	if !applied.Equal(updated) {
+		t.Fatalf("expected %v, got %v", updated, applied)
+	}
+
+	if _, err := delta2.Apply(updated); err != ErrDeltaBaseMismatch {
+		t.Fatalf("expected ErrDeltaBaseMismatch, got %v", err)
+	}
+	if _, err := DiffPeerRecords(updated, base); err == nil {
+		t.Fatal("expected diffing against an older record to fail")
+	}
+}
+
+// sameAddrSet returns true if a and b contain the same addresses, ignoring
+// order and duplicates.
+func sameAddrSet(a, b []ma.Multiaddr) bool {
+	set := func(addrs []ma.Multiaddr) map[string]struct{} {
+		s := make(map[string]struct{}, len(addrs))
+		for _, addr := range addrs {
+			s[string(addr.Bytes())] = struct{}{}
+		}
+		return s
+	}
+	sa, sb := set(a), set(b)
+	if len(sa) != len(sb) {
+		return false
+	}
+	for k := range sa {
+		if _, ok := sb[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPeerRecordDeltaAddrSet(t *testing.T) {
+	id, err := test.RandPeerID()
+	test.AssertNilError(t, err)
+
+	a := ma.StringCast("/ip4/1.2.3.4/tcp/1")
+	b := ma.StringCast("/ip4/1.2.3.4/udp/1/quic")
+	c := ma.StringCast("/ip6/::1/tcp/1")
+	d := ma.StringCast("/ip6/::1/udp/1/quic")
+
+	for _, tc := range []struct {
+		name          string
+		base, updated []ma.Multiaddr
+		empty         bool
+	}{
+		{"reordered", []ma.Multiaddr{a, b, c}, []ma.Multiaddr{c, a, b}, true},
+		{"added in front", []ma.Multiaddr{a, b}, []ma.Multiaddr{c, a, b}, false},
+		{"replaced and reordered", []ma.Multiaddr{a, b, c}, []ma.Multiaddr{d, c, a}, false},
+		{"duplicates", []ma.Multiaddr{a, b}, []ma.Multiaddr{b, a, b, c}, false},
+		{"all removed", []ma.Multiaddr{a, b}, nil, false},
+		{"from empty", nil, []ma.Multiaddr{b, a}, false},
+	} {
+		base := &PeerRecord{PeerID: id, Seq: 1, Addrs: tc.base}
+		updated := &PeerRecord{PeerID: id, Seq: 2, Addrs: tc.updated}
+		delta, err := DiffPeerRecords(base, updated)
+		test.AssertNilError(t, err)
+		if empty := len(delta.Added) == 0 && len(delta.Removed) == 0; empty != tc.empty {
+			t.Errorf("%s: unexpected delta: added %v, removed %v", tc.name, delta.Added, delta.Removed)
+		}
+		applied, err := delta.Apply(base)
+		test.AssertNilError(t, err)
+		if applied.Seq != updated.Seq || !sameAddrSet(applied.Addrs, updated.Addrs) {
+			t.Errorf("%s: expected the addresses %v, got %v", tc.name, updated.Addrs, applied.Addrs)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package peer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+
+	b58 "github.com/mr-tron/base58/base58"
+	mc "github.com/multiformats/go-multicodec"
+	"github.com/multiformats/go-varint"
+)
+
+// DIDKeyPrefix is the prefix of did:key decentralized identifiers.
+const DIDKeyPrefix = "did:key:"
+
+// ErrInvalidDID is returned when parsing a malformed or unsupported did:key
+// identifier.
+var ErrInvalidDID = errors.New("invalid did:key identifier")
+
+// ToDID returns the did:key decentralized identifier of the peer, as defined in
+// https://w3c-ccg.github.io/did-method-key/.
+//
+// Only peer IDs that embed their public key (i.e. Ed25519 and Secp256k1 keys by
+// default) can be converted; for other peer IDs, use DIDFromPublicKey with the
+// peer's public key.
+func (id ID) ToDID() (string, error) {
+	pk, err := id.ExtractPublicKey()
+	if err != nil {
+		return "", err
+	}
+	return DIDFromPublicKey(pk)
+}
+
+// FromDID returns the peer ID of the public key contained in a did:key
+// identifier.
+func FromDID(did string) (ID, error) {
+	pk, err := PublicKeyFromDID(did)
+	if err != nil {
+		return "", err
+	}
+	return IDFromPublicKey(pk)
+}
+
+// DIDFromPublicKey returns the did:key identifier of the given public key.
+// Ed25519, Secp256k1 and ECDSA (P-256, P-384 and P-521) keys are supported.
+func DIDFromPublicKey(pk ic.PubKey) (string, error) {
+	var code mc.Code
+	var raw []byte
+	switch pk.Type() {
+	case pb.KeyType_Ed25519:
+		code = mc.Ed25519Pub
+	case pb.KeyType_Secp256k1:
+		code = mc.Secp256k1Pub
+	case pb.KeyType_ECDSA:
+		der, err := pk.Raw()
+		if err != nil {
+			return "", err
+		}
+		stdKey, err := x509.ParsePKIXPublicKey(der)
+		if err != nil {
+			return "", err
+		}
+		ecKey, ok := stdKey.(*ecdsa.PublicKey)
+		if !ok {
+			return "", ic.ErrBadKeyType
+		}
+		if code, ok = curveCodes[ecKey.Curve]; !ok {
+			return "", fmt.Errorf("unsupported curve %s", ecKey.Curve.Params().Name)
+		}
+		raw = elliptic.MarshalCompressed(ecKey.Curve, ecKey.X, ecKey.Y)
+	default:
+		return "", ic.ErrBadKeyType
+	}
+	if raw == nil {
+		// Ed25519 and Secp256k1 keys are already in their did:key
+		// representation (compressed for Secp256k1).
+		var err error
+		if raw, err = pk.Raw(); err != nil {
+			return "", err
+		}
+	}
+	buf := append(varint.ToUvarint(uint64(code)), raw...)
+	return DIDKeyPrefix + "z" + b58.Encode(buf), nil
+}
+
+// PublicKeyFromDID parses a did:key identifier and returns the public key it
+// contains.
+func PublicKeyFromDID(did string) (ic.PubKey, error) {
+	if !strings.HasPrefix(did, DIDKeyPrefix+"z") {
+		return nil, ErrInvalidDID
+	}
+	buf, err := b58.Decode(did[len(DIDKeyPrefix)+1:])
+	if err != nil {
+		return nil, ErrInvalidDID
+	}
+	code, n, err := varint.FromUvarint(buf)
+	if err != nil {
+		return nil, ErrInvalidDID
+	}
+	raw := buf[n:]
+	switch mc.Code(code) {
+	case mc.Ed25519Pub:
+		return ic.UnmarshalEd25519PublicKey(raw)
+	case mc.Secp256k1Pub:
+		return ic.UnmarshalSecp256k1PublicKey(raw)
+	}
+	for curve, c := range curveCodes {
+		if c != mc.Code(code) {
+			continue
+		}
+		x, y := elliptic.UnmarshalCompressed(curve, raw)
+		if x == nil {
+			return nil, ErrInvalidDID
+		}
+		return ic.ECDSAPublicKeyFromPubKey(ecdsa.PublicKey{Curve: curve, X: x, Y: y})
+	}
+	return nil, fmt.Errorf("%w: unsupported key type %#x", ErrInvalidDID, code)
+}
+
+var curveCodes = map[elliptic.Curve]mc.Code{
+	elliptic.P256(): mc.P256Pub,
+	elliptic.P384(): mc.P384Pub,
+	elliptic.P521(): mc.P521Pub,
+}
@@ -0,0 +1,97 @@
+package peer_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	. "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestDIDKeyVector(t *testing.T) {
+	// Test vector from the did:key specification.
+	const did = "did:key:z6MkiTBz1ymuepAQ4HEHYSF1H8quG5GLVVQR3djdX3mDooWp"
+	const rawHex = "3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29"
+
+	pk, err := PublicKeyFromDID(did)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := pk.Raw()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(raw) != rawHex {
+		t.Fatalf("unexpected key %x", raw)
+	}
+	stdPk, err := crypto.UnmarshalEd25519PublicKey(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := DIDFromPublicKey(stdPk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != did {
+		t.Fatalf("expected %s, got %s", did, out)
+	}
+}
+
+func TestDIDRoundTrip(t *testing.T) {
+	for _, typ := range []int{crypto.Ed25519, crypto.Secp256k1, crypto.ECDSA} {
+		_, pub, err := test.RandTestKeyPair(typ, 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		did, err := DIDFromPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub2, err := PublicKeyFromDID(did)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pub.Equals(pub2) {
+			t.Fatalf("key type %d: keys differ after round trip", typ)
+		}
+
+		id, err := IDFromPublicKey(pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id2, err := FromDID(did)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != id2 {
+			t.Fatalf("key type %d: expected %s, got %s", typ, id, id2)
+		}
+	}
+}
+
+func TestToDID(t *testing.T) {
+	_, pub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did, err := id.ToDID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id2, err := FromDID(did)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != id2 {
+		t.Fatalf("expected %s, got %s", id, id2)
+	}
+
+	if _, err := PublicKeyFromDID("did:web:example.com"); err != ErrInvalidDID {
+		t.Fatalf("expected ErrInvalidDID, got %v", err)
+	}
+}
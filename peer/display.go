@@ -0,0 +1,151 @@
+package peer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidShortID is returned when parsing a malformed short peer ID.
+var ErrInvalidShortID = errors.New("invalid short peer ID")
+
+const (
+	shortIDSeparator         = ".."
+	shortIDChecksumSeparator = "#"
+
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+)
+
+// DisplayOptions configures the short form of peer IDs produced by
+// ID.Display.
+type DisplayOptions struct {
+	// Prefix is the number of leading characters of the encoded ID to keep.
+	Prefix int
+
+	// Suffix is the number of trailing characters of the encoded ID to keep.
+	Suffix int
+
+	// ChecksumBytes is the number of bytes of the ID's SHA-256 digest
+	// appended to the short form, in hex.
+	ChecksumBytes int
+
+	// Color enables ANSI terminal colors for the checksum, making it easier
+	// to tell similar-looking IDs apart. Colored short IDs can't be parsed.
+	Color bool
+}
+
+// DefaultDisplayOptions are the options used by ID.SecureShortString.
+var DefaultDisplayOptions = DisplayOptions{Prefix: 4, Suffix: 6, ChecksumBytes: 2}
+
+// ShortID is the short, checksum-bearing form of a peer ID, as produced by
+// ID.Display.
+//
+// Unlike ID.ShortString, which only keeps a few characters of the encoded ID
+// and is easy to spoof by grinding keys with the same prefix and suffix, the
+// short form includes a checksum of the entire ID.
+type ShortID struct {
+	Prefix   string
+	Suffix   string
+	Checksum []byte
+}
+
+// SecureShortString returns the short form of the peer ID, using the
+// DefaultDisplayOptions. It is suitable for logging and user interfaces.
+func (id ID) SecureShortString() string {
+	return id.Display(DefaultDisplayOptions)
+}
+
+// Display returns the short form of the peer ID, in the format
+// <prefix>..<suffix>#<checksum>. If the encoded ID is too short to be
+// truncated, the full ID is used instead of the prefix and suffix.
+func (id ID) Display(opts DisplayOptions) string {
+	s := id.ShortID(opts)
+	if !opts.Color {
+		return s.String()
+	}
+	var color byte
+	if len(s.Checksum) > 0 {
+		// Use the 216 colors of the 6x6x6 color cube.
+		color = 16 + s.Checksum[0]%216
+	}
+	return fmt.Sprintf("%s%s%s%s\x1b[38;5;%dm%x\x1b[0m",
+		s.Prefix, shortIDSeparator, s.Suffix, shortIDChecksumSeparator, color, s.Checksum)
+}
+
+// ShortID returns the short form of the peer ID. It is based on the base58
+// encoding of the ID, regardless of DefaultEncoding.
+func (id ID) ShortID(opts DisplayOptions) *ShortID {
+	pid := Encode(id, EncodingBase58)
+	sum := sha256.Sum256([]byte(id))
+	n := opts.ChecksumBytes
+	if n > len(sum) {
+		n = len(sum)
+	} else if n < 0 {
+		n = 0
+	}
+	s := &ShortID{Checksum: sum[:n]}
+	if opts.Prefix < 0 || opts.Suffix < 0 || opts.Prefix+opts.Suffix >= len(pid) {
+		s.Prefix = pid
+		return s
+	}
+	s.Prefix = pid[:opts.Prefix]
+	s.Suffix = pid[len(pid)-opts.Suffix:]
+	return s
+}
+
+// ParseShortID parses the uncolored short form of a peer ID, as produced by
+// ID.Display. The parser is strict: the prefix and suffix must be valid
+// base58, and the checksum must be present and in lowercase hex.
+func ParseShortID(s string) (*ShortID, error) {
+	rest, sum, ok := cut(s, shortIDChecksumSeparator)
+	if !ok || len(sum) == 0 || len(sum)%2 != 0 || strings.ToLower(sum) != sum {
+		return nil, ErrInvalidShortID
+	}
+	checksum, err := hex.DecodeString(sum)
+	if err != nil {
+		return nil, ErrInvalidShortID
+	}
+	prefix, suffix, _ := cut(rest, shortIDSeparator)
+	if len(prefix) == 0 || !isBase58(prefix) || !isBase58(suffix) {
+		return nil, ErrInvalidShortID
+	}
+	return &ShortID{Prefix: prefix, Suffix: suffix, Checksum: checksum}, nil
+}
+
+// Matches returns true if the given peer ID has this short form.
+func (s *ShortID) Matches(id ID) bool {
+	pid := Encode(id, EncodingBase58)
+	if len(s.Prefix)+len(s.Suffix) > len(pid) ||
+		!strings.HasPrefix(pid, s.Prefix) || !strings.HasSuffix(pid, s.Suffix) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(id))
+	return len(s.Checksum) <= len(sum) && bytes.Equal(sum[:len(s.Checksum)], s.Checksum)
+}
+
+func (s *ShortID) String() string {
+	if s.Suffix == "" {
+		return fmt.Sprintf("%s%s%x", s.Prefix, shortIDChecksumSeparator, s.Checksum)
+	}
+	return fmt.Sprintf("%s%s%s%s%x", s.Prefix, shortIDSeparator, s.Suffix, shortIDChecksumSeparator, s.Checksum)
+}
+
+func isBase58(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune(base58Alphabet, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// cut slices s around the last instance of sep.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
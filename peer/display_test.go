@@ -0,0 +1,58 @@
+package peer_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestSecureShortString(t *testing.T) {
+	s := testID.SecureShortString()
+	pid := testID.Pretty()
+	if !strings.HasPrefix(s, pid[:4]+".."+pid[len(pid)-6:]+"#") || len(s) != 4+2+6+1+4 {
+		t.Fatalf("unexpected short form %q", s)
+	}
+
+	short, err := ParseShortID(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !short.Matches(testID) {
+		t.Fatal("expected short form to match its ID")
+	}
+	if short.String() != s {
+		t.Fatalf("expected %q, got %q", s, short.String())
+	}
+
+	// Same prefix and suffix, different checksum.
+	spoofed := *short
+	spoofed.Checksum = []byte{short.Checksum[0] ^ 0xff, short.Checksum[1]}
+	if spoofed.Matches(testID) {
+		t.Fatal("expected a different checksum not to match")
+	}
+
+	colored := testID.Display(DisplayOptions{Prefix: 4, Suffix: 6, ChecksumBytes: 2, Color: true})
+	if !strings.Contains(colored, "\x1b[") {
+		t.Fatalf("expected colored output, got %q", colored)
+	}
+	if _, err := ParseShortID(colored); err == nil {
+		t.Fatal("expected colored short form to be rejected")
+	}
+}
+
+func TestParseShortIDStrict(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"QmS3..K5Va",
+		"QmS3..K5Va#",
+		"QmS3..K5Va#abc",
+		"QmS3..K5Va#ABCD",
+		"Qm0l..K5Va#abcd",
+		"..K5Va#abcd",
+	} {
+		if _, err := ParseShortID(s); err != ErrInvalidShortID {
+			t.Errorf("expected %q to be rejected, got %v", s, err)
+		}
+	}
+}
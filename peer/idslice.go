@@ -0,0 +1,93 @@
+package peer
+
+import (
+	"crypto/sha256"
+	"math/rand"
+	"sort"
+
+	"github.com/multiformats/go-varint"
+)
+
+// Sort sorts the IDs in place, in the order defined by Less.
+func (es IDSlice) Sort() {
+	sort.Sort(es)
+}
+
+// Contains returns true if p is in the slice.
+func (es IDSlice) Contains(p ID) bool {
+	for _, id := range es {
+		if id == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Unique returns the IDs of the slice without duplicates, in the order of
+// their first occurrence.
+func (es IDSlice) Unique() IDSlice {
+	seen := make(map[ID]struct{}, len(es))
+	out := make(IDSlice, 0, len(es))
+	for _, id := range es {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// Diff returns the IDs of the slice that are not in other, in order.
+func (es IDSlice) Diff(other IDSlice) IDSlice {
+	exclude := make(map[ID]struct{}, len(other))
+	for _, id := range other {
+		exclude[id] = struct{}{}
+	}
+	var out IDSlice
+	for _, id := range es {
+		if _, ok := exclude[id]; !ok {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// Sample returns n IDs picked uniformly at random from the slice, without
+// replacement, using rng or the math/rand default source if rng is nil. All
+// the IDs are returned, shuffled, if there are less than n. The slice itself
+// is left untouched.
+func (es IDSlice) Sample(n int, rng *rand.Rand) IDSlice {
+	if n > len(es) {
+		n = len(es)
+	}
+	if n <= 0 {
+		return nil
+	}
+	intn := rand.Intn
+	if rng != nil {
+		intn = rng.Intn
+	}
+	tmp := make(IDSlice, len(es))
+	copy(tmp, es)
+	// partial Fisher-Yates shuffle
+	for i := 0; i < n; i++ {
+		j := i + intn(len(tmp)-i)
+		tmp[i], tmp[j] = tmp[j], tmp[i]
+	}
+	return tmp[:n]
+}
+
+// Hash returns a SHA-256 digest of the set of IDs in the slice. It doesn't
+// depend on the order of the IDs or on duplicates, so that two nodes can
+// cheaply check whether they agree on a set of peers.
+func (es IDSlice) Hash() []byte {
+	ids := es.Unique()
+	ids.Sort()
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write(varint.ToUvarint(uint64(len(id))))
+		h.Write([]byte(id))
+	}
+	return h.Sum(nil)
+}
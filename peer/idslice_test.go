@@ -0,0 +1,69 @@
+package peer_test
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestIDSlice(t *testing.T) {
+	a, b, c, d := ID("a"), ID("b"), ID("c"), ID("d")
+	ids := IDSlice{c, a, b, a}
+
+	if !ids.Contains(b) || ids.Contains(d) {
+		t.Fatal("Contains returned the wrong result")
+	}
+	if u := ids.Unique(); len(u) != 3 || u[0] != c || u[1] != a || u[2] != b {
+		t.Fatalf("unexpected Unique result: %v", u)
+	}
+	if diff := ids.Diff(IDSlice{a, d}); len(diff) != 2 || diff[0] != c || diff[1] != b {
+		t.Fatalf("unexpected Diff result: %v", diff)
+	}
+
+	sorted := IDSlice{c, a, b}
+	sorted.Sort()
+	if !sort.IsSorted(sorted) {
+		t.Fatalf("expected sorted slice, got %v", sorted)
+	}
+
+	if !bytes.Equal(ids.Hash(), IDSlice{a, b, c}.Hash()) {
+		t.Fatal("hash depends on order or duplicates")
+	}
+	if bytes.Equal(ids.Hash(), IDSlice{a, b}.Hash()) {
+		t.Fatal("different sets have the same hash")
+	}
+	// IDs are length-prefixed, so concatenations don't collide
+	if bytes.Equal(IDSlice{"ab", "c"}.Hash(), IDSlice{"a", "bc"}.Hash()) {
+		t.Fatal("different sets have the same hash")
+	}
+}
+
+func TestIDSliceSample(t *testing.T) {
+	ids := IDSlice{"a", "b", "c", "d", "e"}
+	orig := append(IDSlice(nil), ids...)
+	rng := rand.New(rand.NewSource(1))
+
+	sample := ids.Sample(3, rng)
+	if len(sample) != 3 || len(sample.Unique()) != 3 {
+		t.Fatalf("expected 3 distinct IDs, got %v", sample)
+	}
+	for _, id := range sample {
+		if !ids.Contains(id) {
+			t.Fatalf("sampled ID %s not in slice", id)
+		}
+	}
+	for i := range ids {
+		if ids[i] != orig[i] {
+			t.Fatal("Sample modified the slice")
+		}
+	}
+	if all := ids.Sample(10, nil); len(all) != len(ids) {
+		t.Fatalf("expected all %d IDs, got %v", len(ids), all)
+	}
+	if none := ids.Sample(0, rng); len(none) != 0 {
+		t.Fatalf("expected no IDs, got %v", none)
+	}
+}
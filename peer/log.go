@@ -0,0 +1,34 @@
+package peer
+
+// Structured logging field names used by ID.LogFields, ID.LogKeyvals and
+// ID.LogValue.
+const (
+	// LogFieldShort is the name of the field holding the short form of a
+	// peer ID, see ID.SecureShortString.
+	LogFieldShort = "peer"
+	// LogFieldID is the name of the field holding the full peer ID, as in
+	// ID.Loggable.
+	LogFieldID = "peerID"
+)
+
+// LogFields returns the peer ID as structured logging fields: its short form,
+// for humans, and its full form, for searching logs. The fields can be used
+// with logrus, as logrus.Fields(id.LogFields()).
+func (id ID) LogFields() map[string]interface{} {
+	return map[string]interface{}{
+		LogFieldShort: id.SecureShortString(),
+		LogFieldID:    id.String(),
+	}
+}
+
+// LogKeyvals returns the fields of LogFields as alternating keys and values,
+// for loggers that take them as variadic arguments, e.g. zap's
+// SugaredLogger.Infow or slog's Logger.Info:
+//
+//	logger.Infow("connected", id.LogKeyvals()...)
+func (id ID) LogKeyvals() []interface{} {
+	return []interface{}{
+		LogFieldShort, id.SecureShortString(),
+		LogFieldID, id.String(),
+	}
+}
@@ -0,0 +1,16 @@
+//go:build go1.21
+
+package peer
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer, rendering the peer ID as a group of the
+// fields of LogFields.
+func (id ID) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String(LogFieldShort, id.SecureShortString()),
+		slog.String(LogFieldID, id.String()),
+	)
+}
+
+var _ slog.LogValuer = ID("")
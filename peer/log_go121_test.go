@@ -0,0 +1,31 @@
+//go:build go1.21
+
+package peer_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestLogValue(t *testing.T) {
+	id, err := Decode(gen1.hpkp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("connected", "remote", id)
+
+	var entry struct {
+		Remote map[string]string `json:"remote"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Remote[LogFieldShort] != id.SecureShortString() || entry.Remote[LogFieldID] != id.String() {
+		t.Fatalf("unexpected log entry %s", buf.String())
+	}
+}
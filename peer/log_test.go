@@ -0,0 +1,27 @@
+package peer_test
+
+import (
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestLogFields(t *testing.T) {
+	id, err := Decode(gen1.hpkp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := id.LogFields()
+	if fields[LogFieldShort] != id.SecureShortString() || fields[LogFieldID] != id.String() {
+		t.Fatalf("unexpected log fields %v", fields)
+	}
+	kv := id.LogKeyvals()
+	if len(kv) != 2*len(fields) {
+		t.Fatalf("expected %d keyvals, got %d", 2*len(fields), len(kv))
+	}
+	for i := 0; i < len(kv); i += 2 {
+		if fields[kv[i].(string)] != kv[i+1] {
+			t.Fatalf("keyvals %v don't match fields %v", kv, fields)
+		}
+	}
+}
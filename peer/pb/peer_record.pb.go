@@ -37,6 +37,8 @@ type PeerRecord struct {
 	Seq uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
 	// addresses is a list of public listen addresses for the peer.
 	Addresses []*PeerRecord_AddressInfo `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	// protocols is an optional list of the protocol IDs the peer supports.
+	Protocols []string `protobuf:"bytes,4,rep,name=protocols,proto3" json:"protocols,omitempty"`
 }
 
 func (m *PeerRecord) Reset()         { *m = PeerRecord{} }
@@ -93,6 +95,13 @@ func (m *PeerRecord) GetAddresses() []*PeerRecord_AddressInfo {
 	return nil
 }
 
+func (m *PeerRecord) GetProtocols() []string {
+	if m != nil {
+		return m.Protocols
+	}
+	return nil
+}
+
 // AddressInfo is a wrapper around a binary multiaddr. It is defined as a
 // separate message to allow us to add per-address metadata in the future.
 type PeerRecord_AddressInfo struct {
@@ -139,27 +148,183 @@ func (m *PeerRecord_AddressInfo) GetMultiaddr() []byte {
 	return nil
 }
 
+// PeerRecordRevocation messages are published by a peer to invalidate
+// PeerRecords it has previously signed, e.g. after a key compromise.
+//
+// Like PeerRecords, revocations are designed to be serialized to bytes and
+// placed inside of SignedEnvelopes before sharing with other peers.
+type PeerRecordRevocation struct {
+	// peer_id contains a libp2p peer id in its binary representation.
+	PeerId []byte `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	// seq is the highest PeerRecord sequence number being revoked. All
+	// PeerRecords for peer_id with a seq <= this value are invalid.
+	Seq uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+}
+
+func (m *PeerRecordRevocation) Reset()         { *m = PeerRecordRevocation{} }
+func (m *PeerRecordRevocation) String() string { return proto.CompactTextString(m) }
+func (*PeerRecordRevocation) ProtoMessage()    {}
+func (*PeerRecordRevocation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dc0d8059ab0ad14d, []int{1}
+}
+func (m *PeerRecordRevocation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PeerRecordRevocation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PeerRecordRevocation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PeerRecordRevocation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerRecordRevocation.Merge(m, src)
+}
+func (m *PeerRecordRevocation) XXX_Size() int {
+	return m.Size()
+}
+func (m *PeerRecordRevocation) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerRecordRevocation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerRecordRevocation proto.InternalMessageInfo
+
+func (m *PeerRecordRevocation) GetPeerId() []byte {
+	if m != nil {
+		return m.PeerId
+	}
+	return nil
+}
+
+func (m *PeerRecordRevocation) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+// PeerRecordDelta messages describe the changes between two PeerRecords of the
+// same peer, so that peers with frequently changing addresses can publish
+// updates without re-sending their full address list.
+//
+// Like PeerRecords, deltas are designed to be serialized to bytes and placed
+// inside of SignedEnvelopes before sharing with other peers.
+type PeerRecordDelta struct {
+	// peer_id contains a libp2p peer id in its binary representation.
+	PeerId []byte `protobuf:"bytes,1,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	// base_seq is the seq of the PeerRecord the delta applies to.
+	BaseSeq uint64 `protobuf:"varint,2,opt,name=base_seq,json=baseSeq,proto3" json:"base_seq,omitempty"`
+	// seq is the seq of the PeerRecord resulting from applying the delta.
+	Seq uint64 `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
+	// added is a list of addresses to add to the base record.
+	Added []*PeerRecord_AddressInfo `protobuf:"bytes,4,rep,name=added,proto3" json:"added,omitempty"`
+	// removed is a list of addresses to remove from the base record.
+	Removed []*PeerRecord_AddressInfo `protobuf:"bytes,5,rep,name=removed,proto3" json:"removed,omitempty"`
+}
+
+func (m *PeerRecordDelta) Reset()         { *m = PeerRecordDelta{} }
+func (m *PeerRecordDelta) String() string { return proto.CompactTextString(m) }
+func (*PeerRecordDelta) ProtoMessage()    {}
+func (*PeerRecordDelta) Descriptor() ([]byte, []int) {
+	return fileDescriptor_dc0d8059ab0ad14d, []int{2}
+}
+func (m *PeerRecordDelta) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PeerRecordDelta) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PeerRecordDelta.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PeerRecordDelta) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerRecordDelta.Merge(m, src)
+}
+func (m *PeerRecordDelta) XXX_Size() int {
+	return m.Size()
+}
+func (m *PeerRecordDelta) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerRecordDelta.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerRecordDelta proto.InternalMessageInfo
+
+func (m *PeerRecordDelta) GetPeerId() []byte {
+	if m != nil {
+		return m.PeerId
+	}
+	return nil
+}
+
+func (m *PeerRecordDelta) GetBaseSeq() uint64 {
+	if m != nil {
+		return m.BaseSeq
+	}
+	return 0
+}
+
+func (m *PeerRecordDelta) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *PeerRecordDelta) GetAdded() []*PeerRecord_AddressInfo {
+	if m != nil {
+		return m.Added
+	}
+	return nil
+}
+
+func (m *PeerRecordDelta) GetRemoved() []*PeerRecord_AddressInfo {
+	if m != nil {
+		return m.Removed
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*PeerRecord)(nil), "peer.pb.PeerRecord")
 	proto.RegisterType((*PeerRecord_AddressInfo)(nil), "peer.pb.PeerRecord.AddressInfo")
+	proto.RegisterType((*PeerRecordRevocation)(nil), "peer.pb.PeerRecordRevocation")
+	proto.RegisterType((*PeerRecordDelta)(nil), "peer.pb.PeerRecordDelta")
 }
 
 func init() { proto.RegisterFile("peer_record.proto", fileDescriptor_dc0d8059ab0ad14d) }
 
 var fileDescriptor_dc0d8059ab0ad14d = []byte{
-	// 189 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2c, 0x48, 0x4d, 0x2d,
-	0x8a, 0x2f, 0x4a, 0x4d, 0xce, 0x2f, 0x4a, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x07,
-	0x09, 0xe9, 0x15, 0x24, 0x29, 0x2d, 0x66, 0xe4, 0xe2, 0x0a, 0x48, 0x4d, 0x2d, 0x0a, 0x02, 0xcb,
-	0x0a, 0x89, 0x73, 0x81, 0x65, 0xe2, 0x33, 0x53, 0x24, 0x18, 0x15, 0x18, 0x35, 0x78, 0x82, 0xd8,
-	0x40, 0x5c, 0xcf, 0x14, 0x21, 0x01, 0x2e, 0xe6, 0xe2, 0xd4, 0x42, 0x09, 0x26, 0x05, 0x46, 0x0d,
-	0x96, 0x20, 0x10, 0x53, 0xc8, 0x96, 0x8b, 0x33, 0x31, 0x25, 0xa5, 0x28, 0xb5, 0xb8, 0x38, 0xb5,
-	0x58, 0x82, 0x59, 0x81, 0x59, 0x83, 0xdb, 0x48, 0x5e, 0x0f, 0x6a, 0xac, 0x1e, 0xc2, 0x48, 0x3d,
-	0x47, 0x88, 0x22, 0xcf, 0xbc, 0xb4, 0xfc, 0x20, 0x84, 0x0e, 0x29, 0x6d, 0x2e, 0x6e, 0x24, 0x19,
-	0x21, 0x19, 0x2e, 0xce, 0xdc, 0xd2, 0x9c, 0x92, 0x4c, 0x90, 0x02, 0xa8, 0xd5, 0x08, 0x01, 0x27,
-	0x89, 0x13, 0x8f, 0xe4, 0x18, 0x2f, 0x3c, 0x92, 0x63, 0x7c, 0xf0, 0x48, 0x8e, 0x71, 0xc2, 0x63,
-	0x39, 0x86, 0x0b, 0x8f, 0xe5, 0x18, 0x6e, 0x3c, 0x96, 0x63, 0x48, 0x62, 0x03, 0xfb, 0xc7, 0x18,
-	0x10, 0x00, 0x00, 0xff, 0xff, 0xcb, 0x99, 0x56, 0x19, 0xe4, 0x00, 0x00, 0x00,
+	// 289 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x90, 0xcd, 0x4a, 0xc3, 0x40,
+	0x14, 0x85, 0x3b, 0xa6, 0x3f, 0xe6, 0x56, 0x50, 0x07, 0xc1, 0x51, 0x64, 0x0c, 0x59, 0x05, 0x84,
+	0x2c, 0x14, 0x17, 0x2e, 0x5c, 0x54, 0xdc, 0x74, 0x27, 0xe3, 0x03, 0x84, 0x24, 0x73, 0x85, 0x40,
+	0xda, 0x49, 0x67, 0x62, 0x9f, 0xc3, 0x57, 0x72, 0xa7, 0xbb, 0x2e, 0x5d, 0x4a, 0xf2, 0x22, 0x32,
+	0x69, 0xcb, 0xb8, 0x11, 0xea, 0x2e, 0xf7, 0xdc, 0x73, 0xbe, 0xdc, 0x39, 0x70, 0x5c, 0x21, 0xea,
+	0x44, 0x63, 0xae, 0xb4, 0x8c, 0x2b, 0xad, 0x6a, 0x45, 0x47, 0x56, 0x8a, 0xab, 0x2c, 0x7c, 0x27,
+	0x00, 0x4f, 0x88, 0x5a, 0x74, 0x5b, 0x7a, 0x0a, 0xdd, 0x26, 0x29, 0x24, 0x23, 0x01, 0x89, 0x0e,
+	0xc4, 0xd0, 0x8e, 0x53, 0x49, 0x8f, 0xc0, 0x33, 0xb8, 0x60, 0x7b, 0x01, 0x89, 0xfa, 0xc2, 0x7e,
+	0xd2, 0x7b, 0xf0, 0x53, 0x29, 0x35, 0x1a, 0x83, 0x86, 0x79, 0x81, 0x17, 0x8d, 0xaf, 0x2f, 0xe3,
+	0x0d, 0x36, 0x76, 0xc8, 0x78, 0xb2, 0x36, 0x4d, 0xe7, 0x2f, 0x4a, 0xb8, 0x04, 0xbd, 0x00, 0xbf,
+	0x3b, 0x25, 0x57, 0xa5, 0x61, 0xfd, 0xc0, 0x8b, 0x7c, 0xe1, 0x84, 0xf3, 0x2b, 0x18, 0xff, 0xca,
+	0x59, 0xf3, 0xec, 0xb5, 0xac, 0x0b, 0x1b, 0xdf, 0x1c, 0xe6, 0x84, 0x70, 0x02, 0x27, 0xee, 0x7f,
+	0x02, 0x97, 0x2a, 0x4f, 0xeb, 0x42, 0xcd, 0xff, 0xf1, 0x98, 0xf0, 0x93, 0xc0, 0xa1, 0x63, 0x3c,
+	0x62, 0x59, 0xa7, 0x7f, 0xc7, 0xcf, 0x60, 0x3f, 0x4b, 0x0d, 0x26, 0x8e, 0x31, 0xb2, 0xf3, 0x33,
+	0x2e, 0xb6, 0x64, 0xcf, 0xd5, 0x74, 0x0b, 0x83, 0x54, 0x4a, 0x94, 0xac, 0xbf, 0x5b, 0x45, 0x6b,
+	0x37, 0xbd, 0x83, 0x91, 0xc6, 0x99, 0x5a, 0xa2, 0x64, 0x83, 0xdd, 0x82, 0x5b, 0xff, 0x03, 0xfb,
+	0x68, 0x38, 0x59, 0x35, 0x9c, 0x7c, 0x37, 0x9c, 0xbc, 0xb5, 0xbc, 0xb7, 0x6a, 0x79, 0xef, 0xab,
+	0xe5, 0xbd, 0x6c, 0xd8, 0x15, 0x7c, 0xf3, 0x33, 0x00, 0x06, 0xb5, 0xe7, 0xb5, 0x11, 0x02, 0x00,
+	0x00,
 }
 
 func (m *PeerRecord) Marshal() (dAtA []byte, err error) {
@@ -182,6 +347,15 @@ func (m *PeerRecord) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Protocols) > 0 {
+		for iNdEx := len(m.Protocols) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Protocols[iNdEx])
+			copy(dAtA[i:], m.Protocols[iNdEx])
+			i = encodeVarintPeerRecord(dAtA, i, uint64(len(m.Protocols[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if len(m.Addresses) > 0 {
 		for iNdEx := len(m.Addresses) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -241,6 +415,109 @@ func (m *PeerRecord_AddressInfo) MarshalToSizedBuffer(dAtA []byte) (int, error)
 	return len(dAtA) - i, nil
 }
 
+func (m *PeerRecordRevocation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PeerRecordRevocation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PeerRecordRevocation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Seq != 0 {
+		i = encodeVarintPeerRecord(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.PeerId) > 0 {
+		i -= len(m.PeerId)
+		copy(dAtA[i:], m.PeerId)
+		i = encodeVarintPeerRecord(dAtA, i, uint64(len(m.PeerId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *PeerRecordDelta) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PeerRecordDelta) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PeerRecordDelta) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Removed) > 0 {
+		for iNdEx := len(m.Removed) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Removed[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintPeerRecord(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.Added) > 0 {
+		for iNdEx := len(m.Added) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Added[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintPeerRecord(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if m.Seq != 0 {
+		i = encodeVarintPeerRecord(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.BaseSeq != 0 {
+		i = encodeVarintPeerRecord(dAtA, i, uint64(m.BaseSeq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.PeerId) > 0 {
+		i -= len(m.PeerId)
+		copy(dAtA[i:], m.PeerId)
+		i = encodeVarintPeerRecord(dAtA, i, uint64(len(m.PeerId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintPeerRecord(dAtA []byte, offset int, v uint64) int {
 	offset -= sovPeerRecord(v)
 	base := offset
@@ -271,6 +548,12 @@ func (m *PeerRecord) Size() (n int) {
 			n += 1 + l + sovPeerRecord(uint64(l))
 		}
 	}
+	if len(m.Protocols) > 0 {
+		for _, s := range m.Protocols {
+			l = len(s)
+			n += 1 + l + sovPeerRecord(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -287,6 +570,53 @@ func (m *PeerRecord_AddressInfo) Size() (n int) {
 	return n
 }
 
+func (m *PeerRecordRevocation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.PeerId)
+	if l > 0 {
+		n += 1 + l + sovPeerRecord(uint64(l))
+	}
+	if m.Seq != 0 {
+		n += 1 + sovPeerRecord(uint64(m.Seq))
+	}
+	return n
+}
+
+func (m *PeerRecordDelta) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.PeerId)
+	if l > 0 {
+		n += 1 + l + sovPeerRecord(uint64(l))
+	}
+	if m.BaseSeq != 0 {
+		n += 1 + sovPeerRecord(uint64(m.BaseSeq))
+	}
+	if m.Seq != 0 {
+		n += 1 + sovPeerRecord(uint64(m.Seq))
+	}
+	if len(m.Added) > 0 {
+		for _, e := range m.Added {
+			l = e.Size()
+			n += 1 + l + sovPeerRecord(uint64(l))
+		}
+	}
+	if len(m.Removed) > 0 {
+		for _, e := range m.Removed {
+			l = e.Size()
+			n += 1 + l + sovPeerRecord(uint64(l))
+		}
+	}
+	return n
+}
+
 func sovPeerRecord(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -409,6 +739,38 @@ func (m *PeerRecord) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocols", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocols = append(m.Protocols, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPeerRecord(dAtA[iNdEx:])
@@ -520,6 +882,305 @@ func (m *PeerRecord_AddressInfo) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *PeerRecordRevocation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPeerRecord
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PeerRecordRevocation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PeerRecordRevocation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PeerId", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PeerId = append(m.PeerId[:0], dAtA[iNdEx:postIndex]...)
+			if m.PeerId == nil {
+				m.PeerId = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPeerRecord(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PeerRecordDelta) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPeerRecord
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PeerRecordDelta: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PeerRecordDelta: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PeerId", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PeerId = append(m.PeerId[:0], dAtA[iNdEx:postIndex]...)
+			if m.PeerId == nil {
+				m.PeerId = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BaseSeq", wireType)
+			}
+			m.BaseSeq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BaseSeq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Added", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Added = append(m.Added, &PeerRecord_AddressInfo{})
+			if err := m.Added[len(m.Added)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Removed", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerRecord
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Removed = append(m.Removed, &PeerRecord_AddressInfo{})
+			if err := m.Removed[len(m.Removed)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPeerRecord(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthPeerRecord
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipPeerRecord(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
@@ -41,7 +41,7 @@ const maxInlineKeyLength = 42
 // hash output as a multihash. See IDFromPublicKey for details.
 type ID string
 
-// Pretty returns a base58-encoded string representation of the ID.
+// Pretty returns a string representation of the ID, using DefaultEncoding.
 func (id ID) Pretty() string {
 	return Encode(id)
 }
@@ -135,7 +135,7 @@ func IDFromBytes(b []byte) (ID, error) {
 // valid.
 //
 // The encoded peer ID can either be a CID of a key or a raw multihash (identity
-// or sha256-256).
+// or sha256-256), so that IDs in any Encoding are accepted.
 func Decode(s string) (ID, error) {
 	if strings.HasPrefix(s, "Qm") || strings.HasPrefix(s, "1") {
 		// base58 encoded sha256 or identity multihash
@@ -153,11 +153,45 @@ func Decode(s string) (ID, error) {
 	return FromCid(c)
 }
 
-// Encode encodes a peer ID as a string.
+// Encoding is a textual encoding of peer IDs.
+type Encoding int
+
+const (
+	// EncodingBase58 encodes peer IDs as base58 multihashes (Qm... or
+	// 12D3KooW...), the legacy encoding.
+	EncodingBase58 Encoding = iota
+	// EncodingCIDv1 encodes peer IDs as base32 CIDv1s of the libp2p-key
+	// multicodec (bafz...). Unlike base58, the encoding is case-insensitive,
+	// so peer IDs can be used in DNS names, e.g. as subdomains.
+	EncodingCIDv1
+)
+
+// DefaultEncoding is the encoding used by Encode unless another is given,
+// and thus by ID.String and the JSON and text marshalling of IDs. Decode
+// accepts IDs in any encoding.
+//
+// This currently defaults to EncodingBase58 for backwards compatibility, but
+// will switch to EncodingCIDv1 in the future.
+var DefaultEncoding = EncodingBase58
+
+// Encode encodes a peer ID as a string, using the given encoding if any, and
+// DefaultEncoding otherwise:
+//
+//	s := peer.Encode(id, peer.EncodingCIDv1)
 //
-// At the moment, it base58 encodes the peer ID but, in the future, it will
-// switch to encoding it as a CID by default.
-func Encode(id ID) string {
+// Invalid peer IDs are always base58 encoded.
+func Encode(id ID, enc ...Encoding) string {
+	var e Encoding
+	if len(enc) > 0 {
+		e = enc[0]
+	} else {
+		e = DefaultEncoding
+	}
+	if e == EncodingCIDv1 {
+		if c := ToCid(id); c.Defined() {
+			return c.String()
+		}
+	}
 	return b58.Encode([]byte(id))
 }
 
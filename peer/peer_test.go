@@ -198,6 +198,44 @@ func TestIDEncoding(t *testing.T) {
 	}
 }
 
+func TestIDEncodingSelection(t *testing.T) {
+	p, err := Decode(gen1.hpkp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Encode(p, EncodingCIDv1)
+	if !strings.HasPrefix(s, "bafz") || strings.ToLower(s) != s {
+		t.Fatalf("expected a lowercase base32 CIDv1, got %s", s)
+	}
+	if s != ToCid(p).String() {
+		t.Fatal("CIDv1 encoding should match the CID string")
+	}
+	if Encode(p, EncodingBase58) != gen1.hpkp {
+		t.Fatal("base58 encoding should match the legacy encoding")
+	}
+	if p2, err := Decode(s); err != nil || p2 != p {
+		t.Fatal("failed to round trip through CIDv1 encoding:", err)
+	}
+
+	defer func(enc Encoding) { DefaultEncoding = enc }(DefaultEncoding)
+	DefaultEncoding = EncodingCIDv1
+	if p.String() != s || Encode(p) != s {
+		t.Fatal("should encode peer IDs with the default encoding")
+	}
+	text, err := p.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p3 ID
+	if err := p3.UnmarshalText(text); err != nil || p3 != p {
+		t.Fatal("failed to round trip through text marshalling:", err)
+	}
+	if Encode("", EncodingCIDv1) != "" {
+		t.Fatal("should encode invalid peer IDs as base58")
+	}
+}
+
 func TestPublicKeyExtraction(t *testing.T) {
 	t.Skip("disabled until libp2p/go-libp2p-crypto#51 is fixed")
 	// Happy path
@@ -1,12 +1,15 @@
 package peer
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/internal/catch"
 	pb "github.com/libp2p/go-libp2p-core/peer/pb"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/libp2p/go-libp2p-core/record"
 
 	ma "github.com/multiformats/go-multiaddr"
@@ -93,6 +96,37 @@ type PeerRecord struct {
 	// but newer PeerRecords MUST have a greater Seq value than older records
 	// for the same peer.
 	Seq uint64
+
+	// Protocols optionally lists the protocols supported by the peer, so that
+	// peers discovering it can filter by capability before dialing. It is
+	// signed along with the addresses, and empty in records from peers that
+	// don't advertise their protocols.
+	Protocols []protocol.ID
+}
+
+// SupportsProtocols returns true if the record advertises all of the given
+// protocols. Records that don't advertise any protocols support none.
+func (r *PeerRecord) SupportsProtocols(protos ...protocol.ID) bool {
+	for _, want := range protos {
+		found := false
+		for _, p := range r.Protocols {
+			if p == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AdvertisesProtocols returns true if the record lists the peer's supported
+// protocols. Records from peers that don't advertise them can't be filtered
+// by capability.
+func (r *PeerRecord) AdvertisesProtocols() bool {
+	return len(r.Protocols) > 0
 }
 
 // NewPeerRecord returns a PeerRecord with a timestamp-based sequence number.
@@ -110,6 +144,35 @@ func PeerRecordFromAddrInfo(info AddrInfo) *PeerRecord {
 	return rec
 }
 
+// SignedPeerRecordFromAddrInfo creates a PeerRecord from an AddrInfo struct,
+// with a timestamp-based sequence number, and seals it in a record.Envelope
+// signed with the given private key.
+//
+// The private key must belong to the peer described by the AddrInfo.
+func SignedPeerRecordFromAddrInfo(sk crypto.PrivKey, info AddrInfo) (*record.Envelope, error) {
+	if !info.ID.MatchesPrivateKey(sk) {
+		return nil, fmt.Errorf("private key does not match peer ID %s", info.ID)
+	}
+	return record.Seal(PeerRecordFromAddrInfo(info), sk)
+}
+
+// ErrSignerMismatch is returned when a signed record describes another peer
+// than the one the Envelope containing it is attributed to.
+var ErrSignerMismatch = errors.New("record is not signed by the peer it describes")
+
+// VerifySigner checks that the PeerRecord, contained in the given Envelope, is
+// about the peer the Envelope is attributed to, i.e. the peer of the
+// Envelope's Issuer: its signer, or the root of its delegation chain.
+//
+// CertifiedAddrBook implementations must check this before accepting a
+// record.
+func (r *PeerRecord) VerifySigner(envelope *record.Envelope) error {
+	if !r.PeerID.MatchesPublicKey(envelope.Issuer()) {
+		return ErrSignerMismatch
+	}
+	return nil
+}
+
 // PeerRecordFromProtobuf creates a PeerRecord from a protobuf PeerRecord
 // struct.
 func PeerRecordFromProtobuf(msg *pb.PeerRecord) (*PeerRecord, error) {
@@ -123,6 +186,9 @@ func PeerRecordFromProtobuf(msg *pb.PeerRecord) (*PeerRecord, error) {
 	record.PeerID = id
 	record.Addrs = addrsFromProtobuf(msg.Addresses)
 	record.Seq = msg.Seq
+	if len(msg.Protocols) > 0 {
+		record.Protocols = protocol.ConvertFromStrings(msg.Protocols)
+	}
 
 	return record, nil
 }
@@ -146,6 +212,12 @@ func TimestampSeq() uint64 {
 	return now
 }
 
+// AddrInfo returns an AddrInfo struct with the peer ID and addresses of the
+// PeerRecord.
+func (r *PeerRecord) AddrInfo() AddrInfo {
+	return AddrInfo{ID: r.PeerID, Addrs: r.Addrs}
+}
+
 // Domain is used when signing and validating PeerRecords contained in Envelopes.
 // It is constant for all PeerRecord instances.
 func (r *PeerRecord) Domain() string {
@@ -215,6 +287,18 @@ func (r *PeerRecord) Equal(other *PeerRecord) bool {
 			return false
 		}
 	}
+	return protocolsEqual(r.Protocols, other.Protocols)
+}
+
+func protocolsEqual(a, b []protocol.ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
 	return true
 }
 
@@ -228,6 +312,7 @@ func (r *PeerRecord) ToProtobuf() (*pb.PeerRecord, error) {
 		PeerId:    idBytes,
 		Addresses: addrsToProtobuf(r.Addrs),
 		Seq:       r.Seq,
+		Protocols: protocol.ConvertToStrings(r.Protocols),
 	}, nil
 }
 
@@ -0,0 +1,210 @@
+package peer
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/internal/catch"
+	"github.com/libp2p/go-libp2p-core/internal/cbor"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p-core/record"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var _ record.Record = (*CBORPeerRecord)(nil)
+
+func init() {
+	record.RegisterType(&CBORPeerRecord{})
+}
+
+// PeerRecordCBOREnvelopePayloadType is the type hint used to identify peer
+// records serialized as DAG-CBOR in an Envelope.
+//
+// There is no registered multicodec for this serialization yet, so a
+// path-like identifier is used instead.
+var PeerRecordCBOREnvelopePayloadType = []byte("/libp2p/peer-record/dag-cbor")
+
+// DAG-CBOR map keys, in canonical order (by length, then bytewise).
+const (
+	cborKeySeq       = "seq"
+	cborKeyAddrs     = "addrs"
+	cborKeyPeerID    = "peerId"
+	cborKeyProtocols = "protocols"
+)
+
+// CBORPeerRecord is a PeerRecord serialized as DAG-CBOR instead of protobuf,
+// so that IPLD-native systems can embed and hash peer records directly in
+// their data model. The serialized record is the map
+//
+//	{"seq": uint, "addrs": [bytes], "peerId": bytes, "protocols": [text]}
+//
+// where addrs are binary multiaddrs and peerId is the binary peer ID. The
+// protocols entry is omitted if the record doesn't advertise any protocols.
+//
+// CBORPeerRecords are signed in the same domain as PeerRecords, and are told
+// apart by the Envelope's payload type, so a receiver consuming an Envelope
+// with PeerRecordEnvelopeDomain gets either a *PeerRecord or a
+// *CBORPeerRecord. Use AsPeerRecord to handle both:
+//
+//	_, untypedRecord, err := record.ConsumeEnvelope(envelopeBytes, peer.PeerRecordEnvelopeDomain)
+//	if err != nil {
+//	  return err
+//	}
+//	peerRec, ok := peer.AsPeerRecord(untypedRecord)
+type CBORPeerRecord struct {
+	PeerRecord
+}
+
+// Codec is a binary identifier for the CBORPeerRecord type. It is constant for
+// all CBORPeerRecord instances.
+func (r *CBORPeerRecord) Codec() []byte {
+	return PeerRecordCBOREnvelopePayloadType
+}
+
+// MarshalRecord serializes a CBORPeerRecord to a byte slice.
+func (r *CBORPeerRecord) MarshalRecord() (res []byte, err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p cbor peer record marshal") }()
+
+	idBytes, err := r.PeerID.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := 3
+	if len(r.Protocols) > 0 {
+		fields++
+	}
+
+	b := cbor.AppendMapHeader(nil, fields)
+	b = cbor.AppendText(b, cborKeySeq)
+	b = cbor.AppendUint(b, r.Seq)
+	b = cbor.AppendText(b, cborKeyAddrs)
+	b = cbor.AppendArrayHeader(b, len(r.Addrs))
+	for _, a := range r.Addrs {
+		b = cbor.AppendBytes(b, a.Bytes())
+	}
+	b = cbor.AppendText(b, cborKeyPeerID)
+	b = cbor.AppendBytes(b, idBytes)
+	if len(r.Protocols) > 0 {
+		b = cbor.AppendText(b, cborKeyProtocols)
+		b = cbor.AppendArrayHeader(b, len(r.Protocols))
+		for _, p := range r.Protocols {
+			b = cbor.AppendText(b, string(p))
+		}
+	}
+	return b, nil
+}
+
+// UnmarshalRecord parses a CBORPeerRecord from a byte slice. Only the
+// canonical DAG-CBOR encoding produced by MarshalRecord is accepted.
+func (r *CBORPeerRecord) UnmarshalRecord(data []byte) (err error) {
+	if r == nil {
+		return fmt.Errorf("cannot unmarshal CBORPeerRecord to nil receiver")
+	}
+
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p cbor peer record unmarshal") }()
+
+	d := cbor.NewDecoder(data)
+	n, err := d.MapHeader()
+	if err != nil {
+		return err
+	}
+	if n != 3 && n != 4 {
+		return fmt.Errorf("expected 3 or 4 fields in cbor peer record, got %d", n)
+	}
+
+	readKey := func(want string) error {
+		k, err := d.Text()
+		if err != nil {
+			return err
+		}
+		if k != want {
+			return fmt.Errorf("expected key %q in cbor peer record, got %q", want, k)
+		}
+		return nil
+	}
+
+	if err := readKey(cborKeySeq); err != nil {
+		return err
+	}
+	seq, err := d.Uint()
+	if err != nil {
+		return err
+	}
+
+	if err := readKey(cborKeyAddrs); err != nil {
+		return err
+	}
+	count, err := d.ArrayHeader()
+	if err != nil {
+		return err
+	}
+	var addrs []ma.Multiaddr
+	for i := 0; i < count; i++ {
+		b, err := d.Bytes()
+		if err != nil {
+			return err
+		}
+		// skip addresses we can't parse, as PeerRecord does
+		if a, err := ma.NewMultiaddrBytes(b); err == nil {
+			addrs = append(addrs, a)
+		}
+	}
+
+	if err := readKey(cborKeyPeerID); err != nil {
+		return err
+	}
+	idBytes, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	var id ID
+	if err := id.UnmarshalBinary(idBytes); err != nil {
+		return err
+	}
+
+	var protos []protocol.ID
+	if n == 4 {
+		if err := readKey(cborKeyProtocols); err != nil {
+			return err
+		}
+		count, err := d.ArrayHeader()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("empty protocols in cbor peer record")
+		}
+		for i := 0; i < count; i++ {
+			p, err := d.Text()
+			if err != nil {
+				return err
+			}
+			protos = append(protos, protocol.ID(p))
+		}
+	}
+
+	if err := d.Finish(); err != nil {
+		return err
+	}
+
+	r.PeerRecord = PeerRecord{PeerID: id, Addrs: addrs, Seq: seq, Protocols: protos}
+	return nil
+}
+
+// AsPeerRecord returns the PeerRecord contained in rec, which is expected to
+// be a *PeerRecord or a *CBORPeerRecord, e.g. as returned by
+// record.ConsumeEnvelope for the PeerRecordEnvelopeDomain.
+func AsPeerRecord(rec record.Record) (*PeerRecord, bool) {
+	switch r := rec.(type) {
+	case *PeerRecord:
+		return r, r != nil
+	case *CBORPeerRecord:
+		if r == nil {
+			return nil, false
+		}
+		return &r.PeerRecord, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,97 @@
+package peer_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	. "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func TestCBORPeerRecordEncoding(t *testing.T) {
+	rec := CBORPeerRecord{PeerRecord{
+		PeerID: ID("\x00\x02id"),
+		Addrs:  []ma.Multiaddr{ma.StringCast("/ip4/1.2.3.4")},
+		Seq:    1000,
+	}}
+	data, err := rec.MarshalRecord()
+	test.AssertNilError(t, err)
+
+	// {"seq": 1000, "addrs": [h'0401020304'], "peerId": h'00026964'}
+	expected := "a3" +
+		"63736571" + "1903e8" +
+		"656164647273" + "81" + "450401020304" +
+		"66706565724964" + "4400026964"
+	if hex.EncodeToString(data) != expected {
+		t.Fatalf("unexpected encoding:\n%x\nexpected:\n%s", data, expected)
+	}
+
+	var decoded CBORPeerRecord
+	test.AssertNilError(t, decoded.UnmarshalRecord(data))
+	if !rec.Equal(&decoded.PeerRecord) {
+		t.Fatal("expected peer record to be unaltered after round-trip serde")
+	}
+
+	// non-canonical seq: 1000 encoded on 4 bytes
+	nonCanonical, _ := hex.DecodeString("a3" + "63736571" + "1a000003e8" +
+		"656164647273" + "80" + "66706565724964" + "4400026964")
+	if err := decoded.UnmarshalRecord(nonCanonical); err == nil {
+		t.Fatal("expected non-canonical encoding to be rejected")
+	}
+	if err := decoded.UnmarshalRecord(append(data, 0)); err == nil {
+		t.Fatal("expected trailing data to be rejected")
+	}
+	if err := decoded.UnmarshalRecord(data[:len(data)-1]); err == nil {
+		t.Fatal("expected truncated data to be rejected")
+	}
+
+	rec.Protocols = []protocol.ID{"/p"}
+	data, err = rec.MarshalRecord()
+	test.AssertNilError(t, err)
+	// {"seq": ..., "addrs": ..., "peerId": ..., "protocols": ["/p"]}
+	expected = "a4" + expected[2:] + "6970726f746f636f6c73" + "81" + "622f70"
+	if hex.EncodeToString(data) != expected {
+		t.Fatalf("unexpected encoding with protocols:\n%x\nexpected:\n%s", data, expected)
+	}
+	test.AssertNilError(t, decoded.UnmarshalRecord(data))
+	if !rec.Equal(&decoded.PeerRecord) {
+		t.Fatal("expected peer record with protocols to be unaltered after round-trip serde")
+	}
+}
+
+func TestSignedCBORPeerRecord(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+
+	rec := &CBORPeerRecord{PeerRecord{PeerID: id, Addrs: test.GenerateTestAddrs(3), Seq: TimestampSeq()}}
+	envelope, err := record.Seal(rec, priv)
+	test.AssertNilError(t, err)
+	if !bytes.Equal(envelope.PayloadType, PeerRecordCBOREnvelopePayloadType) {
+		t.Fatalf("unexpected payload type %q", envelope.PayloadType)
+	}
+	envBytes, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	_, untypedRecord, err := record.ConsumeEnvelope(envBytes, PeerRecordEnvelopeDomain)
+	test.AssertNilError(t, err)
+	if _, ok := untypedRecord.(*CBORPeerRecord); !ok {
+		t.Fatalf("expected a *CBORPeerRecord, got %T", untypedRecord)
+	}
+	peerRec, ok := AsPeerRecord(untypedRecord)
+	if !ok || !peerRec.Equal(&rec.PeerRecord) {
+		t.Fatal("expected peer record to be unaltered after round-trip serde")
+	}
+
+	pbRec := &PeerRecord{PeerID: id, Seq: TimestampSeq()}
+	if r, ok := AsPeerRecord(pbRec); !ok || r != pbRec {
+		t.Fatal("expected AsPeerRecord to return protobuf peer records as is")
+	}
+}
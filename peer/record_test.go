@@ -3,9 +3,11 @@ package peer_test
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/libp2p/go-libp2p-core/crypto"
 	. "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"github.com/libp2p/go-libp2p-core/record"
 	"github.com/libp2p/go-libp2p-core/test"
 )
@@ -53,6 +55,111 @@ func TestSignedPeerRecordFromEnvelope(t *testing.T) {
 	})
 }
 
+func TestDelegatedPeerRecord(t *testing.T) {
+	identity, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	opKey, opPub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := IDFromPrivateKey(identity)
+	test.AssertNilError(t, err)
+
+	now := time.Now()
+	delegation, err := record.SealDelegation(identity, opPub, []string{PeerRecordEnvelopeDomain}, now.Add(-time.Minute), now.Add(time.Hour))
+	test.AssertNilError(t, err)
+	rec := &PeerRecord{PeerID: id, Addrs: test.GenerateTestAddrs(2), Seq: 1}
+	envelope, err := record.SealDelegated(rec, opKey, delegation)
+	test.AssertNilError(t, err)
+	envBytes, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	consumed, untypedRecord, err := record.ConsumeEnvelope(envBytes, PeerRecordEnvelopeDomain)
+	test.AssertNilError(t, err)
+	rec2, ok := untypedRecord.(*PeerRecord)
+	if !ok || !rec.Equal(rec2) {
+		t.Fatal("expected peer record to be unaltered after round-trip serde")
+	}
+	test.AssertNilError(t, rec2.VerifySigner(consumed))
+
+	// the operational key can't sign records of its own identity's peer
+	// without the delegation
+	direct, err := record.Seal(rec, opKey)
+	test.AssertNilError(t, err)
+	if err := rec.VerifySigner(direct); err != ErrSignerMismatch {
+		t.Fatalf("expected ErrSignerMismatch, got %v", err)
+	}
+
+	rev := &PeerRecordRevocation{PeerID: id, Seq: 1}
+	revEnvelope, err := record.SealDelegated(rev, opKey, delegation)
+	if err == nil {
+		t.Fatal("expected the delegation not to cover revocations")
+	}
+	revEnvelope, err = record.Seal(rev, identity)
+	test.AssertNilError(t, err)
+	test.AssertNilError(t, rev.VerifySigner(revEnvelope))
+}
+
+func TestSignedPeerRecordFromAddrInfo(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+
+	info := AddrInfo{ID: id, Addrs: test.GenerateTestAddrs(3)}
+	envelope, err := SignedPeerRecordFromAddrInfo(priv, info)
+	test.AssertNilError(t, err)
+	envBytes, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	_, untypedRecord, err := record.ConsumeEnvelope(envBytes, PeerRecordEnvelopeDomain)
+	test.AssertNilError(t, err)
+	info2 := untypedRecord.(*PeerRecord).AddrInfo()
+	if info2.ID != info.ID {
+		t.Errorf("expected peer ID %s, got %s", info.ID, info2.ID)
+	}
+	test.AssertAddressesEqual(t, info.Addrs, info2.Addrs)
+
+	info.ID = test.RandPeerIDFatal(t)
+	_, err = SignedPeerRecordFromAddrInfo(priv, info)
+	test.ExpectError(t, err, "expected an error when signing a record for another peer")
+}
+
+func TestPeerRecordProtocols(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+
+	protos := []protocol.ID{"/ipfs/kad/1.0.0", "/meshsub/1.1.0"}
+	rec := &PeerRecord{PeerID: id, Addrs: test.GenerateTestAddrs(2), Seq: TimestampSeq(), Protocols: protos}
+	envelope, err := record.Seal(rec, priv)
+	test.AssertNilError(t, err)
+	envBytes, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	_, untypedRecord, err := record.ConsumeEnvelope(envBytes, PeerRecordEnvelopeDomain)
+	test.AssertNilError(t, err)
+	rec2 := untypedRecord.(*PeerRecord)
+	if !rec.Equal(rec2) {
+		t.Fatal("expected peer record with protocols to be unaltered after round-trip serde")
+	}
+	if !rec2.AdvertisesProtocols() || !rec2.SupportsProtocols(protos...) {
+		t.Error("expected record to support its advertised protocols")
+	}
+	if rec2.SupportsProtocols("/ipfs/bitswap/1.2.0") {
+		t.Error("expected record not to support an unlisted protocol")
+	}
+
+	// records without protocols, e.g. from older peers, still parse
+	old := &PeerRecord{PeerID: id, Addrs: rec.Addrs, Seq: rec.Seq}
+	data, err := old.MarshalRecord()
+	test.AssertNilError(t, err)
+	var old2 PeerRecord
+	test.AssertNilError(t, old2.UnmarshalRecord(data))
+	if !old.Equal(&old2) || old2.AdvertisesProtocols() {
+		t.Error("expected peer record without protocols to be unaltered after round-trip serde")
+	}
+}
+
 // This is pretty much guaranteed to pass on Linux no matter how we implement it, but Windows has
 // low clock precision. This makes sure we never get a duplicate.
 func TestTimestampSeq(t *testing.T) {
@@ -0,0 +1,125 @@
+package peer
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/internal/catch"
+	pb "github.com/libp2p/go-libp2p-core/peer/pb"
+	"github.com/libp2p/go-libp2p-core/record"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+var _ record.Record = (*PeerRecordRevocation)(nil)
+
+func init() {
+	record.RegisterType(&PeerRecordRevocation{})
+}
+
+// PeerRecordRevocationEnvelopeDomain is the domain string used for peer record
+// revocations contained in a Envelope.
+const PeerRecordRevocationEnvelopeDomain = "libp2p-peer-record-revocation"
+
+// PeerRecordRevocationEnvelopePayloadType is the type hint used to identify peer
+// record revocations in a Envelope.
+//
+// There is no registered multicodec for revocations yet, so a path-like
+// identifier is used instead.
+var PeerRecordRevocationEnvelopePayloadType = []byte("/libp2p/peer-record-revocation")
+
+// PeerRecordRevocation is a statement, signed by a peer, that all PeerRecords
+// it has published with a Seq less than or equal to the revocation's Seq must
+// no longer be trusted.
+//
+// Revocations are intended for recovering from a key compromise or from the
+// publication of bad addresses: the peer publishes a revocation covering every
+// record issued up to that point, and only records with a higher Seq are
+// accepted thereafter.
+//
+// Like PeerRecords, revocations are shared inside a signed record.Envelope:
+//
+//	rev := &PeerRecordRevocation{PeerID: myPeerId, Seq: lastBadSeq}
+//	envelope, err := record.Seal(rev, myPrivateKey)
+//
+// Peerstores that honor revocations implement peerstore.RevocationBook.
+type PeerRecordRevocation struct {
+	// PeerID is the ID of the peer whose records are revoked.
+	PeerID ID
+
+	// Seq is the highest revoked PeerRecord sequence number.
+	Seq uint64
+}
+
+// Revokes returns true if the given PeerRecord is invalidated by this
+// revocation, i.e. it belongs to the same peer and has a Seq less than or
+// equal to the revocation's Seq.
+func (r *PeerRecordRevocation) Revokes(rec *PeerRecord) bool {
+	if rec == nil {
+		return false
+	}
+	return rec.PeerID == r.PeerID && rec.Seq <= r.Seq
+}
+
+// VerifySigner checks that the PeerRecordRevocation, contained in the given
+// Envelope, is issued by the peer whose records it revokes, i.e. the peer of
+// the Envelope's Issuer. Otherwise any key could revoke the records of any
+// peer, so RevocationBook implementations must check this before accepting a
+// revocation.
+func (r *PeerRecordRevocation) VerifySigner(envelope *record.Envelope) error {
+	if !r.PeerID.MatchesPublicKey(envelope.Issuer()) {
+		return ErrSignerMismatch
+	}
+	return nil
+}
+
+// Domain is used when signing and validating PeerRecordRevocations contained in
+// Envelopes. It is constant for all PeerRecordRevocation instances.
+func (r *PeerRecordRevocation) Domain() string {
+	return PeerRecordRevocationEnvelopeDomain
+}
+
+// Codec is a binary identifier for the PeerRecordRevocation type. It is constant
+// for all PeerRecordRevocation instances.
+func (r *PeerRecordRevocation) Codec() []byte {
+	return PeerRecordRevocationEnvelopePayloadType
+}
+
+// UnmarshalRecord parses a PeerRecordRevocation from a byte slice.
+// This method is called automatically when consuming a record.Envelope
+// whose PayloadType indicates that it contains a PeerRecordRevocation.
+func (r *PeerRecordRevocation) UnmarshalRecord(bytes []byte) (err error) {
+	if r == nil {
+		return fmt.Errorf("cannot unmarshal PeerRecordRevocation to nil receiver")
+	}
+
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p peer record revocation unmarshal") }()
+
+	var msg pb.PeerRecordRevocation
+	if err := proto.Unmarshal(bytes, &msg); err != nil {
+		return err
+	}
+
+	var id ID
+	if err := id.UnmarshalBinary(msg.PeerId); err != nil {
+		return err
+	}
+	r.PeerID = id
+	r.Seq = msg.Seq
+	return nil
+}
+
+// MarshalRecord serializes a PeerRecordRevocation to a byte slice.
+// This method is called automatically when constructing a record.Envelope
+// using record.Seal.
+func (r *PeerRecordRevocation) MarshalRecord() (res []byte, err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p peer record revocation marshal") }()
+
+	idBytes, err := r.PeerID.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&pb.PeerRecordRevocation{
+		PeerId: idBytes,
+		Seq:    r.Seq,
+	})
+}
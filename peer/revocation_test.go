@@ -0,0 +1,65 @@
+package peer_test
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	. "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestPeerRecordRevocation(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+
+	rev := &PeerRecordRevocation{PeerID: id, Seq: 42}
+	envelope, err := record.Seal(rev, priv)
+	test.AssertNilError(t, err)
+	envBytes, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	_, untypedRecord, err := record.ConsumeEnvelope(envBytes, PeerRecordRevocationEnvelopeDomain)
+	test.AssertNilError(t, err)
+	rev2, ok := untypedRecord.(*PeerRecordRevocation)
+	if !ok {
+		t.Fatal("unmarshaled record is not a *PeerRecordRevocation")
+	}
+	if *rev2 != *rev {
+		t.Fatalf("expected revocation to be unaltered after round-trip serde, got %v", rev2)
+	}
+
+	if !rev.Revokes(&PeerRecord{PeerID: id, Seq: 42}) {
+		t.Error("expected record with equal seq to be revoked")
+	}
+	if rev.Revokes(&PeerRecord{PeerID: id, Seq: 43}) {
+		t.Error("expected record with higher seq not to be revoked")
+	}
+	other := test.RandPeerIDFatal(t)
+	if rev.Revokes(&PeerRecord{PeerID: other, Seq: 1}) {
+		t.Error("expected record of another peer not to be revoked")
+	}
+}
+
+func TestPeerRecordRevocationVerifySigner(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+	otherPriv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+
+	rev := &PeerRecordRevocation{PeerID: id, Seq: 1}
+	envelope, err := record.Seal(rev, priv)
+	test.AssertNilError(t, err)
+	test.AssertNilError(t, rev.VerifySigner(envelope))
+
+	// another key can't revoke the peer's records
+	forged, err := record.Seal(rev, otherPriv)
+	test.AssertNilError(t, err)
+	if err := rev.VerifySigner(forged); err != ErrSignerMismatch {
+		t.Fatalf("expected ErrSignerMismatch, got %v", err)
+	}
+}
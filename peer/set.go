@@ -32,6 +32,14 @@ func (ps *Set) Add(p ID) {
 	ps.lk.Unlock()
 }
 
+// Remove removes the given peer from the set, making room for another peer
+// in limited sets.
+func (ps *Set) Remove(p ID) {
+	ps.lk.Lock()
+	delete(ps.ps, p)
+	ps.lk.Unlock()
+}
+
 func (ps *Set) Contains(p ID) bool {
 	ps.lk.RLock()
 	_, ok := ps.ps[p]
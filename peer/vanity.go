@@ -0,0 +1,113 @@
+package peer
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+
+	"github.com/mr-tron/base58/base58"
+)
+
+// ErrInvalidPrefix is returned by GenerateIDWithPrefix when the prefix can't
+// appear in the base58 or base32 representation of a peer ID.
+var ErrInvalidPrefix = errors.New("prefix can't appear in a peer ID")
+
+const base32Alphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// vanityRSABits is the size of the RSA keys generated by GenerateIDWithPrefix.
+const vanityRSABits = 2048
+
+// GenerateIDWithPrefix generates key pairs of the given type until it finds
+// one whose peer ID starts with prefix, using the given number of workers (or
+// one per CPU if workers <= 0). It returns the context error if ctx is done
+// before a matching key is found.
+//
+// The prefix is matched against both the base58 representation of the ID, as
+// returned by ID.String, and the base32 encoded CIDv1 representation (case
+// insensitively). Note that these representations start with a fixed header
+// depending on the key type: Ed25519 IDs, for instance, always start with
+// "12D3KooW" in base58, so the prefix must include it. Every additional
+// character multiplies the expected search time by 58 (or 32).
+func GenerateIDWithPrefix(ctx context.Context, prefix string, keyType, workers int) (ic.PrivKey, ID, error) {
+	match, err := prefixMatcher(prefix)
+	if err != nil {
+		return nil, "", err
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type result struct {
+		sk  ic.PrivKey
+		id  ID
+		err error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result, workers)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				sk, _, err := ic.GenerateKeyPairWithReader(keyType, vanityRSABits, rand.Reader)
+				var id ID
+				if err == nil {
+					id, err = IDFromPrivateKey(sk)
+					if err == nil && !match(id) {
+						continue
+					}
+				}
+				select {
+				case results <- result{sk: sk, id: id, err: err}:
+				default:
+				}
+				return
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	select {
+	case res, ok := <-results:
+		if !ok {
+			return nil, "", ctx.Err()
+		}
+		if res.err != nil {
+			return nil, "", res.err
+		}
+		return res.sk, res.id, nil
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	}
+}
+
+func prefixMatcher(prefix string) (func(ID) bool, error) {
+	if prefix == "" {
+		return func(ID) bool { return true }, nil
+	}
+	_, err := base58.Decode(prefix)
+	b58 := err == nil
+	// CIDv1 strings are prefixed with the 'b' multibase code for base32.
+	lower := strings.ToLower(prefix)
+	b32 := lower[0] == 'b' && strings.Trim(lower[1:], base32Alphabet) == ""
+	if !b58 && !b32 {
+		return nil, ErrInvalidPrefix
+	}
+	return func(id ID) bool {
+		if b58 && strings.HasPrefix(Encode(id, EncodingBase58), prefix) {
+			return true
+		}
+		return b32 && strings.HasPrefix(ToCid(id).String(), lower)
+	}, nil
+}
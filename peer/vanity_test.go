@@ -0,0 +1,57 @@
+package peer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+func TestGenerateIDWithPrefix(t *testing.T) {
+	ctx := context.Background()
+	sk, id, err := GenerateIDWithPrefix(ctx, "12D3KooWA", ic.Ed25519, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(Encode(id, EncodingBase58), "12D3KooWA") {
+		t.Errorf("ID %s doesn't have the requested prefix", id)
+	}
+	if !id.MatchesPrivateKey(sk) {
+		t.Error("ID doesn't match the private key")
+	}
+
+	_, id, err = GenerateIDWithPrefix(ctx, "BAFZAAJAIAEJCA", ic.Ed25519, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(ToCid(id).String(), "bafzaajaiaejca") {
+		t.Errorf("ID %s doesn't have the requested base32 prefix", ToCid(id))
+	}
+
+	if _, _, err := GenerateIDWithPrefix(ctx, "0OIl", ic.Ed25519, 1); err != ErrInvalidPrefix {
+		t.Errorf("expected ErrInvalidPrefix, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := GenerateIDWithPrefix(ctx, "12D3KooWzzzzzzzz", ic.Ed25519, 1); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestGenerateIDWithPrefixIgnoresDefaultEncoding(t *testing.T) {
+	defer func(enc Encoding) { DefaultEncoding = enc }(DefaultEncoding)
+	DefaultEncoding = EncodingCIDv1
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, id, err := GenerateIDWithPrefix(ctx, "12D3KooWA", ic.Ed25519, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(Encode(id, EncodingBase58), "12D3KooWA") {
+		t.Errorf("ID %s doesn't have the requested prefix", Encode(id, EncodingBase58))
+	}
+}
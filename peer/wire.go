@@ -0,0 +1,95 @@
+package peer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/multiformats/go-varint"
+)
+
+// MaxIDSize is the maximum size, in bytes, of the binary peer IDs accepted by
+// ReadID and ConsumeID. It leaves room for IDs inlining large public keys.
+const MaxIDSize = 128
+
+// ErrIDTooLong is returned when reading a length-prefixed peer ID longer than
+// MaxIDSize.
+var ErrIDTooLong = fmt.Errorf("peer ID longer than %d bytes", MaxIDSize)
+
+// Bytes returns the binary representation of the peer ID, the multihash of
+// its public key. A peer ID is a binary string, so it can be used as a map key
+// as is; Bytes is for APIs dealing in byte slices.
+func (id ID) Bytes() []byte {
+	return []byte(id)
+}
+
+// AppendID appends the compact wire form of the peer ID to b: its binary
+// representation, prefixed with its length as an unsigned varint. Wire
+// protocols carrying many IDs can use it instead of an ad-hoc encoding.
+func AppendID(b []byte, id ID) []byte {
+	b = append(b, varint.ToUvarint(uint64(len(id)))...)
+	return append(b, id...)
+}
+
+// ConsumeID parses a peer ID in the compact wire form of AppendID at the start
+// of b, and returns it along with the rest of b.
+func ConsumeID(b []byte) (id ID, rest []byte, err error) {
+	size, n, err := varint.FromUvarint(b)
+	if err != nil {
+		return "", nil, err
+	}
+	if size > MaxIDSize {
+		return "", nil, ErrIDTooLong
+	}
+	b = b[n:]
+	if uint64(len(b)) < size {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	id, err = IDFromBytes(b[:size])
+	if err != nil {
+		return "", nil, err
+	}
+	return id, b[size:], nil
+}
+
+// WriteID writes the peer ID to w in the compact wire form of AppendID.
+func WriteID(w io.Writer, id ID) error {
+	_, err := w.Write(AppendID(make([]byte, 0, varint.MaxLenUvarint63+len(id)), id))
+	return err
+}
+
+// ReadID reads a peer ID in the compact wire form of AppendID from r. Peer
+// IDs longer than MaxIDSize are rejected with ErrIDTooLong before being read.
+func ReadID(r io.Reader) (ID, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = &byteReader{r: r}
+	}
+	size, err := varint.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+	if size > MaxIDSize {
+		return "", ErrIDTooLong
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return IDFromBytes(buf)
+}
+
+// byteReader reads single bytes from a reader, without buffering, so that
+// the rest of the reader is left intact.
+type byteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(b.r, b.buf[:])
+	return b.buf[0], err
+}
@@ -0,0 +1,72 @@
+package peer_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	. "github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	"github.com/multiformats/go-varint"
+)
+
+func TestIDWireForm(t *testing.T) {
+	ids := []ID{test.RandPeerIDFatal(t), test.RandPeerIDFatal(t), test.RandPeerIDFatal(t)}
+
+	var buf bytes.Buffer
+	var b []byte
+	for _, id := range ids {
+		if err := WriteID(&buf, id); err != nil {
+			t.Fatal(err)
+		}
+		b = AppendID(b, id)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Fatal("expected WriteID and AppendID to produce the same bytes")
+	}
+
+	for _, expected := range ids {
+		id, err := ReadID(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != expected {
+			t.Fatalf("expected %s, got %s", expected, id)
+		}
+		id, b, err = ConsumeID(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != expected {
+			t.Fatalf("expected %s, got %s", expected, id)
+		}
+	}
+	if buf.Len() != 0 || len(b) != 0 {
+		t.Fatal("expected all bytes to be consumed")
+	}
+	if _, err := ReadID(&buf); err != io.EOF {
+		t.Fatalf("expected io.EOF at the end of the stream, got %v", err)
+	}
+
+	full := AppendID(nil, ids[0])
+	if _, _, err := ConsumeID(full[:len(full)-1]); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF for a truncated ID, got %v", err)
+	}
+	if _, err := ReadID(noByteReader{bytes.NewReader(full[:len(full)-1])}); err != io.ErrUnexpectedEOF {
+		t.Fatalf("expected io.ErrUnexpectedEOF for a truncated ID, got %v", err)
+	}
+
+	tooLong := varint.ToUvarint(MaxIDSize + 1)
+	if _, _, err := ConsumeID(tooLong); err != ErrIDTooLong {
+		t.Fatalf("expected ErrIDTooLong, got %v", err)
+	}
+	if _, err := ReadID(bytes.NewReader(tooLong)); err != ErrIDTooLong {
+		t.Fatalf("expected ErrIDTooLong, got %v", err)
+	}
+}
+
+// noByteReader hides the io.ByteReader implementation of its reader.
+type noByteReader struct{ r io.Reader }
+
+func (r noByteReader) Read(p []byte) (int, error) { return r.r.Read(p) }
@@ -0,0 +1,62 @@
+package peerstore
+
+import (
+	"bytes"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// AddrIndexBook is implemented by AddrBooks that maintain a reverse index
+// from addresses to peers, so that subsystems such as connection gaters and
+// abuse detectors can find the peers claiming a given IP address or IP and
+// port without scanning every peer.
+//
+// To test whether a given AddrBook / Peerstore implementation is indexed,
+// callers should use the GetAddrIndexBook helper. The PeersWithAddr helper
+// uses the index when available and falls back to a scan otherwise.
+type AddrIndexBook interface {
+	AddrBook
+
+	// PeersWithAddr returns the peers with at least one known (and valid)
+	// address matching prefix, as defined by AddrHasPrefix. For example, the
+	// prefix /ip4/1.2.3.4 matches /ip4/1.2.3.4/tcp/4001 and
+	// /ip4/1.2.3.4/udp/4001/quic, and /ip4/1.2.3.4/tcp/4001 only matches the
+	// former.
+	PeersWithAddr(prefix ma.Multiaddr) peer.IDSlice
+}
+
+// GetAddrIndexBook is a helper to "upcast" an AddrBook to an AddrIndexBook by
+// using type assertion. Returns (nil, false) if the AddrBook is not indexed.
+func GetAddrIndexBook(ab AddrBook) (iab AddrIndexBook, ok bool) {
+	iab, ok = ab.(AddrIndexBook)
+	return iab, ok
+}
+
+// AddrHasPrefix returns true if the leading components of addr are exactly
+// the components of prefix. Every multiaddr has itself as a prefix.
+func AddrHasPrefix(addr, prefix ma.Multiaddr) bool {
+	// Components are self-delimiting, so comparing the binary encodings is
+	// enough to compare them component-wise.
+	return bytes.HasPrefix(addr.Bytes(), prefix.Bytes())
+}
+
+// PeersWithAddr returns the peers with at least one address in ab matching
+// prefix. It uses the index of ab if it is an AddrIndexBook, and otherwise
+// scans the addresses of all peers.
+func PeersWithAddr(ab AddrBook, prefix ma.Multiaddr) peer.IDSlice {
+	if iab, ok := GetAddrIndexBook(ab); ok {
+		return iab.PeersWithAddr(prefix)
+	}
+	var peers peer.IDSlice
+	for _, p := range ab.PeersWithAddrs() {
+		for _, a := range ab.Addrs(p) {
+			if AddrHasPrefix(a, prefix) {
+				peers = append(peers, p)
+				break
+			}
+		}
+	}
+	return peers
+}
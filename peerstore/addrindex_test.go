@@ -0,0 +1,207 @@
+package peerstore
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// memAddrBook is a minimal AddrBook honouring TTLs, with an injectable clock.
+type memAddrBook struct {
+	now   time.Time
+	addrs map[peer.ID]map[string]expiringAddr
+}
+
+type expiringAddr struct {
+	addr    ma.Multiaddr
+	ttl     time.Duration
+	expires time.Time
+}
+
+func newMemAddrBook() *memAddrBook {
+	return &memAddrBook{now: time.Unix(1000, 0), addrs: make(map[peer.ID]map[string]expiringAddr)}
+}
+
+func (ab *memAddrBook) AddAddr(p peer.ID, addr ma.Multiaddr, ttl time.Duration) {
+	ab.AddAddrs(p, []ma.Multiaddr{addr}, ttl)
+}
+
+func (ab *memAddrBook) AddAddrs(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration) {
+	for _, a := range addrs {
+		if e, ok := ab.addrs[p][string(a.Bytes())]; ok && e.expires.After(ab.now.Add(ttl)) {
+			continue
+		}
+		ab.SetAddr(p, a, ttl)
+	}
+}
+
+func (ab *memAddrBook) SetAddr(p peer.ID, addr ma.Multiaddr, ttl time.Duration) {
+	ab.SetAddrs(p, []ma.Multiaddr{addr}, ttl)
+}
+
+func (ab *memAddrBook) SetAddrs(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration) {
+	for _, a := range addrs {
+		if ttl <= 0 {
+			delete(ab.addrs[p], string(a.Bytes()))
+			continue
+		}
+		if ab.addrs[p] == nil {
+			ab.addrs[p] = make(map[string]expiringAddr)
+		}
+		ab.addrs[p][string(a.Bytes())] = expiringAddr{addr: a, ttl: ttl, expires: ab.now.Add(ttl)}
+	}
+}
+
+func (ab *memAddrBook) UpdateAddrs(p peer.ID, oldTTL time.Duration, newTTL time.Duration) {
+	for k, e := range ab.addrs[p] {
+		if e.ttl == oldTTL {
+			if newTTL <= 0 {
+				delete(ab.addrs[p], k)
+				continue
+			}
+			ab.addrs[p][k] = expiringAddr{addr: e.addr, ttl: newTTL, expires: ab.now.Add(newTTL)}
+		}
+	}
+}
+
+func (ab *memAddrBook) Addrs(p peer.ID) []ma.Multiaddr {
+	var out []ma.Multiaddr
+	for _, e := range ab.addrs[p] {
+		if e.expires.After(ab.now) {
+			out = append(out, e.addr)
+		}
+	}
+	return out
+}
+
+func (ab *memAddrBook) AddrStream(context.Context, peer.ID) <-chan ma.Multiaddr { return nil }
+
+func (ab *memAddrBook) ClearAddrs(p peer.ID) { delete(ab.addrs, p) }
+
+func (ab *memAddrBook) PeersWithAddrs() peer.IDSlice {
+	var out peer.IDSlice
+	for p, addrs := range ab.addrs {
+		if len(addrs) > 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// indexedAddrBook is an AddrIndexBook whose index is a fixed answer, to check
+// that PeersWithAddr uses it.
+type indexedAddrBook struct {
+	AddrBook
+	peers peer.IDSlice
+}
+
+func (ab *indexedAddrBook) PeersWithAddr(ma.Multiaddr) peer.IDSlice { return ab.peers }
+
+func sortedPeers(ps peer.IDSlice) peer.IDSlice {
+	sort.Sort(ps)
+	return ps
+}
+
+func TestAddrHasPrefix(t *testing.T) {
+	addr := ma.StringCast("/ip4/1.2.3.4/tcp/4001")
+	for _, tc := range []struct {
+		prefix string
+		exp    bool
+	}{
+		{"/ip4/1.2.3.4", true},
+		{"/ip4/1.2.3.4/tcp/4001", true},
+		{"/ip4/1.2.3.4/tcp/400", false},
+		{"/ip4/1.2.3.5", false},
+		{"/ip4/1.2.3.4/tcp/4001/ws", false},
+		{"/ip4/1.2.3.4/udp/4001", false},
+		{"/ip6/::1", false},
+	} {
+		if got := AddrHasPrefix(addr, ma.StringCast(tc.prefix)); got != tc.exp {
+			t.Errorf("expected AddrHasPrefix(%s, %s) to be %t", addr, tc.prefix, tc.exp)
+		}
+	}
+}
+
+func TestPeersWithAddr(t *testing.T) {
+	ab := newMemAddrBook()
+	a, b, c := peer.ID("a"), peer.ID("b"), peer.ID("c")
+	ip := ma.StringCast("/ip4/1.2.3.4")
+	tcp := ma.StringCast("/ip4/1.2.3.4/tcp/4001")
+	quic := ma.StringCast("/ip4/1.2.3.4/udp/4001/quic")
+	other := ma.StringCast("/ip4/5.6.7.8/tcp/4001")
+
+	check := func(prefix ma.Multiaddr, exp ...peer.ID) {
+		t.Helper()
+		got := sortedPeers(PeersWithAddr(ab, prefix))
+		if len(got) != len(exp) {
+			t.Fatalf("expected %v for %s, got %v", exp, prefix, got)
+		}
+		for i := range got {
+			if got[i] != exp[i] {
+				t.Fatalf("expected %v for %s, got %v", exp, prefix, got)
+			}
+		}
+	}
+
+	check(ip)
+
+	ab.AddAddrs(a, []ma.Multiaddr{tcp, other}, time.Hour)
+	ab.AddAddr(b, quic, time.Minute)
+	ab.AddAddr(c, other, time.Hour)
+	check(ip, a, b)
+	check(tcp, a)
+	check(quic, b)
+	check(other, a, c)
+
+	// removing an address removes the peer from the results for that
+	// address only
+	ab.SetAddr(a, tcp, 0)
+	check(ip, b)
+	check(tcp)
+	check(other, a, c)
+
+	// adding it back restores it
+	ab.AddAddr(a, tcp, time.Hour)
+	check(tcp, a)
+
+	ab.ClearAddrs(a)
+	check(ip, b)
+	check(other, c)
+
+	ab.UpdateAddrs(c, time.Hour, 0)
+	check(other)
+
+	// expired addresses don't match
+	ab.AddAddr(a, tcp, time.Hour)
+	ab.now = ab.now.Add(time.Minute)
+	check(ip, a)
+	check(quic)
+
+	// extending a TTL keeps the address
+	ab.AddAddr(a, tcp, 2*time.Hour)
+	ab.now = ab.now.Add(time.Hour)
+	check(tcp, a)
+	ab.now = ab.now.Add(time.Hour)
+	check(tcp)
+}
+
+func TestPeersWithAddrIndexed(t *testing.T) {
+	ab := newMemAddrBook()
+	ab.AddAddr("a", ma.StringCast("/ip4/1.2.3.4/tcp/4001"), time.Hour)
+	iab := &indexedAddrBook{AddrBook: ab, peers: peer.IDSlice{"b"}}
+	if _, ok := GetAddrIndexBook(ab); ok {
+		t.Fatal("expected an AddrBook without an index not to be an AddrIndexBook")
+	}
+	if _, ok := GetAddrIndexBook(iab); !ok {
+		t.Fatal("expected an AddrIndexBook")
+	}
+	got := PeersWithAddr(iab, ma.StringCast("/ip4/1.2.3.4"))
+	if len(got) != 1 || got[0] != "b" {
+		t.Fatalf("expected PeersWithAddr to use the index, got %v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package peerstore
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrUnsupportedBackupVersion is returned by Backup.Restore when the snapshot
+// was written in a format version the implementation doesn't understand.
+var ErrUnsupportedBackupVersion = errors.New("unsupported peerstore backup version")
+
+// Backup is implemented by Peerstores that can persist their contents, so that
+// nodes can rehydrate their knowledge of the network across restarts.
+//
+// A snapshot contains, for every known peer, its addresses along with their
+// remaining TTLs, its public and private keys, its supported protocols and its
+// certified peer record, if any. Snapshots are written in a versioned format
+// chosen by the implementation, which must start with the format version so
+// that Restore can reject snapshots it doesn't understand.
+//
+// To test whether a given Peerstore implementation supports backups, callers
+// should use the GetBackup helper or type-assert on the Backup interface.
+type Backup interface {
+	// Snapshot writes the contents of the peerstore to w. Addresses that
+	// have expired are not included.
+	Snapshot(w io.Writer) error
+
+	// Restore reads a snapshot written by Snapshot from r and merges it into
+	// the peerstore. Addresses whose TTL has passed since the snapshot was
+	// taken are skipped, and certified peer records are only restored if
+	// they are newer than the ones already stored.
+	//
+	// ErrUnsupportedBackupVersion is returned if the snapshot was written in
+	// an unknown format version.
+	Restore(r io.Reader) error
+}
+
+// GetBackup is a helper to "upcast" a Peerstore to a Backup by using type
+// assertion. Returns (nil, false) if the Peerstore is not a Backup.
+func GetBackup(ps Peerstore) (b Backup, ok bool) {
+	b, ok = ps.(Backup)
+	return b, ok
+}
@@ -0,0 +1,82 @@
+package peerstore
+
+import (
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// EvictionPolicy determines which peers a BoundedAddrBook evicts when it is
+// full.
+type EvictionPolicy int
+
+const (
+	// EvictLeastRecentlyUsed evicts the peers whose addresses were least
+	// recently added, updated or queried.
+	EvictLeastRecentlyUsed EvictionPolicy = iota
+	// EvictOldest evicts the peers that were added first.
+	EvictOldest
+	// EvictRandom evicts random peers.
+	EvictRandom
+	// EvictNone rejects new peers instead of evicting existing ones.
+	EvictNone
+)
+
+func (p EvictionPolicy) String() string {
+	str := [...]string{"LeastRecentlyUsed", "Oldest", "Random", "None"}
+	if p < 0 || int(p) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[p]
+}
+
+// AddrBookLimits bounds the size of a BoundedAddrBook. Zero values mean no
+// limit.
+type AddrBookLimits struct {
+	// MaxPeers is the maximum number of peers with addresses.
+	MaxPeers int
+
+	// MaxAddrsPerPeer is the maximum number of addresses stored per peer.
+	// When exceeded, the addresses with the shortest remaining TTL are
+	// dropped first.
+	MaxAddrsPerPeer int
+
+	// Eviction is the policy used to make room for new peers once MaxPeers
+	// is reached.
+	Eviction EvictionPolicy
+}
+
+// BoundedAddrBook is implemented by AddrBooks that bound the number of peers
+// and addresses they store, so that nodes that see very large numbers of
+// peers don't exhaust their memory or datastore.
+//
+// Bounded AddrBooks never evict peers with addresses stored with
+// ConnectedAddrTTL or PermanentAddrTTL, nor peers with a certified peer
+// record that hasn't expired. If only such peers remain, new peers are
+// rejected even if the eviction policy is not EvictNone.
+//
+// To test whether a given AddrBook / Peerstore implementation is bounded,
+// callers should use the GetBoundedAddrBook helper or type-assert on the
+// BoundedAddrBook interface.
+type BoundedAddrBook interface {
+	AddrBook
+
+	// Limits returns the limits currently in effect.
+	Limits() AddrBookLimits
+
+	// SetLimits changes the limits. If the AddrBook holds more peers or
+	// addresses than allowed by the new limits, the excess is evicted
+	// immediately.
+	SetLimits(AddrBookLimits) error
+
+	// Evicted returns the peers that were most recently evicted, newest
+	// first, up to the given number. This is meant for debugging and
+	// metrics.
+	Evicted(n int) []peer.ID
+}
+
+// GetBoundedAddrBook is a helper to "upcast" an AddrBook to a BoundedAddrBook
+// by using type assertion. Returns (nil, false) if the AddrBook is not a
+// BoundedAddrBook.
+func GetBoundedAddrBook(ab AddrBook) (bab BoundedAddrBook, ok bool) {
+	bab, ok = ab.(BoundedAddrBook)
+	return bab, ok
+}
@@ -0,0 +1,176 @@
+package peerstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// GCCandidate describes a peer considered for garbage collection.
+type GCCandidate struct {
+	// Peer is the candidate peer.
+	Peer peer.ID
+
+	// LastUpdated is the last time data was added or updated for the peer.
+	LastUpdated time.Time
+
+	// EstimatedBytes is an estimate of the memory or storage used by the
+	// peer across all books, as in PeerFootprint.
+	EstimatedBytes int64
+}
+
+// GCPolicy decides which peers a Compactor removes from a Peerstore.
+//
+// Compactors never consider peers with addresses stored with
+// ConnectedAddrTTL or PermanentAddrTTL, nor peers with a certified peer
+// record that hasn't expired, as candidates.
+type GCPolicy interface {
+	// Select returns the candidates to remove.
+	Select(candidates []GCCandidate) []peer.ID
+}
+
+// GCPolicyFunc adapts a function to the GCPolicy interface.
+type GCPolicyFunc func(candidates []GCCandidate) []peer.ID
+
+var _ GCPolicy = GCPolicyFunc(nil)
+
+// Select calls f(candidates).
+func (f GCPolicyFunc) Select(candidates []GCCandidate) []peer.ID {
+	return f(candidates)
+}
+
+// GCOlderThan returns a GCPolicy removing peers that haven't been updated for
+// longer than maxAge.
+func GCOlderThan(maxAge time.Duration) GCPolicy {
+	return GCPolicyFunc(func(candidates []GCCandidate) []peer.ID {
+		cutoff := time.Now().Add(-maxAge)
+		var out []peer.ID
+		for _, c := range candidates {
+			if c.LastUpdated.Before(cutoff) {
+				out = append(out, c.Peer)
+			}
+		}
+		return out
+	})
+}
+
+// GCMaxPeers returns a GCPolicy keeping at most n peers among the candidates,
+// removing the least recently updated ones.
+func GCMaxPeers(n int) GCPolicy {
+	return GCScore(func(c GCCandidate) float64 {
+		return float64(c.LastUpdated.UnixNano())
+	}, n)
+}
+
+// GCMaxBytes returns a GCPolicy removing the least recently updated peers
+// until the candidates left use at most n bytes.
+func GCMaxBytes(n int64) GCPolicy {
+	return GCPolicyFunc(func(candidates []GCCandidate) []peer.ID {
+		sorted := sortCandidates(candidates, func(c GCCandidate) float64 {
+			return float64(c.LastUpdated.UnixNano())
+		})
+		var total int64
+		for _, c := range sorted {
+			total += c.EstimatedBytes
+		}
+		var out []peer.ID
+		for i := len(sorted) - 1; i >= 0 && total > n; i-- {
+			out = append(out, sorted[i].Peer)
+			total -= sorted[i].EstimatedBytes
+		}
+		return out
+	})
+}
+
+// GCScore returns a GCPolicy keeping the keep candidates with the highest
+// score, and removing the others.
+func GCScore(score func(GCCandidate) float64, keep int) GCPolicy {
+	return GCPolicyFunc(func(candidates []GCCandidate) []peer.ID {
+		n := keep
+		if n < 0 {
+			n = 0
+		}
+		if len(candidates) <= n {
+			return nil
+		}
+		sorted := sortCandidates(candidates, score)
+		out := make([]peer.ID, 0, len(sorted)-n)
+		for _, c := range sorted[n:] {
+			out = append(out, c.Peer)
+		}
+		return out
+	})
+}
+
+// GCAny returns a GCPolicy removing the peers selected by any of the given
+// policies.
+func GCAny(policies ...GCPolicy) GCPolicy {
+	return GCPolicyFunc(func(candidates []GCCandidate) []peer.ID {
+		var out peer.IDSlice
+		for _, p := range policies {
+			out = append(out, p.Select(candidates)...)
+		}
+		return out.Unique()
+	})
+}
+
+// sortCandidates returns a copy of candidates sorted by decreasing score.
+func sortCandidates(candidates []GCCandidate, score func(GCCandidate) float64) []GCCandidate {
+	scores := make(map[peer.ID]float64, len(candidates))
+	for _, c := range candidates {
+		scores[c.Peer] = score(c)
+	}
+	sorted := make([]GCCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scores[sorted[i].Peer] > scores[sorted[j].Peer]
+	})
+	return sorted
+}
+
+// CompactProgress reports the progress of a Compact call.
+type CompactProgress struct {
+	// Scanned is the number of peers considered so far.
+	Scanned int
+	// Total is the number of peers to consider.
+	Total int
+	// Removed is the number of peers removed so far.
+	Removed int
+	// BytesFreed is an estimate of the memory or storage freed so far.
+	BytesFreed int64
+}
+
+// Compactor is implemented by Peerstores that can be trimmed on demand
+// according to a GCPolicy, e.g. datastore-backed Peerstores on constrained
+// devices.
+//
+// To test whether a given Peerstore implementation supports compaction,
+// callers should use the GetCompactor helper or type-assert on the Compactor
+// interface.
+type Compactor interface {
+	// GCPolicy returns the policy used by Compact, or nil if no policy is
+	// set, in which case Compact only removes expired data.
+	GCPolicy() GCPolicy
+
+	// SetGCPolicy sets the policy used by Compact, and by the periodic
+	// garbage collection of implementations that run one.
+	SetGCPolicy(GCPolicy)
+
+	// Compact removes expired data and the peers selected by the GCPolicy,
+	// then compacts the underlying storage, if applicable. If progress is
+	// not nil, it is called periodically with the progress so far.
+	//
+	// If ctx is done, Compact stops, keeping the peers removed so far, and
+	// returns the progress made along with the context error.
+	Compact(ctx context.Context, progress func(CompactProgress)) (CompactProgress, error)
+}
+
+// GetCompactor is a helper to "upcast" a Peerstore to a Compactor by using
+// type assertion. Returns (nil, false) if the Peerstore doesn't support
+// compaction.
+func GetCompactor(ps Peerstore) (c Compactor, ok bool) {
+	c, ok = ps.(Compactor)
+	return c, ok
+}
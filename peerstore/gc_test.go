@@ -0,0 +1,73 @@
+package peerstore
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+func TestGCPolicies(t *testing.T) {
+	now := time.Now()
+	candidates := []GCCandidate{
+		{Peer: "a", LastUpdated: now.Add(-3 * time.Hour), EstimatedBytes: 100},
+		{Peer: "b", LastUpdated: now.Add(-2 * time.Hour), EstimatedBytes: 200},
+		{Peer: "c", LastUpdated: now.Add(-time.Hour), EstimatedBytes: 300},
+		{Peer: "d", LastUpdated: now, EstimatedBytes: 400},
+	}
+	bySize := func(c GCCandidate) float64 { return float64(c.EstimatedBytes) }
+
+	for _, tc := range []struct {
+		name       string
+		policy     GCPolicy
+		candidates []GCCandidate
+		expected   []peer.ID
+	}{
+		{"older than, none", GCOlderThan(4 * time.Hour), candidates, nil},
+		{"older than", GCOlderThan(90 * time.Minute), candidates, []peer.ID{"a", "b"}},
+		{"older than, no candidates", GCOlderThan(0), nil, nil},
+		{"max peers, under the limit", GCMaxPeers(4), candidates, nil},
+		{"max peers", GCMaxPeers(1), candidates, []peer.ID{"a", "b", "c"}},
+		{"max peers, zero", GCMaxPeers(0), candidates, []peer.ID{"a", "b", "c", "d"}},
+		{"max bytes, under the limit", GCMaxBytes(1000), candidates, nil},
+		{"max bytes", GCMaxBytes(700), candidates, []peer.ID{"a", "b"}},
+		{"max bytes, zero", GCMaxBytes(0), candidates, []peer.ID{"a", "b", "c", "d"}},
+		{"score", GCScore(bySize, 2), candidates, []peer.ID{"a", "b"}},
+		{"score, negative keep", GCScore(bySize, -1), candidates, []peer.ID{"a", "b", "c", "d"}},
+		{"score, no candidates", GCScore(bySize, 0), nil, nil},
+		{"any", GCAny(GCOlderThan(150*time.Minute), GCMaxPeers(2)), candidates, []peer.ID{"a", "b"}},
+		{"any, none", GCAny(), candidates, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			selected := tc.policy.Select(tc.candidates)
+			sort.Slice(selected, func(i, j int) bool { return selected[i] < selected[j] })
+			if len(selected) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, selected)
+			}
+			for i := range selected {
+				if selected[i] != tc.expected[i] {
+					t.Fatalf("expected %v, got %v", tc.expected, selected)
+				}
+			}
+		})
+	}
+}
+
+func TestGCScoreConcurrent(t *testing.T) {
+	policy := GCScore(func(c GCCandidate) float64 { return float64(c.EstimatedBytes) }, -1)
+	candidates := []GCCandidate{{Peer: "a"}, {Peer: "b"}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if n := len(policy.Select(candidates)); n != 2 {
+				t.Errorf("expected all candidates to be selected, got %d", n)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,96 @@
+package peerstore
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// LatencyConfig configures how a LatencyMetrics implementation aggregates
+// latency measurements.
+type LatencyConfig struct {
+	// Smoothing is the weight of a new measurement in the latency EWMA, in
+	// (0, 1]. Higher values make the EWMA react faster to changes, and be
+	// more sensitive to outliers.
+	Smoothing float64
+
+	// Samples is the number of most recent measurements retained per peer
+	// for LatencyPercentile. Zero disables percentile queries.
+	Samples int
+}
+
+// DefaultLatencyConfig is the configuration of LatencyMetrics implementations
+// unless changed with SetLatencyConfig.
+var DefaultLatencyConfig = LatencyConfig{
+	Smoothing: 0.1,
+	Samples:   32,
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c LatencyConfig) Validate() error {
+	if !(c.Smoothing > 0 && c.Smoothing <= 1) {
+		return fmt.Errorf("latency smoothing factor must be in (0, 1], got %v", c.Smoothing)
+	}
+	if c.Samples < 0 {
+		return fmt.Errorf("number of latency samples must not be negative, got %d", c.Samples)
+	}
+	return nil
+}
+
+// LatencyMetrics is implemented by Metrics that retain recent latency
+// measurements, so that dial ranking and relay selection can use percentiles
+// such as the p95 latency rather than an average skewed by outliers.
+//
+// To test whether a given Metrics / Peerstore implementation supports
+// percentiles, use the GetLatencyMetrics helper.
+type LatencyMetrics interface {
+	Metrics
+
+	// LatencyConfig returns the configuration currently in effect.
+	LatencyConfig() LatencyConfig
+
+	// SetLatencyConfig changes the configuration. Measurements already
+	// recorded are kept, up to the new number of samples.
+	SetLatencyConfig(LatencyConfig) error
+
+	// LatencyPercentile returns the given percentile, in [0, 100], of the
+	// recent latency measurements of a peer, as computed by
+	// LatencyPercentile. It returns zero if there are no measurements.
+	LatencyPercentile(p peer.ID, percentile float64) time.Duration
+}
+
+// GetLatencyMetrics is a helper to "upcast" a Metrics to a LatencyMetrics by
+// using type assertion. Returns (nil, false) if the Metrics doesn't support
+// percentiles.
+func GetLatencyMetrics(m Metrics) (lm LatencyMetrics, ok bool) {
+	lm, ok = m.(LatencyMetrics)
+	return lm, ok
+}
+
+// LatencyPercentile returns the given percentile, in [0, 100], of samples,
+// using the nearest-rank method: the smallest sample such that at least
+// percentile percent of the samples are less than or equal to it. It returns
+// zero if there are no samples. The samples are left untouched.
+//
+// LatencyMetrics implementations should use it, so that percentiles are
+// comparable across implementations.
+func LatencyPercentile(samples []time.Duration, percentile float64) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
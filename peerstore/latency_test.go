@@ -0,0 +1,44 @@
+package peerstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyPercentile(t *testing.T) {
+	ms := time.Millisecond
+	// unsorted, to check that the samples are sorted
+	samples := []time.Duration{50 * ms, 10 * ms, 40 * ms, 20 * ms, 30 * ms}
+
+	for _, tc := range []struct {
+		name       string
+		samples    []time.Duration
+		percentile float64
+		expected   time.Duration
+	}{
+		{"empty", nil, 50, 0},
+		{"empty p0", nil, 0, 0},
+		{"single sample p0", []time.Duration{7 * ms}, 0, 7 * ms},
+		{"single sample p50", []time.Duration{7 * ms}, 50, 7 * ms},
+		{"single sample p100", []time.Duration{7 * ms}, 100, 7 * ms},
+		{"p0", samples, 0, 10 * ms},
+		{"p1", samples, 1, 10 * ms},
+		{"p20", samples, 20, 10 * ms},
+		{"p21", samples, 21, 20 * ms},
+		{"p50", samples, 50, 30 * ms},
+		{"p95", samples, 95, 50 * ms},
+		{"p100", samples, 100, 50 * ms},
+		{"below range", samples, -10, 10 * ms},
+		{"above range", samples, 150, 50 * ms},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := LatencyPercentile(tc.samples, tc.percentile); d != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, d)
+			}
+		})
+	}
+
+	if samples[0] != 50*ms {
+		t.Fatal("expected the samples to be left untouched")
+	}
+}
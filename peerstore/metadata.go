@@ -0,0 +1,127 @@
+package peerstore
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ErrMetadataType is returned by the typed metadata accessors when the stored
+// value doesn't have the requested type.
+var ErrMetadataType = errors.New("peerstore: metadata value has unexpected type")
+
+// MetadataNamespaceSeparator separates the namespace from the key in
+// namespaced metadata keys.
+const MetadataNamespaceSeparator = "/"
+
+// Well-known metadata namespaces. Subsystems storing metadata in the peerstore
+// should use their own namespace, e.g. the protocol ID of the protocol they
+// implement, to avoid collisions.
+const (
+	// MetadataNamespaceIdentify is used by the identify protocol for the
+	// information it learns about remote peers.
+	MetadataNamespaceIdentify = "libp2p/identify"
+)
+
+// MetadataKey returns the key used to store name under the given namespace.
+func MetadataKey(namespace, name string) string {
+	return namespace + MetadataNamespaceSeparator + name
+}
+
+// SplitMetadataKey splits a namespaced metadata key into its namespace and
+// name. If the key isn't namespaced, the returned namespace is empty.
+func SplitMetadataKey(key string) (namespace, name string) {
+	i := strings.LastIndex(key, MetadataNamespaceSeparator)
+	if i < 0 {
+		return "", key
+	}
+	return key[:i], key[i+len(MetadataNamespaceSeparator):]
+}
+
+// NamespacedMetadata wraps a PeerMetadata, prefixing all keys with a
+// namespace. It is itself a PeerMetadata.
+//
+// Note that RemovePeer removes all the values stored for the peer, including
+// those in other namespaces.
+type NamespacedMetadata struct {
+	PeerMetadata
+	namespace string
+}
+
+var _ PeerMetadata = (*NamespacedMetadata)(nil)
+
+// NewNamespacedMetadata returns a view of pm where all keys are stored under
+// the given namespace.
+func NewNamespacedMetadata(pm PeerMetadata, namespace string) *NamespacedMetadata {
+	return &NamespacedMetadata{PeerMetadata: pm, namespace: namespace}
+}
+
+// Namespace returns the namespace of the view.
+func (m *NamespacedMetadata) Namespace() string {
+	return m.namespace
+}
+
+// Get returns the value stored for the key in the view's namespace.
+func (m *NamespacedMetadata) Get(p peer.ID, key string) (interface{}, error) {
+	return m.PeerMetadata.Get(p, MetadataKey(m.namespace, key))
+}
+
+// Put stores a value for the key in the view's namespace.
+func (m *NamespacedMetadata) Put(p peer.ID, key string, val interface{}) error {
+	return m.PeerMetadata.Put(p, MetadataKey(m.namespace, key), val)
+}
+
+func metadataTypeError(key string, val interface{}, want string) error {
+	return fmt.Errorf("%w: key %q holds %T, expected %s", ErrMetadataType, key, val, want)
+}
+
+// GetString returns the string stored for the given peer and key.
+func GetString(pm PeerMetadata, p peer.ID, key string) (string, error) {
+	v, err := pm.Get(p, key)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", metadataTypeError(key, v, "string")
+	}
+	return s, nil
+}
+
+// GetBytes returns the byte slice stored for the given peer and key.
+func GetBytes(pm PeerMetadata, p peer.ID, key string) ([]byte, error) {
+	v, err := pm.Get(p, key)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, metadataTypeError(key, v, "[]byte")
+	}
+	return b, nil
+}
+
+// GetTyped returns the value stored for the given peer and key, asserted to
+// type T. If the stored value doesn't have type T, an error wrapping
+// ErrMetadataType is returned.
+func GetTyped[T any](pm PeerMetadata, p peer.ID, key string) (T, error) {
+	var zero T
+	v, err := pm.Get(p, key)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, metadataTypeError(key, v, reflect.TypeOf((*T)(nil)).Elem().String())
+	}
+	return t, nil
+}
+
+// PutTyped stores a value of type T for the given peer and key. It is
+// equivalent to pm.Put, and exists for symmetry with GetTyped.
+func PutTyped[T any](pm PeerMetadata, p peer.ID, key string, val T) error {
+	return pm.Put(p, key, val)
+}
@@ -52,6 +52,10 @@ const (
 
 // Peerstore provides a threadsafe store of Peer related
 // information.
+//
+// Implementations constructed with an event bus should emit
+// event.EvtPeerAddressesAdded, event.EvtPeerKeyAdded and event.EvtPeerRemoved
+// when peers are mutated, after the mutation is visible to readers.
 type Peerstore interface {
 	io.Closer
 
@@ -172,6 +176,11 @@ type CertifiedAddrBook interface {
 	// existing non-certified addresses for that peer, and only the certified
 	// addresses will be returned from AddrBook.Addrs thereafter.
 	//
+	// The record must be about the peer the Envelope is attributed to, i.e.
+	// the peer of Envelope.Issuer, so that peers can sign their records with
+	// a delegated key. Implementations check this with
+	// peer.PeerRecord.VerifySigner, and return an error for other records.
+	//
 	// Likewise, once certified addresses have been added for a given peer,
 	// any non-certified addresses added via AddrBook.AddAddrs or
 	// AddrBook.SetAddrs will be ignored. AddrBook.SetAddrs may still be used
@@ -182,6 +191,9 @@ type CertifiedAddrBook interface {
 	// GetPeerRecord returns a Envelope containing a PeerRecord for the
 	// given peer id, if one exists.
 	// Returns nil if no signed PeerRecord exists for the peer.
+	//
+	// To pass the record on, e.g. to gossip it, use the Envelope's Bytes
+	// method, which returns the signed bytes as they were received.
 	GetPeerRecord(p peer.ID) *record.Envelope
 }
 
@@ -198,6 +210,41 @@ func GetCertifiedAddrBook(ab AddrBook) (cab CertifiedAddrBook, ok bool) {
 	return cab, ok
 }
 
+// RevocationBook is implemented by CertifiedAddrBooks that honor signed
+// peer.PeerRecordRevocations.
+//
+// Once a revocation has been consumed for a peer, any stored PeerRecord for
+// that peer covered by the revocation is discarded along with its certified
+// addresses, and subsequent calls to CertifiedAddrBook.ConsumePeerRecord with
+// a covered record are ignored (accepted == false).
+//
+// To test whether a given AddrBook / Peerstore implementation supports
+// revocations, use the GetRevocationBook helper.
+type RevocationBook interface {
+	// ConsumeRevocation stores a signed peer.PeerRecordRevocation contained
+	// in a record.Envelope and applies it to the certified address state of
+	// the revoking peer.
+	//
+	// The envelope must be issued by the peer being revoked: implementations
+	// must check peer.PeerRecordRevocation.VerifySigner, and return its error
+	// without storing or applying the revocation otherwise. A revocation with
+	// a Seq lower than or equal to an already stored revocation for the same
+	// peer is ignored, and 'accepted' will be false.
+	ConsumeRevocation(s *record.Envelope) (accepted bool, err error)
+
+	// GetRevocation returns the Envelope containing the latest revocation
+	// for the given peer, or nil if the peer hasn't revoked any records.
+	GetRevocation(p peer.ID) *record.Envelope
+}
+
+// GetRevocationBook is a helper to "upcast" an AddrBook to a RevocationBook
+// by using type assertion. Returns (nil, false) if the AddrBook doesn't
+// support revocations.
+func GetRevocationBook(ab AddrBook) (rb RevocationBook, ok bool) {
+	rb, ok = ab.(RevocationBook)
+	return rb, ok
+}
+
 // KeyBook tracks the keys of Peers.
 type KeyBook interface {
 	// PubKey stores the public key of a peer.
@@ -0,0 +1,95 @@
+package peerstore
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// AddrSource describes how an address was learned.
+type AddrSource int
+
+const (
+	// SourceUnknown is used for addresses added without provenance, e.g.
+	// through AddrBook.AddAddrs.
+	SourceUnknown AddrSource = iota
+	// SourceIdentify is used for addresses the peer announced through the
+	// identify protocol.
+	SourceIdentify
+	// SourceDHT is used for addresses returned by DHT lookups.
+	SourceDHT
+	// SourceManual is used for addresses explicitly provided by the user or
+	// the application, e.g. bootstrap peers.
+	SourceManual
+	// SourceSignedRecord is used for addresses taken from a signed peer
+	// record consumed by a CertifiedAddrBook.
+	SourceSignedRecord
+	// SourceRelay is used for addresses learned through a relay.
+	SourceRelay
+	// SourceObserved is used for addresses observed on connections to the
+	// peer.
+	SourceObserved
+)
+
+func (s AddrSource) String() string {
+	str := [...]string{"Unknown", "Identify", "DHT", "Manual", "SignedRecord", "Relay", "Observed"}
+	if s < 0 || int(s) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[s]
+}
+
+// AddrProvenance is an address along with the sources it was learned from.
+type AddrProvenance struct {
+	Addr ma.Multiaddr
+	// Sources are the sources the address was learned from, in the order in
+	// which they first reported it.
+	Sources []AddrSource
+	// FirstSeen is the time the address was first added.
+	FirstSeen time.Time
+	// LastSeen is the time the address was last added or updated.
+	LastSeen time.Time
+}
+
+// Verified returns true if the address was learned from a signed peer record.
+func (ap *AddrProvenance) Verified() bool {
+	for _, s := range ap.Sources {
+		if s == SourceSignedRecord {
+			return true
+		}
+	}
+	return false
+}
+
+// ProvenanceAddrBook is implemented by AddrBooks that record where each
+// address was learned from, so that dialers can prefer verified addresses and
+// bad addresses can be traced back to their source.
+//
+// Addresses added through the plain AddrBook methods are recorded with
+// SourceUnknown, and addresses from consumed peer records with
+// SourceSignedRecord. The provenance of an address is dropped along with the
+// address when it expires.
+//
+// To test whether a given AddrBook / Peerstore implementation tracks
+// provenance, use the GetProvenanceAddrBook helper.
+type ProvenanceAddrBook interface {
+	AddrBook
+
+	// AddAddrsFrom behaves like AddrBook.AddAddrs, additionally recording
+	// src as a source of the given addresses.
+	AddAddrsFrom(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration, src AddrSource)
+
+	// AddrsWithProvenance returns all known (and valid) addresses for a
+	// given peer, along with their provenance.
+	AddrsWithProvenance(p peer.ID) []AddrProvenance
+}
+
+// GetProvenanceAddrBook is a helper to "upcast" an AddrBook to a
+// ProvenanceAddrBook by using type assertion. Returns (nil, false) if the
+// AddrBook doesn't track provenance.
+func GetProvenanceAddrBook(ab AddrBook) (pab ProvenanceAddrBook, ok bool) {
+	pab, ok = ab.(ProvenanceAddrBook)
+	return pab, ok
+}
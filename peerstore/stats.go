@@ -0,0 +1,115 @@
+package peerstore
+
+import (
+	"sort"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// BookStats reports the size of one of the books of a Peerstore.
+type BookStats struct {
+	// Entries is the number of entries (addresses, keys, protocols,
+	// metadata values) stored in the book.
+	Entries int
+	// EstimatedBytes is an estimate of the memory or storage used by the
+	// book.
+	EstimatedBytes int64
+}
+
+// PeerFootprint is the estimated memory or storage used by a single peer
+// across all books.
+type PeerFootprint struct {
+	Peer           peer.ID
+	EstimatedBytes int64
+}
+
+// Stats is a snapshot of the size of a Peerstore, used to diagnose peerstore
+// bloat.
+type Stats struct {
+	// Peers is the number of peers known to the Peerstore.
+	Peers int
+
+	AddrBook  BookStats
+	KeyBook   BookStats
+	ProtoBook BookStats
+	Metadata  BookStats
+
+	// TopPeers lists the peers with the largest footprint, largest first.
+	TopPeers []PeerFootprint
+}
+
+// EstimatedBytes returns the estimated size of the Peerstore across all books.
+func (s *Stats) EstimatedBytes() int64 {
+	return s.AddrBook.EstimatedBytes + s.KeyBook.EstimatedBytes +
+		s.ProtoBook.EstimatedBytes + s.Metadata.EstimatedBytes
+}
+
+// StatsReporter is implemented by Peerstores that can report their size.
+type StatsReporter interface {
+	// Stats returns a snapshot of the size of the Peerstore, including the
+	// topN peers with the largest footprint.
+	Stats(topN int) Stats
+}
+
+// GetStats returns the Stats of the given Peerstore. If the Peerstore doesn't
+// implement StatsReporter, the stats are estimated by walking its content
+// through the Peerstore interface; in that case, metadata can't be enumerated
+// and is not accounted for.
+func GetStats(ps Peerstore, topN int) Stats {
+	if sr, ok := ps.(StatsReporter); ok {
+		return sr.Stats(topN)
+	}
+
+	peers := ps.Peers()
+	stats := Stats{Peers: len(peers)}
+	footprints := make([]PeerFootprint, 0, len(peers))
+	for _, p := range peers {
+		var size int64
+
+		for _, a := range ps.Addrs(p) {
+			stats.AddrBook.Entries++
+			size += int64(len(a.Bytes()))
+		}
+		stats.AddrBook.EstimatedBytes += size
+
+		var keySize int64
+		if pk := ps.PubKey(p); pk != nil {
+			if raw, err := pk.Raw(); err == nil {
+				stats.KeyBook.Entries++
+				keySize += int64(len(raw))
+			}
+		}
+		if sk := ps.PrivKey(p); sk != nil {
+			if raw, err := sk.Raw(); err == nil {
+				stats.KeyBook.Entries++
+				keySize += int64(len(raw))
+			}
+		}
+		stats.KeyBook.EstimatedBytes += keySize
+		size += keySize
+
+		var protoSize int64
+		if protos, err := ps.GetProtocols(p); err == nil {
+			for _, proto := range protos {
+				stats.ProtoBook.Entries++
+				protoSize += int64(len(proto))
+			}
+		}
+		stats.ProtoBook.EstimatedBytes += protoSize
+		size += protoSize
+
+		footprints = append(footprints, PeerFootprint{Peer: p, EstimatedBytes: size + int64(len(p))})
+	}
+
+	sort.Slice(footprints, func(i, j int) bool {
+		return footprints[i].EstimatedBytes > footprints[j].EstimatedBytes
+	})
+	if topN < 0 {
+		topN = 0
+	}
+	if topN < len(footprints) {
+		footprints = footprints[:topN]
+	}
+	stats.TopPeers = footprints
+	return stats
+}
@@ -0,0 +1,141 @@
+package peerstore
+
+import (
+	"testing"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// walkPeerstore is a Peerstore holding fixed content, implementing only the
+// methods GetStats walks.
+type walkPeerstore struct {
+	Peerstore
+	addrs  map[peer.ID][]ma.Multiaddr
+	pubs   map[peer.ID]ic.PubKey
+	privs  map[peer.ID]ic.PrivKey
+	protos map[peer.ID][]string
+}
+
+func (ps *walkPeerstore) Peers() peer.IDSlice {
+	seen := make(map[peer.ID]struct{})
+	var out peer.IDSlice
+	add := func(p peer.ID) {
+		if _, ok := seen[p]; !ok {
+			seen[p] = struct{}{}
+			out = append(out, p)
+		}
+	}
+	for p := range ps.addrs {
+		add(p)
+	}
+	for p := range ps.pubs {
+		add(p)
+	}
+	for p := range ps.protos {
+		add(p)
+	}
+	return out
+}
+
+func (ps *walkPeerstore) Addrs(p peer.ID) []ma.Multiaddr           { return ps.addrs[p] }
+func (ps *walkPeerstore) PubKey(p peer.ID) ic.PubKey               { return ps.pubs[p] }
+func (ps *walkPeerstore) PrivKey(p peer.ID) ic.PrivKey             { return ps.privs[p] }
+func (ps *walkPeerstore) GetProtocols(p peer.ID) ([]string, error) { return ps.protos[p], nil }
+
+type statsPeerstore struct {
+	Peerstore
+	stats Stats
+}
+
+func (ps *statsPeerstore) Stats(topN int) Stats {
+	s := ps.stats
+	if topN < len(s.TopPeers) {
+		s.TopPeers = s.TopPeers[:topN]
+	}
+	return s
+}
+
+func TestGetStatsEmpty(t *testing.T) {
+	s := GetStats(&walkPeerstore{}, 10)
+	if s.Peers != 0 || s.EstimatedBytes() != 0 || len(s.TopPeers) != 0 {
+		t.Fatalf("expected empty stats, got %+v", s)
+	}
+}
+
+func TestGetStatsWalk(t *testing.T) {
+	priv, pub, err := test.RandTestKeyPair(ic.Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withKey, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withAddrs, withProtos := peer.ID("addrs"), peer.ID("protos")
+	addrs := test.GenerateTestAddrs(3)
+
+	ps := &walkPeerstore{
+		addrs:  map[peer.ID][]ma.Multiaddr{withAddrs: addrs},
+		pubs:   map[peer.ID]ic.PubKey{withKey: pub},
+		privs:  map[peer.ID]ic.PrivKey{withKey: priv},
+		protos: map[peer.ID][]string{withProtos: {"/a/1.0.0", "/b"}},
+	}
+	s := GetStats(ps, 2)
+
+	if s.Peers != 3 {
+		t.Errorf("expected 3 peers, got %d", s.Peers)
+	}
+	var addrBytes int64
+	for _, a := range addrs {
+		addrBytes += int64(len(a.Bytes()))
+	}
+	if s.AddrBook.Entries != 3 || s.AddrBook.EstimatedBytes != addrBytes {
+		t.Errorf("unexpected address book stats %+v", s.AddrBook)
+	}
+	// ed25519 public keys are 32 bytes, private keys 64
+	if s.KeyBook.Entries != 2 || s.KeyBook.EstimatedBytes != 96 {
+		t.Errorf("unexpected key book stats %+v", s.KeyBook)
+	}
+	if s.ProtoBook.Entries != 2 || s.ProtoBook.EstimatedBytes != 10 {
+		t.Errorf("unexpected protocol book stats %+v", s.ProtoBook)
+	}
+	if s.Metadata != (BookStats{}) {
+		t.Errorf("expected metadata not to be accounted for, got %+v", s.Metadata)
+	}
+	if s.EstimatedBytes() != addrBytes+96+10 {
+		t.Errorf("unexpected total size %d", s.EstimatedBytes())
+	}
+
+	if len(s.TopPeers) != 2 {
+		t.Fatalf("expected the top 2 peers, got %v", s.TopPeers)
+	}
+	if s.TopPeers[0].Peer != withKey || s.TopPeers[0].EstimatedBytes != 96+int64(len(withKey)) {
+		t.Errorf("expected the peer with keys first, got %+v", s.TopPeers[0])
+	}
+	if s.TopPeers[0].EstimatedBytes < s.TopPeers[1].EstimatedBytes {
+		t.Errorf("expected the top peers to be sorted, got %v", s.TopPeers)
+	}
+
+	if s := GetStats(ps, -1); len(s.TopPeers) != 0 {
+		t.Errorf("expected no top peers for a negative topN, got %v", s.TopPeers)
+	}
+}
+
+func TestGetStatsReporter(t *testing.T) {
+	stats := Stats{
+		Peers:    2,
+		Metadata: BookStats{Entries: 1, EstimatedBytes: 42},
+		TopPeers: []PeerFootprint{{Peer: "a", EstimatedBytes: 42}, {Peer: "b"}},
+	}
+	s := GetStats(&statsPeerstore{stats: stats}, 1)
+	if s.Peers != 2 || s.Metadata != stats.Metadata || s.EstimatedBytes() != 42 {
+		t.Errorf("expected the stats of the StatsReporter, got %+v", s)
+	}
+	if len(s.TopPeers) != 1 || s.TopPeers[0].Peer != "a" {
+		t.Errorf("expected topN to be passed to the StatsReporter, got %v", s.TopPeers)
+	}
+}
@@ -0,0 +1,58 @@
+// Package ping provides the interface of the ping protocol, which measures
+// the round-trip time to connected peers.
+//
+// Making ping part of the core interfaces lets latency probing be used
+// uniformly, whichever implementation the host runs. Hosts expose their
+// Pinger through host.PingHost.
+package ping
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// ID is the protocol ID of the ping protocol.
+const ID protocol.ID = "/ipfs/ping/1.0.0"
+
+// Result is the result of a single ping.
+type Result struct {
+	// RTT is the measured round-trip time, if Error is nil.
+	RTT time.Duration
+	// Error is the error that ended the ping, if any.
+	Error error
+}
+
+// Pinger pings remote peers.
+//
+// Implementations should record the measured round-trip times in the
+// peerstore with Metrics.RecordLatency.
+type Pinger interface {
+	// Ping pings p repeatedly over a single stream, connecting to it if
+	// needed, and sends the result of every ping on the returned channel.
+	// The channel is closed when ctx is done, or after a Result with a
+	// non-nil Error is sent.
+	Ping(ctx context.Context, p peer.ID) <-chan Result
+}
+
+// Once pings p once, and returns the measured round-trip time.
+func Once(ctx context.Context, pinger Pinger, p peer.ID) (time.Duration, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	select {
+	case res, ok := <-pinger.Ping(ctx, p):
+		if !ok {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+			return 0, errors.New("ping ended without a result")
+		}
+		return res.RTT, res.Error
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
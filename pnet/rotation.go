@@ -0,0 +1,83 @@
+package pnet
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrNoActivePSK is returned when no key of a PSKSchedule is valid at a given
+// time.
+var ErrNoActivePSK = NewError("no pre-shared key is valid at this time")
+
+// ScheduledPSK is a PSK with a validity window.
+type ScheduledPSK struct {
+	Key PSK
+
+	// NotBefore is the time from which the key is valid. The zero time
+	// means the key is valid from the start.
+	NotBefore time.Time
+
+	// NotAfter is the time after which the key is no longer valid. The zero
+	// time means the key doesn't expire.
+	NotAfter time.Time
+}
+
+// ValidAt returns true if the key is valid at the given time.
+func (s ScheduledPSK) ValidAt(t time.Time) bool {
+	return (s.NotBefore.IsZero() || !t.Before(s.NotBefore)) &&
+		(s.NotAfter.IsZero() || !t.After(s.NotAfter))
+}
+
+// PSKSchedule is an ordered list of PSKs with validity windows, used to rotate
+// the key of a private network without restarting all nodes at once.
+//
+// To rotate from key A to key B, operators distribute the schedule
+//
+//	[{Key: B, NotBefore: T}, {Key: A, NotAfter: T + grace}]
+//
+// to all nodes ahead of time T. Until T, nodes only use A. Between T and
+// T + grace, nodes dial with B and accept connections using either key, so
+// nodes whose clocks are slightly off, or that haven't picked up the schedule
+// yet, can still connect. After T + grace, only B is valid.
+type PSKSchedule []ScheduledPSK
+
+// Validate checks that all keys are 32 bytes long and have a non-empty
+// validity window.
+func (s PSKSchedule) Validate() error {
+	if len(s) == 0 {
+		return NewError("empty PSK schedule")
+	}
+	for i, k := range s {
+		if len(k.Key) != 32 {
+			return NewError(fmt.Sprintf("PSK %d: expected 32 byte key, got %d bytes", i, len(k.Key)))
+		}
+		if !k.NotBefore.IsZero() && !k.NotAfter.IsZero() && k.NotAfter.Before(k.NotBefore) {
+			return NewError(fmt.Sprintf("PSK %d: validity window ends before it starts", i))
+		}
+	}
+	return nil
+}
+
+// Active returns the keys that are valid at the given time, in order of
+// preference. Inbound connections should be accepted for any of these keys.
+func (s PSKSchedule) Active(t time.Time) []PSK {
+	var keys []PSK
+	for _, k := range s {
+		if k.ValidAt(t) {
+			keys = append(keys, k.Key)
+		}
+	}
+	return keys
+}
+
+// Current returns the preferred key at the given time, i.e. the first valid
+// key, which should be used for outbound connections. ErrNoActivePSK is
+// returned if no key is valid.
+func (s PSKSchedule) Current(t time.Time) (PSK, error) {
+	for _, k := range s {
+		if k.ValidAt(t) {
+			return k.Key, nil
+		}
+	}
+	return nil, ErrNoActivePSK
+}
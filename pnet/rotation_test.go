@@ -0,0 +1,62 @@
+package pnet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPSKScheduleRotation(t *testing.T) {
+	oldKey := PSK(bytes.Repeat([]byte{1}, 32))
+	newKey := PSK(bytes.Repeat([]byte{2}, 32))
+	rotation := time.Unix(1000, 0)
+	grace := time.Minute
+
+	s := PSKSchedule{
+		{Key: newKey, NotBefore: rotation},
+		{Key: oldKey, NotAfter: rotation.Add(grace)},
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		at      time.Time
+		current PSK
+		active  int
+	}{
+		{rotation.Add(-time.Second), oldKey, 1},
+		{rotation, newKey, 2},
+		{rotation.Add(grace), newKey, 2},
+		{rotation.Add(grace + time.Second), newKey, 1},
+	} {
+		cur, err := s.Current(tc.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(cur, tc.current) {
+			t.Errorf("at %s: unexpected current key", tc.at)
+		}
+		if n := len(s.Active(tc.at)); n != tc.active {
+			t.Errorf("at %s: expected %d active keys, got %d", tc.at, tc.active, n)
+		}
+	}
+
+	expired := PSKSchedule{{Key: oldKey, NotAfter: rotation}}
+	if _, err := expired.Current(rotation.Add(time.Second)); err != ErrNoActivePSK {
+		t.Fatalf("expected ErrNoActivePSK, got %v", err)
+	}
+}
+
+func TestPSKScheduleValidate(t *testing.T) {
+	if err := (PSKSchedule{}).Validate(); err == nil {
+		t.Fatal("expected empty schedule to be invalid")
+	}
+	if err := (PSKSchedule{{Key: PSK{1, 2, 3}}}).Validate(); !IsPNetError(err) {
+		t.Fatalf("expected short key to be invalid, got %v", err)
+	}
+	inverted := PSKSchedule{{Key: make(PSK, 32), NotBefore: time.Unix(2, 0), NotAfter: time.Unix(1, 0)}}
+	if err := inverted.Validate(); err == nil {
+		t.Fatal("expected inverted window to be invalid")
+	}
+}
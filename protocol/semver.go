@@ -0,0 +1,246 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidVersion is returned when a protocol ID doesn't end with a valid
+// semantic version.
+var ErrInvalidVersion = errors.New("invalid protocol version")
+
+// ErrInvalidConstraint is returned by MatchSemver for malformed version
+// constraints.
+var ErrInvalidConstraint = errors.New("invalid version constraint")
+
+// Version is the semantic version of a protocol, e.g. 1.2.0 in /app/kad/1.2.0.
+type Version struct {
+	Major, Minor, Patch uint64
+
+	// Pre is the pre-release version, e.g. "rc.1" in 1.2.0-rc.1, or empty.
+	Pre string
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is respectively lower than, equal to or
+// greater than o. Versions are ordered as specified by Semantic Versioning
+// 2.0.0: a pre-release version is lower than the associated normal version,
+// e.g. 1.0.0-alpha < 1.0.0-alpha.1 < 1.0.0-beta < 1.0.0.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return cmpUint(v.Major, o.Major)
+	case v.Minor != o.Minor:
+		return cmpUint(v.Minor, o.Minor)
+	case v.Patch != o.Patch:
+		return cmpUint(v.Patch, o.Patch)
+	default:
+		return comparePre(v.Pre, o.Pre)
+	}
+}
+
+// comparePre compares pre-release versions, where the empty pre-release
+// version, i.e. a normal version, is the highest.
+func comparePre(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdent(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return cmpUint(uint64(len(as)), uint64(len(bs)))
+}
+
+// compareIdent compares pre-release identifiers: numeric identifiers
+// numerically, lower than alphanumeric ones, which compare lexically.
+func compareIdent(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmpUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func cmpUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseVersion parses a version of the form MAJOR[.MINOR[.PATCH]][-PRE],
+// where PRE is a pre-release version made of dot separated identifiers, e.g.
+// 1.2.0-rc.1. Missing components are zero.
+func ParseVersion(s string) (Version, error) {
+	s, pre, hasPre := strings.Cut(s, "-")
+	if hasPre && !validPre(pre) {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s+"-"+pre)
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+	var nums [3]uint64
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Pre: pre}, nil
+}
+
+// validPre returns true if pre is a valid pre-release version: non-empty
+// identifiers of ASCII alphanumerics and hyphens, without leading zeros in
+// numeric identifiers.
+func validPre(pre string) bool {
+	for _, ident := range strings.Split(pre, ".") {
+		if ident == "" {
+			return false
+		}
+		numeric := true
+		for _, r := range ident {
+			switch {
+			case r >= '0' && r <= '9':
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+				numeric = false
+			default:
+				return false
+			}
+		}
+		if numeric && len(ident) > 1 && ident[0] == '0' {
+			return false
+		}
+	}
+	return true
+}
+
+// SplitVersion splits a protocol ID such as /app/kad/1.2.0 into its base
+// (/app/kad) and its version (1.2.0).
+func SplitVersion(id ID) (base ID, v Version, err error) {
+	i := strings.LastIndexByte(string(id), '/')
+	if i < 0 {
+		return "", Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, id)
+	}
+	v, err = ParseVersion(string(id[i+1:]))
+	if err != nil {
+		return "", Version{}, err
+	}
+	return id[:i], v, nil
+}
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.v)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseConstraint parses a space separated list of comparators, all of which
+// must match. Supported operators are >=, >, <=, <, = (the default), and the
+// ^ (compatible with) and ~ (same minor version) shorthands.
+func parseConstraint(constraint string) ([]comparator, error) {
+	fields := strings.Fields(constraint)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: empty constraint", ErrInvalidConstraint)
+	}
+	var cs []comparator
+	for _, f := range fields {
+		op := f[:len(f)-len(strings.TrimLeft(f, "<>=^~"))]
+		v, err := ParseVersion(f[len(op):])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, f)
+		}
+		switch op {
+		case ">=", ">", "<=", "<", "=", "":
+			cs = append(cs, comparator{op, v})
+		case "^":
+			upper := Version{Major: v.Major + 1}
+			if v.Major == 0 {
+				upper = Version{Minor: v.Minor + 1}
+			}
+			cs = append(cs, comparator{">=", v}, comparator{"<", upper})
+		case "~":
+			cs = append(cs, comparator{">=", v}, comparator{"<", Version{Major: v.Major, Minor: v.Minor + 1}})
+		default:
+			return nil, fmt.Errorf("%w: unknown operator in %q", ErrInvalidConstraint, f)
+		}
+	}
+	return cs, nil
+}
+
+// MatchSemver returns a match function, for use with
+// host.Host.SetStreamHandlerMatch, matching the protocol IDs with the given
+// base whose version satisfies constraint:
+//
+//	match, err := protocol.MatchSemver("/app/kad", ">=1.1.0 <2.0.0")
+//	if err != nil {
+//		return err
+//	}
+//	h.SetStreamHandlerMatch("/app/kad/1.1.0", match, handler)
+//
+// The constraint is a space separated list of comparators, all of which must
+// be satisfied. The operators are >=, >, <=, <, = (the default), ^ (e.g. ^1.2.0
+// means >=1.2.0 <2.0.0, and ^0.2.0 means >=0.2.0 <0.3.0) and ~ (e.g. ~1.2.0
+// means >=1.2.0 <1.3.0).
+func MatchSemver(base ID, constraint string) (func(string) bool, error) {
+	cs, err := parseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	return func(s string) bool {
+		b, v, err := SplitVersion(ID(s))
+		if err != nil || b != base {
+			return false
+		}
+		for _, c := range cs {
+			if !c.matches(v) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,182 @@
+package protocol
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, tc := range []struct {
+		in  string
+		exp Version
+	}{
+		{"1", Version{Major: 1}},
+		{"1.2", Version{Major: 1, Minor: 2}},
+		{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+		{"1.2.3-rc.1", Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1"}},
+		{"1.0.0-x-y.0", Version{Major: 1, Pre: "x-y.0"}},
+	} {
+		v, err := ParseVersion(tc.in)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %s", tc.in, err)
+		}
+		if v != tc.exp {
+			t.Fatalf("expected %q to parse as %+v, got %+v", tc.in, tc.exp, v)
+		}
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		".",
+		"1.",
+		"1..2",
+		"1.2.3.4",
+		"v1.2.3",
+		"-1.2.3",
+		"1.2.x",
+		"1.2.3-",
+		"1.2.3-rc..1",
+		"1.2.3-rc.01",
+		"1.2.3-rc_1",
+		"1.2.3+build",
+		"99999999999999999999.0.0",
+	} {
+		if v, err := ParseVersion(in); !errors.Is(err, ErrInvalidVersion) {
+			t.Fatalf("expected ErrInvalidVersion for %q, got %+v, %v", in, v, err)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// in increasing order
+	ordered := []string{
+		"0.9.9",
+		"1.0.0-0",
+		"1.0.0-2",
+		"1.0.0-10",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	vs := make([]Version, len(ordered))
+	for i, s := range ordered {
+		v, err := ParseVersion(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.String() != s {
+			t.Fatalf("expected %q to round-trip, got %q", s, v)
+		}
+		vs[i] = v
+	}
+	for i := range vs {
+		for j := range vs {
+			exp := 0
+			if i < j {
+				exp = -1
+			} else if i > j {
+				exp = 1
+			}
+			if c := vs[i].Compare(vs[j]); c != exp {
+				t.Fatalf("expected %s compared to %s to be %d, got %d", vs[i], vs[j], exp, c)
+			}
+		}
+	}
+}
+
+func TestSplitVersion(t *testing.T) {
+	base, v, err := SplitVersion("/app/kad/1.2.0-rc.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base != "/app/kad" || v != (Version{Major: 1, Minor: 2, Pre: "rc.1"}) {
+		t.Fatalf("unexpected split: %s, %+v", base, v)
+	}
+	for _, id := range []ID{"1.2.0", "/app/kad", "/app/kad/"} {
+		if _, _, err := SplitVersion(id); !errors.Is(err, ErrInvalidVersion) {
+			t.Fatalf("expected ErrInvalidVersion for %q, got %v", id, err)
+		}
+	}
+}
+
+func TestMatchSemver(t *testing.T) {
+	for _, tc := range []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{
+			constraint: ">=1.1.0 <2.0.0",
+			match:      []string{"/app/kad/1.1.0", "/app/kad/1.9.9", "/app/kad/1.2"},
+			noMatch:    []string{"/app/kad/1.0.9", "/app/kad/2.0.0", "/app/kad/1.1.0-rc.1", "/app/dht/1.1.0", "/app/kad", "/app/kad/x"},
+		},
+		{
+			constraint: "1.2.0",
+			match:      []string{"/app/kad/1.2.0", "/app/kad/1.2"},
+			noMatch:    []string{"/app/kad/1.2.1", "/app/kad/1.2.0-rc.1"},
+		},
+		{
+			constraint: "=1.2.0-rc.1",
+			match:      []string{"/app/kad/1.2.0-rc.1"},
+			noMatch:    []string{"/app/kad/1.2.0", "/app/kad/1.2.0-rc.2"},
+		},
+		{
+			constraint: ">1.0.0 <=1.2.0",
+			match:      []string{"/app/kad/1.0.1", "/app/kad/1.2.0"},
+			noMatch:    []string{"/app/kad/1.0.0", "/app/kad/1.2.1"},
+		},
+		{
+			constraint: ">=1.0.0-beta",
+			match:      []string{"/app/kad/1.0.0-beta", "/app/kad/1.0.0-rc.1", "/app/kad/1.0.0"},
+			noMatch:    []string{"/app/kad/1.0.0-alpha", "/app/kad/0.9.0"},
+		},
+		{
+			constraint: "^1.2.0",
+			match:      []string{"/app/kad/1.2.0", "/app/kad/1.9.0"},
+			noMatch:    []string{"/app/kad/1.1.9", "/app/kad/2.0.0"},
+		},
+		{
+			constraint: "^0.2.0",
+			match:      []string{"/app/kad/0.2.0", "/app/kad/0.2.9"},
+			noMatch:    []string{"/app/kad/0.1.0", "/app/kad/0.3.0", "/app/kad/1.0.0"},
+		},
+		{
+			constraint: "~1.2.0",
+			match:      []string{"/app/kad/1.2.0", "/app/kad/1.2.9"},
+			noMatch:    []string{"/app/kad/1.1.0", "/app/kad/1.3.0"},
+		},
+	} {
+		match, err := MatchSemver("/app/kad", tc.constraint)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %s", tc.constraint, err)
+		}
+		for _, s := range tc.match {
+			if !match(s) {
+				t.Fatalf("expected %q to match %q", s, tc.constraint)
+			}
+		}
+		for _, s := range tc.noMatch {
+			if match(s) {
+				t.Fatalf("expected %q not to match %q", s, tc.constraint)
+			}
+		}
+	}
+}
+
+func TestMatchSemverInvalid(t *testing.T) {
+	for _, constraint := range []string{"", " ", ">=", ">=x", "!=1.0.0", "=>1.0.0", ">=1.0.0 <", "^1.0.0-"} {
+		if _, err := MatchSemver("/app/kad", constraint); !errors.Is(err, ErrInvalidConstraint) {
+			t.Fatalf("expected ErrInvalidConstraint for %q, got %v", constraint, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package record
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Compression is an algorithm used to compress Envelope payloads on the wire.
+type Compression int32
+
+const (
+	// CompressionNone leaves the payload uncompressed.
+	CompressionNone Compression = iota
+	// CompressionDeflate compresses the payload with DEFLATE (RFC 1951). It
+	// is always available.
+	CompressionDeflate
+	// CompressionZstd compresses the payload with Zstandard. It is only
+	// available once registered with RegisterCompression.
+	CompressionZstd
+	// CompressionSnappy compresses the payload with Snappy. It is only
+	// available once registered with RegisterCompression.
+	CompressionSnappy
+)
+
+func (c Compression) String() string {
+	str := [...]string{"None", "Deflate", "Zstd", "Snappy"}
+	if c < 0 || int(c) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[c]
+}
+
+// ErrCompressionNotRegistered is returned when marshalling or unmarshalling an
+// Envelope whose payload uses a compression algorithm that hasn't been
+// registered.
+var ErrCompressionNotRegistered = errors.New("envelope payload compression not registered")
+
+// ErrPayloadTooLarge is returned when unmarshalling an Envelope whose payload
+// decompresses to more than MaxDecompressedPayloadSize bytes.
+var ErrPayloadTooLarge = errors.New("envelope payload too large")
+
+const (
+	// CompressionThreshold is the payload size, in bytes, above which Marshal
+	// compresses the payloads of Envelopes with a Compression set. Smaller
+	// payloads rarely shrink enough to be worth it.
+	CompressionThreshold = 1024
+
+	// MaxDecompressedPayloadSize bounds the size of decompressed payloads, to
+	// protect against decompression bombs.
+	MaxDecompressedPayloadSize = 1 << 20
+)
+
+// CompressFunc compresses a payload.
+type CompressFunc func(payload []byte) ([]byte, error)
+
+// DecompressFunc decompresses a payload. It must fail with
+// ErrPayloadTooLarge rather than produce more than maxSize bytes.
+type DecompressFunc func(data []byte, maxSize int) ([]byte, error)
+
+type compressor struct {
+	compress   CompressFunc
+	decompress DecompressFunc
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[Compression]compressor{
+		CompressionDeflate: {compressDeflate, decompressDeflate},
+	}
+)
+
+// RegisterCompression registers an implementation of a compression
+// algorithm, e.g. of CompressionZstd or CompressionSnappy, which this package
+// doesn't implement to avoid the dependencies. Registering an algorithm
+// again replaces the previous implementation.
+func RegisterCompression(c Compression, compress CompressFunc, decompress DecompressFunc) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[c] = compressor{compress, decompress}
+}
+
+func getCompressor(c Compression) (compressor, error) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	comp, ok := compressors[c]
+	if !ok {
+		return compressor{}, fmt.Errorf("%w: %s", ErrCompressionNotRegistered, c)
+	}
+	return comp, nil
+}
+
+// compressPayload compresses the payload with c if it is larger than the
+// threshold and shrinks. It returns the payload to put on the wire and the
+// compression actually used.
+func compressPayload(payload []byte, c Compression) ([]byte, Compression, error) {
+	if c == CompressionNone || len(payload) <= CompressionThreshold {
+		return payload, CompressionNone, nil
+	}
+	comp, err := getCompressor(c)
+	if err != nil {
+		return nil, CompressionNone, err
+	}
+	compressed, err := comp.compress(payload)
+	if err != nil {
+		return nil, CompressionNone, err
+	}
+	if len(compressed) >= len(payload) {
+		return payload, CompressionNone, nil
+	}
+	return compressed, c, nil
+}
+
+func decompressPayload(data []byte, c Compression) ([]byte, error) {
+	if c == CompressionNone {
+		return data, nil
+	}
+	comp, err := getCompressor(c)
+	if err != nil {
+		return nil, err
+	}
+	return comp.decompress(data, MaxDecompressedPayloadSize)
+}
+
+func compressDeflate(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressDeflate(data []byte, maxSize int) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) > maxSize {
+		return nil, ErrPayloadTooLarge
+	}
+	return out, nil
+}
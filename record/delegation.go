@@ -0,0 +1,243 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/internal/catch"
+	pb "github.com/libp2p/go-libp2p-core/record/pb"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+var _ Record = (*KeyDelegation)(nil)
+
+func init() {
+	RegisterType(&KeyDelegation{})
+}
+
+// KeyDelegationEnvelopeDomain is the domain string used for key delegations
+// contained in an Envelope.
+const KeyDelegationEnvelopeDomain = "libp2p-key-delegation"
+
+// KeyDelegationEnvelopePayloadType is the type hint used to identify key
+// delegations in an Envelope.
+var KeyDelegationEnvelopePayloadType = []byte("/libp2p/key-delegation")
+
+// ErrInvalidDelegation is returned when the delegation chain of an Envelope
+// doesn't authorize its signer.
+var ErrInvalidDelegation = errors.New("invalid key delegation")
+
+// ErrDelegationNoExpiration is returned by SealDelegation when no expiration
+// is given.
+var ErrDelegationNoExpiration = errors.New("key delegations must expire")
+
+// KeyDelegation authorizes a delegate key to sign envelopes on behalf of the
+// key signing the Envelope containing the KeyDelegation, typically a peer's
+// identity key.
+//
+// This allows the identity key to be kept offline, in cold storage, while a
+// short-lived operational key signs routing records:
+//
+//	// offline, with the identity key:
+//	delegation, err := record.SealDelegation(identityKey, opKey.GetPublic(),
+//	    []string{peer.PeerRecordEnvelopeDomain}, time.Now(), time.Now().Add(24*time.Hour))
+//
+//	// online, with the operational key:
+//	envelope, err := record.SealDelegated(peerRec, opKey, delegation)
+//
+// Envelopes carrying a delegation chain are verified by ConsumeEnvelope and
+// ConsumeTypedEnvelope like any other Envelope; the chain is verified along
+// with the signature, and the key the chain is rooted at is returned by
+// Envelope.Issuer.
+type KeyDelegation struct {
+	// Delegate is the key being authorized.
+	Delegate crypto.PubKey
+
+	// Domains restricts the envelope domains the delegate may sign for. An
+	// empty list doesn't restrict the domains.
+	//
+	// The delegate may only delegate further, i.e. sign the next
+	// KeyDelegation of a chain, if KeyDelegationEnvelopeDomain is allowed.
+	Domains []string
+}
+
+// Domain is used when signing and validating KeyDelegations contained in
+// Envelopes. It is constant for all KeyDelegation instances.
+func (d *KeyDelegation) Domain() string {
+	return KeyDelegationEnvelopeDomain
+}
+
+// Codec is a binary identifier for the KeyDelegation type. It is constant for
+// all KeyDelegation instances.
+func (d *KeyDelegation) Codec() []byte {
+	return KeyDelegationEnvelopePayloadType
+}
+
+// MarshalRecord serializes a KeyDelegation to a byte slice.
+func (d *KeyDelegation) MarshalRecord() (res []byte, err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "key delegation marshal") }()
+	key, err := crypto.PublicKeyToProto(d.Delegate)
+	if err != nil {
+		return nil, err
+	}
+	msg := pb.KeyDelegation{
+		Delegate: key,
+		Domains:  d.Domains,
+	}
+	return proto.Marshal(&msg)
+}
+
+// UnmarshalRecord parses a KeyDelegation from a byte slice.
+func (d *KeyDelegation) UnmarshalRecord(data []byte) (err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "key delegation unmarshal") }()
+	var msg pb.KeyDelegation
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	key, err := crypto.PublicKeyFromProto(msg.Delegate)
+	if err != nil {
+		return err
+	}
+	d.Delegate = key
+	d.Domains = msg.Domains
+	return nil
+}
+
+// allows returns true if the delegation covers the given domain.
+func (d *KeyDelegation) allows(domain string) bool {
+	if len(d.Domains) == 0 {
+		return true
+	}
+	for _, dom := range d.Domains {
+		if dom == domain {
+			return true
+		}
+	}
+	return false
+}
+
+// SealDelegation creates an Envelope signed by issuer, authorizing delegate to
+// sign envelopes for the given domains (or any domain if none are given)
+// between notBefore and expiration. The expiration is mandatory, so that a
+// compromised delegate key eventually becomes useless.
+func SealDelegation(issuer crypto.PrivKey, delegate crypto.PubKey, domains []string, notBefore, expiration time.Time) (*Envelope, error) {
+	if expiration.IsZero() {
+		return nil, ErrDelegationNoExpiration
+	}
+	return SealWithValidity(&KeyDelegation{Delegate: delegate, Domains: domains}, issuer, notBefore, expiration)
+}
+
+// SealDelegated is like Seal, but attaches the given chain of delegations,
+// created with SealDelegation and starting at the root key, authorizing
+// privateKey to sign the record.
+//
+// The chain is checked to authorize privateKey for the record's domain, but
+// the validity periods of the delegations are only checked when the Envelope
+// is consumed.
+func SealDelegated(rec Record, privateKey crypto.PrivKey, chain ...*Envelope) (*Envelope, error) {
+	e, err := Seal(rec, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	e.delegations = chain
+	if err := e.verifyDelegations(e.domain); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Issuer returns the key the Envelope's delegation chain is rooted at, or the
+// signer's key if the Envelope has no delegation chain. This is the key the
+// Envelope is to be attributed to, e.g. to derive the peer ID of its author.
+//
+// The delegation chain is only verified when the Envelope is consumed, so the
+// result must not be trusted for Envelopes obtained with UnmarshalEnvelope.
+func (e *Envelope) Issuer() crypto.PubKey {
+	if len(e.delegations) > 0 {
+		return e.delegations[0].PublicKey
+	}
+	return e.PublicKey
+}
+
+// Delegation returns the keys through which authority was delegated to the
+// Envelope's signer, starting at the root key returned by Issuer. It is empty
+// if the signer acts on its own behalf.
+func (e *Envelope) Delegation() []crypto.PubKey {
+	if len(e.delegations) == 0 {
+		return nil
+	}
+	keys := make([]crypto.PubKey, 0, len(e.delegations))
+	for _, d := range e.delegations {
+		keys = append(keys, d.PublicKey)
+	}
+	return keys
+}
+
+// verifyDelegations checks that the delegation chain of the Envelope, if any,
+// is correctly signed and authorizes its signer for the given domain.
+func (e *Envelope) verifyDelegations(domain string) error {
+	if len(e.delegations) == 0 {
+		return nil
+	}
+	var delegate crypto.PubKey
+	for i, d := range e.delegations {
+		if len(d.delegations) > 0 {
+			return fmt.Errorf("%w: nested delegation chain", ErrInvalidDelegation)
+		}
+		if delegate != nil && !d.PublicKey.Equals(delegate) {
+			return fmt.Errorf("%w: link %d is not signed by the previous delegate", ErrInvalidDelegation, i)
+		}
+		if err := d.validate(KeyDelegationEnvelopeDomain); err != nil {
+			return fmt.Errorf("%w: link %d: %s", ErrInvalidDelegation, i, err)
+		}
+		var rec KeyDelegation
+		if err := d.TypedRecord(&rec); err != nil {
+			return fmt.Errorf("%w: link %d: %s", ErrInvalidDelegation, i, err)
+		}
+		if !rec.allows(domain) {
+			return fmt.Errorf("%w: link %d doesn't allow domain %q", ErrInvalidDelegation, i, domain)
+		}
+		// the delegate of every link but the last signs the next link
+		if i < len(e.delegations)-1 && !rec.allows(KeyDelegationEnvelopeDomain) {
+			return fmt.Errorf("%w: link %d doesn't allow further delegation", ErrInvalidDelegation, i)
+		}
+		delegate = rec.Delegate
+	}
+	if !e.PublicKey.Equals(delegate) {
+		return fmt.Errorf("%w: signer is not the final delegate", ErrInvalidDelegation)
+	}
+	return nil
+}
+
+func unmarshalDelegations(data [][]byte) ([]*Envelope, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	delegations := make([]*Envelope, 0, len(data))
+	for _, b := range data {
+		d, err := UnmarshalEnvelope(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal delegation: %w", err)
+		}
+		delegations = append(delegations, d)
+	}
+	return delegations, nil
+}
+
+func marshalDelegations(delegations []*Envelope) ([][]byte, error) {
+	if len(delegations) == 0 {
+		return nil, nil
+	}
+	data := make([][]byte, 0, len(delegations))
+	for _, d := range delegations {
+		b, err := d.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, b)
+	}
+	return data, nil
+}
@@ -0,0 +1,84 @@
+package record_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	. "github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestKeyDelegation(t *testing.T) {
+	RegisterType(&simpleRecord{})
+
+	identity, identityPub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	opKey, opPub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+
+	now := time.Now()
+	rec := &simpleRecord{message: "hello world!"}
+
+	if _, err := SealDelegation(identity, opPub, nil, now, time.Time{}); err != ErrDelegationNoExpiration {
+		t.Fatalf("expected ErrDelegationNoExpiration, got %v", err)
+	}
+
+	delegation, err := SealDelegation(identity, opPub, []string{rec.Domain()}, now.Add(-time.Minute), now.Add(time.Hour))
+	test.AssertNilError(t, err)
+	envelope, err := SealDelegated(rec, opKey, delegation)
+	test.AssertNilError(t, err)
+	data, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	consumed, _, err := ConsumeEnvelope(data, rec.Domain())
+	test.AssertNilError(t, err)
+	if !consumed.Issuer().Equals(identityPub) {
+		t.Error("expected the identity key to be the issuer")
+	}
+	if chain := consumed.Delegation(); len(chain) != 1 || !chain[0].Equals(identityPub) {
+		t.Errorf("unexpected delegation chain %v", chain)
+	}
+
+	// expired delegation
+	_, _, err = ConsumeEnvelopeAt(data, rec.Domain(), now.Add(2*time.Hour))
+	if !errors.Is(err, ErrInvalidDelegation) {
+		t.Errorf("expected ErrInvalidDelegation for an expired delegation, got %v", err)
+	}
+
+	// delegation for another domain
+	other, err := SealDelegation(identity, opPub, []string{"other-domain"}, now, now.Add(time.Hour))
+	test.AssertNilError(t, err)
+	if _, err := SealDelegated(rec, opKey, other); !errors.Is(err, ErrInvalidDelegation) {
+		t.Errorf("expected ErrInvalidDelegation for a delegation to another domain, got %v", err)
+	}
+
+	// delegation to another key
+	if _, err := SealDelegated(rec, identity, delegation); !errors.Is(err, ErrInvalidDelegation) {
+		t.Errorf("expected ErrInvalidDelegation for a delegation to another key, got %v", err)
+	}
+
+	// a chain of two delegations
+	subKey, subPub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	sub, err := SealDelegation(opKey, subPub, nil, now, now.Add(time.Hour))
+	test.AssertNilError(t, err)
+	if _, err := SealDelegated(rec, subKey, delegation, sub); !errors.Is(err, ErrInvalidDelegation) {
+		t.Errorf("expected ErrInvalidDelegation for a sub-delegation not allowed by the first link, got %v", err)
+	}
+	delegation, err = SealDelegation(identity, opPub, []string{rec.Domain(), KeyDelegationEnvelopeDomain}, now.Add(-time.Minute), now.Add(time.Hour))
+	test.AssertNilError(t, err)
+	envelope, err = SealDelegated(rec, subKey, delegation, sub)
+	test.AssertNilError(t, err)
+	data, err = envelope.Marshal()
+	test.AssertNilError(t, err)
+	consumed, _, err = ConsumeEnvelope(data, rec.Domain())
+	test.AssertNilError(t, err)
+	if len(consumed.Delegation()) != 2 || !consumed.Issuer().Equals(identityPub) {
+		t.Error("unexpected delegation chain")
+	}
+	if _, err := SealDelegated(rec, subKey, sub, delegation); !errors.Is(err, ErrInvalidDelegation) {
+		t.Errorf("expected ErrInvalidDelegation for an out of order chain, got %v", err)
+	}
+}
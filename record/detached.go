@@ -0,0 +1,108 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrDetachedEnvelope is returned when validating or consuming a detached
+// Envelope without its payload. Use VerifyDetached or ConsumeDetachedEnvelope
+// instead.
+var ErrDetachedEnvelope = errors.New("envelope payload is detached")
+
+// ErrNotDetached is returned by VerifyDetached for Envelopes that carry their
+// payload.
+var ErrNotDetached = errors.New("envelope is not detached")
+
+// Detach returns a copy of the Envelope without its payload, along with the
+// payload. This is useful when the payload is large and stored elsewhere,
+// e.g. in a DHT or a content store, so that only the signature and metadata
+// have to travel in the Envelope:
+//
+//	envelope, err := record.Seal(rec, privKey)
+//	if err != nil {
+//	  return err
+//	}
+//	detached, payload := envelope.Detach()
+//	storePayload(payload)
+//	publishEnvelope(detached)
+//
+// The signature still covers the payload, so the detached Envelope can only be
+// verified with VerifyDetached or ConsumeDetachedEnvelope, given the payload.
+func (e *Envelope) Detach() (*Envelope, []byte) {
+	return &Envelope{
+		PublicKey:   e.PublicKey,
+		PayloadType: e.PayloadType,
+		NotBefore:   e.NotBefore,
+		Expiration:  e.Expiration,
+		Compression: e.Compression,
+		signature:   e.signature,
+		delegations: e.delegations,
+		detached:    true,
+		domain:      e.domain,
+	}, e.RawPayload
+}
+
+// IsDetached returns true if the Envelope's payload was left out with Detach.
+func (e *Envelope) IsDetached() bool {
+	return e.detached
+}
+
+// VerifyDetached verifies the signature of a detached Envelope over the given
+// payload, and checks its validity period against the current time.
+//
+// The signature is verified against the Envelope's Domain if it is known, and
+// otherwise against the domain of the Record type registered for its
+// PayloadType. Use ConsumeDetachedEnvelope to verify against a given domain.
+func (e *Envelope) VerifyDetached(payload []byte) error {
+	if !e.detached {
+		return ErrNotDetached
+	}
+	domain := e.domain
+	if domain == "" {
+		rec, err := blankRecordForPayloadType(e.PayloadType)
+		if err != nil {
+			return err
+		}
+		domain = rec.Domain()
+	}
+	if err := e.validatePayload(domain, payload); err != nil {
+		return err
+	}
+	return e.ValidAt(time.Now())
+}
+
+// ConsumeDetachedEnvelope is like ConsumeEnvelope for detached Envelopes: it
+// unmarshals a serialized detached Envelope and validates its signature over
+// the given payload using the provided 'domain' string.
+//
+// On success, the payload is unmarshalled into a Record, which is returned
+// and made available through the Envelope's Record method. The returned
+// Envelope remains detached. As with ConsumeEnvelope, the Envelope may be
+// returned along with an error, and must not be used in that case.
+func ConsumeDetachedEnvelope(data []byte, payload []byte, domain string) (envelope *Envelope, rec Record, err error) {
+	e, err := UnmarshalEnvelope(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed when unmarshalling the envelope: %w", err)
+	}
+	if !e.detached {
+		return e, nil, ErrNotDetached
+	}
+
+	if err := e.validatePayload(domain, payload); err != nil {
+		return e, nil, fmt.Errorf("failed to validate envelope: %w", err)
+	}
+	e.domain = domain
+
+	if err := e.ValidAt(time.Now()); err != nil {
+		return e, nil, err
+	}
+
+	rec, err = unmarshalRecordPayload(e.PayloadType, payload)
+	if err != nil {
+		return e, nil, fmt.Errorf("failed to unmarshal envelope payload: %w", err)
+	}
+	e.cached = rec
+	return e, rec, nil
+}
@@ -2,9 +2,11 @@ package record
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/libp2p/go-libp2p-core/internal/catch"
@@ -33,9 +35,36 @@ type Envelope struct {
 	// The envelope payload.
 	RawPayload []byte
 
+	// NotBefore is the time before which the envelope is not valid. The zero
+	// time means no lower bound. See SealWithValidity.
+	NotBefore time.Time
+
+	// Expiration is the time after which the envelope is not valid. The zero
+	// time means no expiry. See SealWithValidity.
+	Expiration time.Time
+
+	// Compression is the algorithm Marshal compresses the payload with if it
+	// is larger than CompressionThreshold. It is set to the algorithm used
+	// on the wire by UnmarshalEnvelope, which decompresses the payload.
+	// Compression is transparent: the signature and RawPayload are those of
+	// the uncompressed payload.
+	Compression Compression
+
 	// The signature of the domain string :: type hint :: payload.
 	signature []byte
 
+	// the chain of KeyDelegation envelopes authorizing the signer, see SealDelegated.
+	delegations []*Envelope
+
+	// whether the payload was left out of the envelope, see SealDetached.
+	detached bool
+
+	// the domain the envelope was sealed with or consumed for, see Domain.
+	domain string
+
+	// the serialized envelope, if obtained with UnmarshalEnvelope, see Bytes.
+	raw []byte
+
 	// the unmarshalled payload as a Record, cached on first access via the Record accessor method
 	cached         Record
 	unmarshalError error
@@ -45,10 +74,49 @@ type Envelope struct {
 var ErrEmptyDomain = errors.New("envelope domain must not be empty")
 var ErrEmptyPayloadType = errors.New("payloadType must not be empty")
 var ErrInvalidSignature = errors.New("invalid signature or incorrect domain")
+var ErrNoMatchingDomain = errors.New("envelope signature not valid for any of the given domains")
+var ErrEnvelopeNotYetValid = errors.New("envelope is not valid yet")
+var ErrEnvelopeExpired = errors.New("envelope has expired")
+var ErrInvalidValidity = errors.New("envelope validity period must be after the Unix epoch")
 
 // Seal marshals the given Record, places the marshaled bytes inside an Envelope,
 // and signs with the given private key.
 func Seal(rec Record, privateKey crypto.PrivKey) (*Envelope, error) {
+	return SealWithValidity(rec, privateKey, time.Time{}, time.Time{})
+}
+
+// SealWithValidity is like Seal, but restricts the validity of the Envelope to
+// the period between notBefore and expiration, so that time-bounded records
+// don't have to carry their own expiry. Either time may be zero to leave that
+// end of the period open. Times are truncated to whole seconds, and must be
+// after the Unix epoch, otherwise ErrInvalidValidity is returned.
+//
+// Envelopes with a validity period are rejected by ConsumeEnvelope and
+// ConsumeTypedEnvelope outside of that period. Note that peers that don't
+// support validity periods will fail to verify such envelopes.
+func SealWithValidity(rec Record, privateKey crypto.PrivKey, notBefore, expiration time.Time) (*Envelope, error) {
+	return SealWithSigner(context.Background(), rec, privateKey, notBefore, expiration)
+}
+
+// SealWithSigner is like SealWithValidity, but signs with a crypto.Signer
+// instead of a private key, e.g. a crypto.ThresholdSigner, so that the
+// private key doesn't have to be held in memory. ThresholdSigners are given
+// ctx to bound the signing rounds.
+//
+// Signatures produced by Signers other than private keys are verified before
+// the Envelope is returned, and ErrInvalidSignature is returned if they don't
+// verify under the Signer's public key.
+func SealWithSigner(ctx context.Context, rec Record, signer crypto.Signer, notBefore, expiration time.Time) (*Envelope, error) {
+	if !notBefore.IsZero() && !expiration.IsZero() && expiration.Before(notBefore) {
+		return nil, fmt.Errorf("envelope expiration %s is before not-before time %s", expiration, notBefore)
+	}
+	// the zero Unix time means no limit on the wire, so earlier times can't be
+	// encoded
+	for _, t := range []time.Time{notBefore, expiration} {
+		if !t.IsZero() && t.Unix() <= 0 {
+			return nil, fmt.Errorf("%w: got %s", ErrInvalidValidity, t)
+		}
+	}
 	payload, err := rec.MarshalRecord()
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling record: %v", err)
@@ -64,22 +132,42 @@ func Seal(rec Record, privateKey crypto.PrivKey) (*Envelope, error) {
 		return nil, ErrEmptyPayloadType
 	}
 
-	unsigned, err := makeUnsigned(domain, payloadType, payload)
+	nbf, exp := toUnixSeconds(notBefore), toUnixSeconds(expiration)
+	unsigned, err := makeUnsigned(domain, payloadType, payload, nbf, exp)
 	if err != nil {
 		return nil, err
 	}
 	defer pool.Put(unsigned)
 
-	sig, err := privateKey.Sign(unsigned)
+	var sig []byte
+	if ts, ok := signer.(crypto.ThresholdSigner); ok {
+		sig, err = ts.SignContext(ctx, unsigned)
+	} else {
+		sig, err = signer.Sign(unsigned)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	pub := signer.GetPublic()
+	if _, ok := signer.(crypto.PrivKey); !ok {
+		valid, err := pub.Verify(unsigned, sig)
+		if err != nil {
+			return nil, fmt.Errorf("failed while verifying signature: %w", err)
+		}
+		if !valid {
+			return nil, ErrInvalidSignature
+		}
+	}
+
 	return &Envelope{
-		PublicKey:   privateKey.GetPublic(),
+		PublicKey:   pub,
 		PayloadType: payloadType,
 		RawPayload:  payload,
+		NotBefore:   fromUnixSeconds(nbf),
+		Expiration:  fromUnixSeconds(exp),
 		signature:   sig,
+		domain:      domain,
 	}, nil
 }
 
@@ -113,6 +201,12 @@ func Seal(rec Record, privateKey crypto.PrivKey) (*Envelope, error) {
 // PayloadType, ErrPayloadTypeNotRegistered will be returned, along with the Envelope and
 // a nil Record.
 func ConsumeEnvelope(data []byte, domain string) (envelope *Envelope, rec Record, err error) {
+	return ConsumeEnvelopeAt(data, domain, time.Now())
+}
+
+// ConsumeEnvelopeAt is like ConsumeEnvelope, but checks the Envelope's validity
+// period against the given time instead of the current time.
+func ConsumeEnvelopeAt(data []byte, domain string, now time.Time) (envelope *Envelope, rec Record, err error) {
 	e, err := UnmarshalEnvelope(data)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed when unmarshalling the envelope: %w", err)
@@ -122,6 +216,11 @@ func ConsumeEnvelope(data []byte, domain string) (envelope *Envelope, rec Record
 	if err != nil {
 		return e, nil, fmt.Errorf("failed to validate envelope: %w", err)
 	}
+	e.domain = domain
+
+	if err := e.ValidAt(now); err != nil {
+		return e, nil, err
+	}
 
 	rec, err = e.Record()
 	if err != nil {
@@ -161,6 +260,11 @@ func ConsumeTypedEnvelope(data []byte, destRecord Record) (envelope *Envelope, e
 	if err != nil {
 		return e, fmt.Errorf("failed to validate envelope: %w", err)
 	}
+	e.domain = destRecord.Domain()
+
+	if err := e.ValidAt(time.Now()); err != nil {
+		return e, err
+	}
 
 	err = destRecord.UnmarshalRecord(e.RawPayload)
 	if err != nil {
@@ -183,11 +287,27 @@ func UnmarshalEnvelope(data []byte) (*Envelope, error) {
 		return nil, err
 	}
 
+	delegations, err := unmarshalDelegations(e.Delegations)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := decompressPayload(e.Payload, Compression(e.Compression))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress envelope payload: %w", err)
+	}
+
 	return &Envelope{
 		PublicKey:   key,
 		PayloadType: e.PayloadType,
-		RawPayload:  e.Payload,
+		RawPayload:  payload,
+		Compression: Compression(e.Compression),
+		NotBefore:   fromUnixSeconds(e.NotBefore),
+		Expiration:  fromUnixSeconds(e.Expiration),
 		signature:   e.Signature,
+		delegations: delegations,
+		detached:    e.Detached,
+		raw:         append([]byte(nil), data...),
 	}, nil
 }
 
@@ -200,15 +320,48 @@ func (e *Envelope) Marshal() (res []byte, err error) {
 		return nil, err
 	}
 
+	delegations, err := marshalDelegations(e.delegations)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, compression, err := compressPayload(e.RawPayload, e.Compression)
+	if err != nil {
+		return nil, err
+	}
+
 	msg := pb.Envelope{
 		PublicKey:   key,
 		PayloadType: e.PayloadType,
-		Payload:     e.RawPayload,
+		Payload:     payload,
+		Compression: pb.Compression(compression),
 		Signature:   e.signature,
+		NotBefore:   toUnixSeconds(e.NotBefore),
+		Expiration:  toUnixSeconds(e.Expiration),
+		Delegations: delegations,
+		Detached:    e.detached,
 	}
 	return proto.Marshal(&msg)
 }
 
+// Bytes returns the serialized Envelope. For Envelopes obtained with
+// UnmarshalEnvelope or one of the Consume functions, these are the exact bytes
+// the Envelope was unmarshalled from, so that it can be passed on, e.g.
+// gossiped, byte for byte: re-marshalling it with Marshal may yield a
+// different encoding, e.g. if the signer's protobuf encoder orders fields
+// differently or added fields this version doesn't know about, which
+// downstream peers may not accept. For other Envelopes, Bytes is equivalent
+// to Marshal.
+//
+// The bytes aren't updated when the Envelope's exported fields are modified;
+// use Marshal to serialize a modified Envelope.
+func (e *Envelope) Bytes() ([]byte, error) {
+	if e.raw != nil {
+		return e.raw, nil
+	}
+	return e.Marshal()
+}
+
 // Equal returns true if the other Envelope has the same public key,
 // payload, payload type, and signature. This implies that they were also
 // created with the same domain string.
@@ -217,6 +370,7 @@ func (e *Envelope) Equal(other *Envelope) bool {
 		return e == nil
 	}
 	return e.PublicKey.Equals(other.PublicKey) &&
+		e.detached == other.detached &&
 		bytes.Equal(e.PayloadType, other.PayloadType) &&
 		bytes.Equal(e.signature, other.signature) &&
 		bytes.Equal(e.RawPayload, other.RawPayload)
@@ -248,10 +402,100 @@ func (e *Envelope) TypedRecord(dest Record) error {
 	return dest.UnmarshalRecord(e.RawPayload)
 }
 
+// Domain returns the domain the Envelope was sealed with, or the domain its
+// signature was validated against when it was consumed, e.g. with
+// ConsumeEnvelope.
+//
+// The domain isn't part of the serialized Envelope, so Domain returns an empty
+// string for Envelopes obtained with UnmarshalEnvelope. Use the domain
+// returned by VerifyWithAnyDomain in that case.
+func (e *Envelope) Domain() string {
+	return e.domain
+}
+
+// VerifyWithAnyDomain validates the Envelope's signature against each of the
+// given domains in order, and returns the first domain the signature is valid
+// for, after checking the Envelope's validity period against the current
+// time. This allows code that handles several record types to route an
+// Envelope without knowing its domain in advance:
+//
+//	envelope, err := UnmarshalEnvelope(envelopeBytes)
+//	if err != nil {
+//		return err
+//	}
+//	domain, err := envelope.VerifyWithAnyDomain(knownDomains)
+//	if err != nil {
+//		return err
+//	}
+//	rec, err := envelope.Record()
+//
+// If the signature is not valid for any of the domains, ErrNoMatchingDomain is
+// returned. If it is valid, but the Envelope is outside of its validity
+// period, the error of ValidAt is returned.
+//
+// VerifyWithAnyDomain doesn't modify the Envelope, so it is safe to call
+// concurrently, and doesn't change the result of Domain.
+func (e *Envelope) VerifyWithAnyDomain(domains []string) (string, error) {
+	return e.VerifyWithAnyDomainAt(domains, time.Now())
+}
+
+// VerifyWithAnyDomainAt is like VerifyWithAnyDomain, but checks the
+// Envelope's validity period against the given time instead of the current
+// time.
+func (e *Envelope) VerifyWithAnyDomainAt(domains []string, now time.Time) (string, error) {
+	for _, domain := range domains {
+		if domain == "" {
+			continue
+		}
+		err := e.validate(domain)
+		if err == nil {
+			if err := e.ValidAt(now); err != nil {
+				return "", err
+			}
+			return domain, nil
+		}
+		if err != ErrInvalidSignature {
+			return "", err
+		}
+	}
+	return "", ErrNoMatchingDomain
+}
+
+// ValidAt returns nil if the Envelope's validity period includes the given
+// time, ErrEnvelopeNotYetValid if now is before the NotBefore time, and
+// ErrEnvelopeExpired if now is after the Expiration time. The validity periods
+// of the delegations authorizing the signer, if any, are checked as well.
+//
+// ValidAt doesn't verify the signature.
+func (e *Envelope) ValidAt(now time.Time) error {
+	if !e.NotBefore.IsZero() && now.Before(e.NotBefore) {
+		return ErrEnvelopeNotYetValid
+	}
+	if !e.Expiration.IsZero() && now.After(e.Expiration) {
+		return ErrEnvelopeExpired
+	}
+	for _, d := range e.delegations {
+		if err := d.ValidAt(now); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidDelegation, err)
+		}
+	}
+	return nil
+}
+
 // validate returns nil if the envelope signature is valid for the given 'domain',
-// or an error if signature validation fails.
+// and its signer is authorized by its delegation chain, if any, or an error if
+// signature validation fails.
 func (e *Envelope) validate(domain string) error {
-	unsigned, err := makeUnsigned(domain, e.PayloadType, e.RawPayload)
+	if e.detached {
+		return ErrDetachedEnvelope
+	}
+	return e.validatePayload(domain, e.RawPayload)
+}
+
+// validatePayload is like validate, but verifies the signature over the given
+// payload instead of the Envelope's RawPayload.
+func (e *Envelope) validatePayload(domain string, payload []byte) error {
+	unsigned, err := makeUnsigned(domain, e.PayloadType, payload, toUnixSeconds(e.NotBefore), toUnixSeconds(e.Expiration))
 	if err != nil {
 		return err
 	}
@@ -264,13 +508,16 @@ func (e *Envelope) validate(domain string) error {
 	if !valid {
 		return ErrInvalidSignature
 	}
-	return nil
+	return e.verifyDelegations(domain)
 }
 
 // makeUnsigned is a helper function that prepares a buffer to sign or verify.
 // It returns a byte slice from a pool. The caller MUST return this slice to the
 // pool.
-func makeUnsigned(domain string, payloadType []byte, payload []byte) ([]byte, error) {
+//
+// The validity period is only included if set, so that the buffer for
+// envelopes without one is unchanged.
+func makeUnsigned(domain string, payloadType []byte, payload []byte, notBefore, expiration uint64) ([]byte, error) {
 	var (
 		fields = [][]byte{[]byte(domain), payloadType, payload}
 
@@ -281,6 +528,11 @@ func makeUnsigned(domain string, payloadType []byte, payload []byte) ([]byte, er
 		size = 0
 	)
 
+	if notBefore != 0 || expiration != 0 {
+		fields = append(fields, varint.ToUvarint(notBefore), varint.ToUvarint(expiration))
+		flen = make([][]byte, len(fields))
+	}
+
 	for i, f := range fields {
 		l := len(f)
 		flen[i] = varint.ToUvarint(uint64(l))
@@ -297,3 +549,17 @@ func makeUnsigned(domain string, payloadType []byte, payload []byte) ([]byte, er
 
 	return b[:s], nil
 }
+
+func toUnixSeconds(t time.Time) uint64 {
+	if t.IsZero() || t.Unix() <= 0 {
+		return 0
+	}
+	return uint64(t.Unix())
+}
+
+func fromUnixSeconds(s uint64) time.Time {
+	if s == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(s), 0)
+}
@@ -2,8 +2,12 @@ package record_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	crypto "github.com/libp2p/go-libp2p-core/crypto"
 	. "github.com/libp2p/go-libp2p-core/record"
@@ -89,6 +93,362 @@ func TestEnvelopeHappyPath(t *testing.T) {
 	}
 }
 
+func TestEnvelopeValidity(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "hello world!"}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+		notBefore    = time.Unix(1000, 0)
+		expiration   = time.Unix(2000, 0)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := SealWithValidity(rec, priv, notBefore, expiration)
+	test.AssertNilError(t, err)
+	serialized, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	RegisterType(&simpleRecord{})
+	deserialized, _, err := ConsumeEnvelopeAt(serialized, rec.Domain(), time.Unix(1500, 0))
+	test.AssertNilError(t, err)
+	if !deserialized.NotBefore.Equal(notBefore) || !deserialized.Expiration.Equal(expiration) {
+		t.Errorf("unexpected validity period %s - %s", deserialized.NotBefore, deserialized.Expiration)
+	}
+
+	if _, _, err := ConsumeEnvelopeAt(serialized, rec.Domain(), time.Unix(999, 0)); err != ErrEnvelopeNotYetValid {
+		t.Errorf("expected ErrEnvelopeNotYetValid, got %v", err)
+	}
+	if _, _, err := ConsumeEnvelopeAt(serialized, rec.Domain(), time.Unix(2001, 0)); err != ErrEnvelopeExpired {
+		t.Errorf("expected ErrEnvelopeExpired, got %v", err)
+	}
+	if _, err := ConsumeTypedEnvelope(serialized, &simpleRecord{}); err != ErrEnvelopeExpired {
+		t.Errorf("expected ErrEnvelopeExpired, got %v", err)
+	}
+
+	// the validity period is covered by the signature
+	msg := &pb.Envelope{}
+	test.AssertNilError(t, proto.Unmarshal(serialized, msg))
+	msg.Expiration = 3000
+	tampered, err := proto.Marshal(msg)
+	test.AssertNilError(t, err)
+	if _, _, err := ConsumeEnvelopeAt(tampered, rec.Domain(), time.Unix(2500, 0)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	// times up to the Unix epoch would be encoded as no limit
+	for _, ts := range [][2]time.Time{
+		{time.Unix(-1, 0), expiration},
+		{time.Unix(0, 0), expiration},
+		{time.Time{}, time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC)},
+	} {
+		if _, err := SealWithValidity(rec, priv, ts[0], ts[1]); !errors.Is(err, ErrInvalidValidity) {
+			t.Errorf("expected ErrInvalidValidity for %s - %s, got %v", ts[0], ts[1], err)
+		}
+	}
+}
+
+func TestEnvelopeText(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "hello world!"}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	text, err := envelope.MarshalText()
+	test.AssertNilError(t, err)
+	if text[0] != 'u' {
+		t.Errorf("expected base64url multibase prefix, got %q", text[0])
+	}
+
+	RegisterType(&simpleRecord{})
+	var decoded Envelope
+	test.AssertNilError(t, decoded.UnmarshalText(text))
+	if !envelope.Equal(&decoded) {
+		t.Error("round-trip text serde results in unequal envelope structures")
+	}
+	if decoded.Domain() != rec.Domain() {
+		t.Errorf("expected domain %q, got %q", rec.Domain(), decoded.Domain())
+	}
+
+	text[len(text)-2] ^= 1
+	if err := decoded.UnmarshalText(text); err == nil {
+		t.Error("expected tampered envelope to be rejected")
+	}
+}
+
+func TestEnvelopeDetached(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "a large payload"}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	if envelope.IsDetached() {
+		t.Fatal("sealed envelope shouldn't be detached")
+	}
+	if err := envelope.VerifyDetached(envelope.RawPayload); err != ErrNotDetached {
+		t.Fatalf("expected ErrNotDetached, got %v", err)
+	}
+
+	detached, payload := envelope.Detach()
+	if !detached.IsDetached() || detached.RawPayload != nil {
+		t.Fatal("expected envelope without payload")
+	}
+	if string(payload) != rec.message {
+		t.Fatalf("expected payload %q, got %q", rec.message, payload)
+	}
+	test.AssertNilError(t, detached.VerifyDetached(payload))
+	if err := detached.VerifyDetached([]byte("another payload")); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	data, err := detached.Marshal()
+	test.AssertNilError(t, err)
+	if bytes.Contains(data, payload) {
+		t.Fatal("serialized detached envelope contains the payload")
+	}
+
+	if _, _, err := ConsumeEnvelope(data, rec.Domain()); !errors.Is(err, ErrDetachedEnvelope) {
+		t.Fatalf("expected ErrDetachedEnvelope, got %v", err)
+	}
+
+	RegisterType(&simpleRecord{})
+	consumed, r, err := ConsumeDetachedEnvelope(data, payload, rec.Domain())
+	test.AssertNilError(t, err)
+	if !consumed.Equal(detached) {
+		t.Error("round-trip serde results in unequal envelope structures")
+	}
+	if r.(*simpleRecord).message != rec.message {
+		t.Errorf("expected message %q, got %q", rec.message, r.(*simpleRecord).message)
+	}
+	if cached, err := consumed.Record(); err != nil || cached != r {
+		t.Error("expected consumed record to be cached")
+	}
+	if _, _, err := ConsumeDetachedEnvelope(data, payload, "other-domain"); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	unmarshalled, err := UnmarshalEnvelope(data)
+	test.AssertNilError(t, err)
+	test.AssertNilError(t, unmarshalled.VerifyDetached(payload))
+}
+
+// thresholdSigner simulates a threshold signer with a single key, failing
+// unless enough participants are online.
+type thresholdSigner struct {
+	key    crypto.PrivKey
+	online int
+	ctxs   int
+	forged bool
+}
+
+func (s *thresholdSigner) Sign(data []byte) ([]byte, error) {
+	return s.SignContext(context.Background(), data)
+}
+
+func (s *thresholdSigner) SignContext(ctx context.Context, data []byte) ([]byte, error) {
+	s.ctxs++
+	if t, _ := s.Threshold(); s.online < t {
+		return nil, crypto.ErrThresholdNotMet
+	}
+	if s.forged {
+		data = append([]byte("forged"), data...)
+	}
+	return s.key.Sign(data)
+}
+
+func (s *thresholdSigner) GetPublic() crypto.PubKey { return s.key.GetPublic() }
+
+func (s *thresholdSigner) Threshold() (t, n int) { return 2, 3 }
+
+func TestSealWithSigner(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	rec := &simpleRecord{message: "hello world!"}
+	signer := &thresholdSigner{key: priv, online: 2}
+
+	envelope, err := SealWithSigner(context.Background(), rec, signer, time.Time{}, time.Time{})
+	test.AssertNilError(t, err)
+	if signer.ctxs != 1 {
+		t.Error("expected SignContext to be used")
+	}
+	data, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	_, err = ConsumeTypedEnvelope(data, &simpleRecord{})
+	test.AssertNilError(t, err)
+
+	signer.online = 1
+	if _, err := SealWithSigner(context.Background(), rec, signer, time.Time{}, time.Time{}); err != crypto.ErrThresholdNotMet {
+		t.Fatalf("expected ErrThresholdNotMet, got %v", err)
+	}
+
+	signer.online, signer.forged = 3, true
+	if _, err := SealWithSigner(context.Background(), rec, signer, time.Time{}, time.Time{}); err != ErrInvalidSignature {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestEnvelopeCompression(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: strings.Repeat("/ip4/1.2.3.4/tcp/4001 ", 200)}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	plain, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+
+	envelope.Compression = CompressionDeflate
+	compressed, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	if len(compressed) >= len(plain)/2 {
+		t.Fatalf("expected compressed envelope to be much smaller: %d vs %d bytes", len(compressed), len(plain))
+	}
+
+	consumed, err := ConsumeTypedEnvelope(compressed, &simpleRecord{})
+	test.AssertNilError(t, err)
+	if !envelope.Equal(consumed) {
+		t.Error("round-trip serde results in unequal envelope structures")
+	}
+	if consumed.Compression != CompressionDeflate {
+		t.Errorf("expected deflate compression, got %s", consumed.Compression)
+	}
+
+	// small payloads are left uncompressed
+	small, err := Seal(&simpleRecord{message: "hello"}, priv)
+	test.AssertNilError(t, err)
+	small.Compression = CompressionDeflate
+	data, err := small.Marshal()
+	test.AssertNilError(t, err)
+	unmarshalled, err := UnmarshalEnvelope(data)
+	test.AssertNilError(t, err)
+	if unmarshalled.Compression != CompressionNone {
+		t.Errorf("expected no compression, got %s", unmarshalled.Compression)
+	}
+
+	envelope.Compression = CompressionZstd
+	if _, err := envelope.Marshal(); !errors.Is(err, ErrCompressionNotRegistered) {
+		t.Fatalf("expected ErrCompressionNotRegistered, got %v", err)
+	}
+
+	// payloads decompressing to more than MaxDecompressedPayloadSize bytes
+	// are rejected
+	bomb, err := Seal(&simpleRecord{message: strings.Repeat("a", MaxDecompressedPayloadSize+1)}, priv)
+	test.AssertNilError(t, err)
+	bomb.Compression = CompressionDeflate
+	data, err = bomb.Marshal()
+	test.AssertNilError(t, err)
+	if len(data) >= MaxDecompressedPayloadSize/100 {
+		t.Fatalf("expected the payload to compress well, got %d bytes", len(data))
+	}
+	if _, err := UnmarshalEnvelope(data); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+	}
+}
+
+func TestEnvelopeBytes(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	test.AssertNilError(t, err)
+	envelope, err := Seal(&simpleRecord{message: "hello world!"}, priv)
+	test.AssertNilError(t, err)
+
+	sealed, err := envelope.Bytes()
+	test.AssertNilError(t, err)
+	marshalled, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	if !bytes.Equal(sealed, marshalled) {
+		t.Fatal("expected Bytes of a sealed envelope to match Marshal")
+	}
+
+	// append a field unknown to this version, which Marshal drops
+	data := append(marshalled, 0x78, 0x01)
+	consumed, _, err := ConsumeEnvelope(data, envelope.Domain())
+	test.AssertNilError(t, err)
+	raw, err := consumed.Bytes()
+	test.AssertNilError(t, err)
+	if !bytes.Equal(raw, data) {
+		t.Fatal("expected Bytes to return the bytes the envelope was unmarshalled from")
+	}
+	remarshalled, err := consumed.Marshal()
+	test.AssertNilError(t, err)
+	if bytes.Equal(remarshalled, data) {
+		t.Fatal("expected Marshal to drop the unknown field")
+	}
+}
+
+func TestEnvelopeVerifyWithAnyDomain(t *testing.T) {
+	var (
+		rec          = &simpleRecord{message: "hello world!"}
+		priv, _, err = test.RandTestKeyPair(crypto.Ed25519, 256)
+	)
+	test.AssertNilError(t, err)
+
+	envelope, err := Seal(rec, priv)
+	test.AssertNilError(t, err)
+	if envelope.Domain() != rec.Domain() {
+		t.Errorf("expected sealed envelope domain %q, got %q", rec.Domain(), envelope.Domain())
+	}
+
+	serialized, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	unmarshalled, err := UnmarshalEnvelope(serialized)
+	test.AssertNilError(t, err)
+	if unmarshalled.Domain() != "" {
+		t.Errorf("expected unvalidated envelope to have no domain, got %q", unmarshalled.Domain())
+	}
+
+	if _, err := unmarshalled.VerifyWithAnyDomain([]string{"foo", "bar"}); err != ErrNoMatchingDomain {
+		t.Errorf("expected ErrNoMatchingDomain, got %v", err)
+	}
+	domain, err := unmarshalled.VerifyWithAnyDomain([]string{"foo", rec.Domain()})
+	test.AssertNilError(t, err)
+	if domain != rec.Domain() {
+		t.Errorf("expected domain %q, got %q", rec.Domain(), domain)
+	}
+	if unmarshalled.Domain() != "" {
+		t.Errorf("expected verification not to modify the envelope, got domain %q", unmarshalled.Domain())
+	}
+
+	// a shared envelope can be verified concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := unmarshalled.VerifyWithAnyDomain([]string{"foo", rec.Domain()}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	consumed, _, err := ConsumeEnvelope(serialized, rec.Domain())
+	test.AssertNilError(t, err)
+	if consumed.Domain() != rec.Domain() {
+		t.Errorf("expected consumed envelope domain %q, got %q", rec.Domain(), consumed.Domain())
+	}
+
+	// the validity period is checked along with the signature
+	expiring, err := SealWithValidity(rec, priv, time.Unix(1000, 0), time.Unix(2000, 0))
+	test.AssertNilError(t, err)
+	if _, err := expiring.VerifyWithAnyDomain([]string{rec.Domain()}); err != ErrEnvelopeExpired {
+		t.Errorf("expected ErrEnvelopeExpired, got %v", err)
+	}
+	if _, err := expiring.VerifyWithAnyDomainAt([]string{rec.Domain()}, time.Unix(999, 0)); err != ErrEnvelopeNotYetValid {
+		t.Errorf("expected ErrEnvelopeNotYetValid, got %v", err)
+	}
+	domain, err = expiring.VerifyWithAnyDomainAt([]string{"foo", rec.Domain()}, time.Unix(1500, 0))
+	test.AssertNilError(t, err)
+	if domain != rec.Domain() {
+		t.Errorf("expected domain %q, got %q", rec.Domain(), domain)
+	}
+}
+
 func TestConsumeTypedEnvelope(t *testing.T) {
 	var (
 		rec          = simpleRecord{message: "hello world!"}
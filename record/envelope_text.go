@@ -0,0 +1,68 @@
+package record
+
+import (
+	"encoding"
+	"fmt"
+	"time"
+
+	"github.com/multiformats/go-multibase"
+)
+
+var (
+	_ encoding.TextMarshaler   = (*Envelope)(nil)
+	_ encoding.TextUnmarshaler = (*Envelope)(nil)
+)
+
+// MarshalText returns the Envelope serialized with Marshal and encoded as a
+// multibase base64url string, suitable for configuration files, DNS TXT
+// records and command line flags.
+func (e *Envelope) MarshalText() ([]byte, error) {
+	data, err := e.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	s, err := multibase.Encode(multibase.Base64url, data)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText parses an Envelope encoded with MarshalText. Any multibase
+// encoding is accepted.
+//
+// Since the domain isn't part of the serialized Envelope, the payload type
+// must have been registered with RegisterType; the signature is then
+// validated against the domain of the registered Record type, and the
+// Envelope's validity period is checked against the current time.
+func (e *Envelope) UnmarshalText(text []byte) error {
+	_, data, err := multibase.Decode(string(text))
+	if err != nil {
+		return fmt.Errorf("failed to decode envelope text: %w", err)
+	}
+	env, err := UnmarshalEnvelope(data)
+	if err != nil {
+		return fmt.Errorf("failed when unmarshalling the envelope: %w", err)
+	}
+	rec, err := blankRecordForPayloadType(env.PayloadType)
+	if err != nil {
+		return err
+	}
+	if err := env.validate(rec.Domain()); err != nil {
+		return fmt.Errorf("failed to validate envelope: %w", err)
+	}
+	if err := env.ValidAt(time.Now()); err != nil {
+		return err
+	}
+
+	e.PublicKey = env.PublicKey
+	e.PayloadType = env.PayloadType
+	e.RawPayload = env.RawPayload
+	e.NotBefore = env.NotBefore
+	e.Expiration = env.Expiration
+	e.Compression = env.Compression
+	e.signature = env.signature
+	e.delegations = env.delegations
+	e.domain = rec.Domain()
+	return nil
+}
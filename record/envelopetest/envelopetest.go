@@ -0,0 +1,291 @@
+// Package envelopetest provides test vectors for signed record envelopes, so
+// that implementations in other languages, and fuzzers, can check that they
+// sign and serialize envelopes byte for byte like this implementation.
+//
+// The vectors can be exported as JSON with WriteJSON:
+//
+//	if err := envelopetest.WriteJSON(os.Stdout); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The exported vectors are committed in testdata/vectors.json, and the tests
+// of this package fail if they change.
+package envelopetest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Hex is a byte slice encoded in hexadecimal in JSON.
+type Hex []byte
+
+// MarshalText encodes h in hexadecimal.
+func (h Hex) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(h)), nil
+}
+
+// UnmarshalText decodes h from hexadecimal.
+func (h *Hex) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
+// Vector is a test vector for a signed envelope.
+type Vector struct {
+	// Name describes the vector.
+	Name string `json:"name"`
+
+	// PrivateKey is the signer's private key, serialized with
+	// crypto.MarshalPrivateKey.
+	PrivateKey Hex `json:"privateKey"`
+
+	// Domain, PayloadType and Payload are the inputs of the signature.
+	Domain      string `json:"domain"`
+	PayloadType Hex    `json:"payloadType"`
+	Payload     Hex    `json:"payload"`
+
+	// NotBefore and Expiration are the validity period of the envelope, in
+	// Unix seconds, or 0 if unbounded.
+	NotBefore  int64 `json:"notBefore,omitempty"`
+	Expiration int64 `json:"expiration,omitempty"`
+
+	// SigningInput is the exact byte string signed, see
+	// record.SigningInput.
+	SigningInput Hex `json:"signingInput"`
+
+	// Signature is the signature of SigningInput.
+	Signature Hex `json:"signature"`
+
+	// Envelope is the serialized envelope.
+	Envelope Hex `json:"envelope"`
+}
+
+// CanonicalizePayload returns the canonical form of a payload of the given
+// type. Check compares payloads in canonical form, so that implementations
+// under test, or fuzzers, that re-encode payloads differently but
+// equivalently, e.g. with protobuf fields in a different order, can set it to
+// a function normalizing the encoding. It defaults to the identity, i.e.
+// payloads are compared byte for byte.
+var CanonicalizePayload = func(payloadType, payload []byte) ([]byte, error) {
+	return payload, nil
+}
+
+// rawRecord is a record.Record with an arbitrary domain, codec and payload.
+type rawRecord struct {
+	domain  string
+	codec   []byte
+	payload []byte
+}
+
+func (r *rawRecord) Domain() string                    { return r.domain }
+func (r *rawRecord) Codec() []byte                     { return r.codec }
+func (r *rawRecord) MarshalRecord() ([]byte, error)    { return r.payload, nil }
+func (r *rawRecord) UnmarshalRecord(data []byte) error { r.payload = data; return nil }
+
+// key derives a deterministic Ed25519 key from a seed string. Ed25519
+// signatures are deterministic, so the vectors are stable.
+func key(seed string) (crypto.PrivKey, error) {
+	s := sha256.Sum256([]byte(seed))
+	priv, _, err := crypto.GenerateEd25519Key(bytes.NewReader(s[:]))
+	return priv, err
+}
+
+func makeVector(name, seed string, rec *rawRecord, notBefore, expiration int64) (Vector, error) {
+	priv, err := key(seed)
+	if err != nil {
+		return Vector{}, err
+	}
+	var nbf, exp time.Time
+	if notBefore != 0 {
+		nbf = time.Unix(notBefore, 0)
+	}
+	if expiration != 0 {
+		exp = time.Unix(expiration, 0)
+	}
+	env, err := record.SealWithValidity(rec, priv, nbf, exp)
+	if err != nil {
+		return Vector{}, err
+	}
+	data, err := env.Marshal()
+	if err != nil {
+		return Vector{}, err
+	}
+	keyBytes, err := crypto.MarshalPrivateKey(priv)
+	if err != nil {
+		return Vector{}, err
+	}
+	input := record.SigningInput(rec.domain, rec.codec, rec.payload, nbf, exp)
+	sig, err := priv.Sign(input)
+	if err != nil {
+		return Vector{}, err
+	}
+	return Vector{
+		Name:         name,
+		PrivateKey:   keyBytes,
+		Domain:       rec.domain,
+		PayloadType:  rec.codec,
+		Payload:      rec.payload,
+		NotBefore:    notBefore,
+		Expiration:   expiration,
+		SigningInput: input,
+		Signature:    sig,
+		Envelope:     data,
+	}, nil
+}
+
+// Vectors returns the test vectors. They are deterministic.
+func Vectors() ([]Vector, error) {
+	peerPriv, err := key("peer record")
+	if err != nil {
+		return nil, err
+	}
+	peerID, err := peer.IDFromPrivateKey(peerPriv)
+	if err != nil {
+		return nil, err
+	}
+	peerRec := &peer.PeerRecord{
+		PeerID: peerID,
+		Seq:    1,
+		Addrs: []ma.Multiaddr{
+			ma.StringCast("/ip4/1.2.3.4/tcp/4001"),
+			ma.StringCast("/ip6/2001:db8::1/udp/4001/quic"),
+		},
+	}
+	peerPayload, err := peerRec.MarshalRecord()
+	if err != nil {
+		return nil, err
+	}
+	binary := make([]byte, 256)
+	for i := range binary {
+		binary[i] = byte(i)
+	}
+
+	cases := []struct {
+		name, seed      string
+		rec             *rawRecord
+		nbf, expiration int64
+	}{
+		{"simple", "simple", &rawRecord{"libp2p-testing", []byte("/libp2p/testdata"), []byte("hello world!")}, 0, 0},
+		{"empty payload", "empty payload", &rawRecord{"libp2p-testing", []byte("/libp2p/testdata"), nil}, 0, 0},
+		{"binary payload", "binary payload", &rawRecord{"libp2p-testing", []byte{0x03, 0x01}, binary}, 0, 0},
+		{"validity period", "validity period", &rawRecord{"libp2p-testing", []byte("/libp2p/testdata"), []byte("hello world!")}, 1600000000, 1700000000},
+		{"expiration only", "expiration only", &rawRecord{"libp2p-testing", []byte("/libp2p/testdata"), []byte("hello world!")}, 0, 1700000000},
+		{"peer record", "peer record", &rawRecord{peer.PeerRecordEnvelopeDomain, peer.PeerRecordEnvelopePayloadType, peerPayload}, 0, 0},
+	}
+	vectors := make([]Vector, 0, len(cases))
+	for _, c := range cases {
+		v, err := makeVector(c.name, c.seed, c.rec, c.nbf, c.expiration)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.name, err)
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+// WriteJSON writes the test vectors to w as an indented JSON array.
+func WriteJSON(w io.Writer) error {
+	vectors, err := Vectors()
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(vectors)
+}
+
+// Check checks this implementation against a test vector, e.g. one produced
+// by another implementation: the signing input and signature computed from
+// the vector's inputs must match the vector's, and its envelope must verify,
+// carry the same payload, and serialize back to the same bytes.
+func Check(v Vector) error {
+	priv, err := crypto.UnmarshalPrivateKey(v.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	var nbf, exp time.Time
+	if v.NotBefore != 0 {
+		nbf = time.Unix(v.NotBefore, 0)
+	}
+	if v.Expiration != 0 {
+		exp = time.Unix(v.Expiration, 0)
+	}
+
+	input := record.SigningInput(v.Domain, v.PayloadType, v.Payload, nbf, exp)
+	if !bytes.Equal(input, v.SigningInput) {
+		return errors.New("signing input mismatch")
+	}
+	if ok, err := priv.GetPublic().Verify(input, v.Signature); err != nil || !ok {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	if priv.Type() == crypto.Ed25519 {
+		sig, err := priv.Sign(input)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(sig, v.Signature) {
+			return errors.New("signature mismatch")
+		}
+	}
+
+	env, err := record.UnmarshalEnvelope(v.Envelope)
+	if err != nil {
+		return fmt.Errorf("invalid envelope: %w", err)
+	}
+	// vectors may have expired, so verify them within their validity period
+	at := time.Now()
+	switch {
+	case !nbf.IsZero():
+		at = nbf
+	case !exp.IsZero():
+		at = exp
+	}
+	if _, err := env.VerifyWithAnyDomainAt([]string{v.Domain}, at); err != nil {
+		return fmt.Errorf("envelope doesn't verify: %w", err)
+	}
+	if !env.PublicKey.Equals(priv.GetPublic()) {
+		return errors.New("envelope public key mismatch")
+	}
+	if !bytes.Equal(env.PayloadType, v.PayloadType) {
+		return errors.New("envelope payload type mismatch")
+	}
+	want, err := CanonicalizePayload(v.PayloadType, v.Payload)
+	if err != nil {
+		return err
+	}
+	got, err := CanonicalizePayload(env.PayloadType, env.RawPayload)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		return errors.New("envelope payload mismatch")
+	}
+	if env.NotBefore.Unix() != nbf.Unix() || env.Expiration.Unix() != exp.Unix() {
+		return errors.New("envelope validity period mismatch")
+	}
+	data, err := env.Marshal()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, v.Envelope) {
+		return errors.New("envelope serialization mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package envelopetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the committed test vectors")
+
+var vectorsFile = filepath.Join("testdata", "vectors.json")
+
+func TestVectors(t *testing.T) {
+	vectors, err := Vectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Vectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vectors, again) {
+		t.Fatal("expected vectors to be deterministic")
+	}
+	for _, v := range vectors {
+		if err := Check(v); err != nil {
+			t.Errorf("%s: %s", v.Name, err)
+		}
+	}
+
+	tampered := vectors[0]
+	tampered.Payload = append(Hex(nil), tampered.Payload...)
+	tampered.Payload[0] ^= 1
+	if err := Check(tampered); err == nil {
+		t.Error("expected a tampered vector to fail")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(vectorsFile, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the vectors published to other implementations must not change
+	committed, err := os.ReadFile(vectorsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), committed) {
+		t.Fatalf("vectors differ from %s; if the change is intended, run the tests with -update", vectorsFile)
+	}
+
+	var vectors []Vector
+	if err := json.Unmarshal(committed, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatalf("no vectors in %s", vectorsFile)
+	}
+	for _, v := range vectors {
+		if err := Check(v); err != nil {
+			t.Errorf("%s: %s", v.Name, err)
+		}
+	}
+}
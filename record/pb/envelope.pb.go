@@ -23,6 +23,38 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+// Compression is a payload compression algorithm.
+type Compression int32
+
+const (
+	Compression_NONE    Compression = 0
+	Compression_DEFLATE Compression = 1
+	Compression_ZSTD    Compression = 2
+	Compression_SNAPPY  Compression = 3
+)
+
+var Compression_name = map[int32]string{
+	0: "NONE",
+	1: "DEFLATE",
+	2: "ZSTD",
+	3: "SNAPPY",
+}
+
+var Compression_value = map[string]int32{
+	"NONE":    0,
+	"DEFLATE": 1,
+	"ZSTD":    2,
+	"SNAPPY":  3,
+}
+
+func (x Compression) String() string {
+	return proto.EnumName(Compression_name, int32(x))
+}
+
+func (Compression) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ee266e8c558e9dc5, []int{0}
+}
+
 // Envelope encloses a signed payload produced by a peer, along with the public
 // key of the keypair it was signed with so that it can be statelessly validated
 // by the receiver.
@@ -43,6 +75,28 @@ type Envelope struct {
 	// the enclosed public key, over the payload, prefixing a domain string for
 	// additional security.
 	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// not_before is the time, in seconds since the Unix epoch, before which
+	// the envelope must not be accepted. Zero means no lower bound.
+	NotBefore uint64 `protobuf:"varint,6,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	// expiration is the time, in seconds since the Unix epoch, after which
+	// the envelope must not be accepted. Zero means no expiry.
+	//
+	// When either not_before or expiration is set, both are covered by the
+	// signature.
+	Expiration uint64 `protobuf:"varint,7,opt,name=expiration,proto3" json:"expiration,omitempty"`
+	// delegations is the chain of signed KeyDelegation envelopes, starting
+	// at the root identity key, through which the signer of this envelope
+	// was authorized. It is empty if the envelope is signed by the identity
+	// key itself. The chain is not covered by the signature, as each link is
+	// signed on its own.
+	Delegations [][]byte `protobuf:"bytes,8,rep,name=delegations,proto3" json:"delegations,omitempty"`
+	// detached is set when the payload was left out of the envelope, to be
+	// distributed separately. The signature still covers the payload, so it
+	// must be supplied to verify the envelope.
+	Detached bool `protobuf:"varint,9,opt,name=detached,proto3" json:"detached,omitempty"`
+	// compression is the algorithm the payload is compressed with. The
+	// signature covers the uncompressed payload.
+	Compression Compression `protobuf:"varint,10,opt,name=compression,proto3,enum=record.pb.Compression" json:"compression,omitempty"`
 }
 
 func (m *Envelope) Reset()         { *m = Envelope{} }
@@ -106,27 +160,73 @@ func (m *Envelope) GetSignature() []byte {
 	return nil
 }
 
+func (m *Envelope) GetNotBefore() uint64 {
+	if m != nil {
+		return m.NotBefore
+	}
+	return 0
+}
+
+func (m *Envelope) GetExpiration() uint64 {
+	if m != nil {
+		return m.Expiration
+	}
+	return 0
+}
+
+func (m *Envelope) GetDelegations() [][]byte {
+	if m != nil {
+		return m.Delegations
+	}
+	return nil
+}
+
+func (m *Envelope) GetDetached() bool {
+	if m != nil {
+		return m.Detached
+	}
+	return false
+}
+
+func (m *Envelope) GetCompression() Compression {
+	if m != nil {
+		return m.Compression
+	}
+	return Compression_NONE
+}
+
 func init() {
+	proto.RegisterEnum("record.pb.Compression", Compression_name, Compression_value)
 	proto.RegisterType((*Envelope)(nil), "record.pb.Envelope")
 }
 
 func init() { proto.RegisterFile("envelope.proto", fileDescriptor_ee266e8c558e9dc5) }
 
 var fileDescriptor_ee266e8c558e9dc5 = []byte{
-	// 205 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4b, 0xcd, 0x2b, 0x4b,
-	0xcd, 0xc9, 0x2f, 0x48, 0xd5, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x2c, 0x4a, 0x4d, 0xce,
-	0x2f, 0x4a, 0xd1, 0x2b, 0x48, 0x92, 0x12, 0x4b, 0x2e, 0xaa, 0x2c, 0x28, 0xc9, 0xd7, 0x2f, 0x48,
-	0xd2, 0x87, 0xb0, 0x20, 0x4a, 0x94, 0x66, 0x31, 0x72, 0x71, 0xb8, 0x42, 0x75, 0x09, 0x19, 0x73,
-	0x71, 0x15, 0x94, 0x26, 0xe5, 0x64, 0x26, 0xc7, 0x67, 0xa7, 0x56, 0x4a, 0x30, 0x2a, 0x30, 0x6a,
-	0x70, 0x1b, 0x89, 0xe8, 0xc1, 0xd4, 0x27, 0xe9, 0x05, 0x80, 0x25, 0xbd, 0x53, 0x2b, 0x83, 0x38,
-	0x0b, 0x60, 0x4c, 0x21, 0x45, 0x2e, 0x9e, 0x82, 0xc4, 0xca, 0x9c, 0xfc, 0xc4, 0x94, 0xf8, 0x92,
-	0xca, 0x82, 0x54, 0x09, 0x26, 0x05, 0x46, 0x0d, 0x9e, 0x20, 0x6e, 0xa8, 0x58, 0x48, 0x65, 0x41,
-	0xaa, 0x90, 0x04, 0x17, 0x3b, 0x94, 0x2b, 0xc1, 0x0c, 0x96, 0x85, 0x71, 0x85, 0x64, 0xb8, 0x38,
-	0x8b, 0x33, 0xd3, 0xf3, 0x12, 0x4b, 0x4a, 0x8b, 0x52, 0x25, 0x58, 0xc1, 0x72, 0x08, 0x01, 0x27,
-	0x89, 0x13, 0x8f, 0xe4, 0x18, 0x2f, 0x3c, 0x92, 0x63, 0x7c, 0xf0, 0x48, 0x8e, 0x71, 0xc2, 0x63,
-	0x39, 0x86, 0x0b, 0x8f, 0xe5, 0x18, 0x6e, 0x3c, 0x96, 0x63, 0x48, 0x62, 0x03, 0xbb, 0xde, 0x18,
-	0x10, 0x00, 0x00, 0xff, 0xff, 0xaa, 0x0b, 0xd9, 0x6d, 0xf2, 0x00, 0x00, 0x00,
+	// 353 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x91, 0xbb, 0x8e, 0xda, 0x40,
+	0x14, 0x86, 0x3d, 0x40, 0xc0, 0x3e, 0x46, 0xc8, 0x1a, 0x45, 0x68, 0x84, 0x12, 0xcb, 0x49, 0x65,
+	0xa5, 0x30, 0x12, 0x34, 0x51, 0x3a, 0x08, 0x4e, 0x93, 0x88, 0x20, 0x43, 0x93, 0x34, 0xc8, 0x97,
+	0x13, 0x62, 0xc5, 0xf1, 0x8c, 0xc6, 0xc3, 0x6a, 0xfd, 0x16, 0xfb, 0x58, 0x5b, 0xb2, 0xdd, 0x96,
+	0x2b, 0x78, 0x91, 0x15, 0xe6, 0xe6, 0xee, 0xfc, 0xdf, 0xf7, 0xcf, 0x68, 0x34, 0x07, 0x7a, 0x98,
+	0xdf, 0x61, 0xc6, 0x05, 0x7a, 0x42, 0x72, 0xc5, 0xa9, 0x21, 0x31, 0xe6, 0x32, 0xf1, 0x44, 0x34,
+	0xe8, 0xc7, 0xb2, 0x14, 0x8a, 0x0f, 0x45, 0x34, 0x3c, 0x4d, 0xa7, 0xca, 0xc7, 0xa7, 0x06, 0xe8,
+	0xfe, 0xf9, 0x14, 0x1d, 0x03, 0x88, 0x6d, 0x94, 0xa5, 0xf1, 0xfa, 0x1f, 0x96, 0x8c, 0x38, 0xc4,
+	0x35, 0x47, 0x6f, 0xbd, 0x4b, 0x3f, 0xf2, 0x16, 0x95, 0xfc, 0x8e, 0x65, 0x60, 0x88, 0xcb, 0x48,
+	0x3f, 0x40, 0x57, 0x84, 0x65, 0xc6, 0xc3, 0x64, 0xad, 0x4a, 0x81, 0xac, 0xe1, 0x10, 0xb7, 0x1b,
+	0x98, 0x67, 0xb6, 0x2a, 0x05, 0x52, 0x06, 0x9d, 0x73, 0x64, 0xcd, 0xca, 0x5e, 0x22, 0x7d, 0x07,
+	0x46, 0x91, 0x6e, 0xf2, 0x50, 0x6d, 0x25, 0xb2, 0x37, 0x95, 0xbb, 0x01, 0xfa, 0x1e, 0x20, 0xe7,
+	0x6a, 0x1d, 0xe1, 0x1f, 0x2e, 0x91, 0xb5, 0x1d, 0xe2, 0xb6, 0x02, 0x23, 0xe7, 0x6a, 0x5a, 0x01,
+	0x6a, 0x03, 0xe0, 0xbd, 0x48, 0x65, 0xa8, 0x52, 0x9e, 0xb3, 0x4e, 0xa5, 0x6b, 0x84, 0x3a, 0x60,
+	0x26, 0x98, 0xe1, 0xa6, 0x4a, 0x05, 0xd3, 0x9d, 0xe6, 0xf1, 0x61, 0x35, 0x44, 0x07, 0xa0, 0x27,
+	0xa8, 0xc2, 0xf8, 0x2f, 0x26, 0xcc, 0x70, 0x88, 0xab, 0x07, 0xd7, 0x4c, 0x3f, 0x83, 0x19, 0xf3,
+	0xff, 0x42, 0x62, 0x51, 0x1c, 0xaf, 0x07, 0x87, 0xb8, 0xbd, 0x51, 0xdf, 0xbb, 0x7e, 0xa9, 0xf7,
+	0xf5, 0x66, 0x83, 0x7a, 0xf5, 0xd3, 0x17, 0x30, 0x6b, 0x8e, 0xea, 0xd0, 0x9a, 0xff, 0x9c, 0xfb,
+	0x96, 0x46, 0x4d, 0xe8, 0xcc, 0xfc, 0x6f, 0x3f, 0x26, 0x2b, 0xdf, 0x22, 0x47, 0xfc, 0x7b, 0xb9,
+	0x9a, 0x59, 0x0d, 0x0a, 0xd0, 0x5e, 0xce, 0x27, 0x8b, 0xc5, 0x2f, 0xab, 0x39, 0x65, 0x8f, 0x7b,
+	0x9b, 0xec, 0xf6, 0x36, 0x79, 0xd9, 0xdb, 0xe4, 0xe1, 0x60, 0x6b, 0xbb, 0x83, 0xad, 0x3d, 0x1f,
+	0x6c, 0x2d, 0x6a, 0x57, 0x0b, 0x1b, 0xbf, 0x0e, 0x00, 0xdc, 0xb8, 0xbb, 0x83, 0xe5, 0x01, 0x00,
+	0x00,
 }
 
 func (m *Envelope) Marshal() (dAtA []byte, err error) {
@@ -149,6 +249,40 @@ func (m *Envelope) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Compression != 0 {
+		i = encodeVarintEnvelope(dAtA, i, uint64(m.Compression))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.Detached {
+		i--
+		if m.Detached {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x48
+	}
+	if len(m.Delegations) > 0 {
+		for iNdEx := len(m.Delegations) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Delegations[iNdEx])
+			copy(dAtA[i:], m.Delegations[iNdEx])
+			i = encodeVarintEnvelope(dAtA, i, uint64(len(m.Delegations[iNdEx])))
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if m.Expiration != 0 {
+		i = encodeVarintEnvelope(dAtA, i, uint64(m.Expiration))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.NotBefore != 0 {
+		i = encodeVarintEnvelope(dAtA, i, uint64(m.NotBefore))
+		i--
+		dAtA[i] = 0x30
+	}
 	if len(m.Signature) > 0 {
 		i -= len(m.Signature)
 		copy(dAtA[i:], m.Signature)
@@ -218,6 +352,24 @@ func (m *Envelope) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovEnvelope(uint64(l))
 	}
+	if m.NotBefore != 0 {
+		n += 1 + sovEnvelope(uint64(m.NotBefore))
+	}
+	if m.Expiration != 0 {
+		n += 1 + sovEnvelope(uint64(m.Expiration))
+	}
+	if len(m.Delegations) > 0 {
+		for _, b := range m.Delegations {
+			l = len(b)
+			n += 1 + l + sovEnvelope(uint64(l))
+		}
+	}
+	if m.Detached {
+		n += 2
+	}
+	if m.Compression != 0 {
+		n += 1 + sovEnvelope(uint64(m.Compression))
+	}
 	return n
 }
 
@@ -394,6 +546,115 @@ func (m *Envelope) Unmarshal(dAtA []byte) error {
 				m.Signature = []byte{}
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NotBefore", wireType)
+			}
+			m.NotBefore = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NotBefore |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expiration", wireType)
+			}
+			m.Expiration = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Expiration |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delegations", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Delegations = append(m.Delegations, make([]byte, postIndex-iNdEx))
+			copy(m.Delegations[len(m.Delegations)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Detached", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Detached = bool(v != 0)
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			m.Compression = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Compression |= Compression(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipEnvelope(dAtA[iNdEx:])
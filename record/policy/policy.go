@@ -0,0 +1,201 @@
+// Package policy implements a trust policy engine for envelope-based records.
+//
+// A Policy is an ordered list of Rules that is consulted whenever a signed
+// record.Envelope is consumed. Each rule inspects the envelope and the context
+// it was received in (the signer's key type, the delegation chain, the record
+// domain and age, and the security of the connection it arrived on) and
+// returns a Decision. The decisions of all rules are combined, and the full
+// reasoning can be retrieved via Explain, so that trust decisions are made in
+// one place and can be audited.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/record"
+)
+
+// ErrDenied is returned by Policy.ConsumeEnvelope when the policy denies an
+// envelope.
+var ErrDenied = errors.New("record denied by trust policy")
+
+// Decision is the outcome of evaluating a rule or a policy.
+type Decision int
+
+const (
+	// Abstain means that the rule doesn't apply to the input. A policy where
+	// all rules abstain returns its default decision.
+	Abstain Decision = iota
+	// Allow means that the record can be trusted.
+	Allow
+	// Flag means that the record can be used, but is suspicious and should be
+	// logged or surfaced to the operator.
+	Flag
+	// Deny means that the record must not be trusted.
+	Deny
+)
+
+func (d Decision) String() string {
+	str := [...]string{"Abstain", "Allow", "Flag", "Deny"}
+	if d < 0 || int(d) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[d]
+}
+
+// SecurityLevel describes the security of the connection a record was
+// received on.
+type SecurityLevel int
+
+const (
+	// SecurityUnknown is used when the origin of the record is not known.
+	SecurityUnknown SecurityLevel = iota
+	// SecurityNone is used for records received over unauthenticated and
+	// unencrypted connections.
+	SecurityNone
+	// SecurityAuthenticated is used for records received over a secure
+	// channel with an authenticated remote peer.
+	SecurityAuthenticated
+	// SecurityLocal is used for records that originate from the local node.
+	SecurityLocal
+)
+
+func (l SecurityLevel) String() string {
+	str := [...]string{"Unknown", "None", "Authenticated", "Local"}
+	if l < 0 || int(l) >= len(str) {
+		return "(unrecognized)"
+	}
+	return str[l]
+}
+
+// Input is the information a policy is evaluated against.
+type Input struct {
+	// Envelope is the validated envelope being consumed.
+	Envelope *record.Envelope
+
+	// Domain is the domain the envelope was validated with.
+	Domain string
+
+	// Delegation is the chain of keys through which authority was delegated
+	// to the envelope's signer, starting at the root. It is empty if the
+	// signer acts on its own behalf.
+	Delegation []crypto.PubKey
+
+	// Created is the time the record was created, if known.
+	Created time.Time
+
+	// Received is the time the record was received. If zero, the time of
+	// evaluation is used.
+	Received time.Time
+
+	// Security is the security level of the connection the record was
+	// received on.
+	Security SecurityLevel
+}
+
+// Rule is a single trust rule.
+type Rule interface {
+	// Name returns a short identifier for the rule, used in explanations.
+	Name() string
+
+	// Evaluate returns the rule's decision for the given input, along with a
+	// human readable reason.
+	Evaluate(in *Input) (Decision, string)
+}
+
+// Step is the result of evaluating a single rule.
+type Step struct {
+	Rule     string
+	Decision Decision
+	Reason   string
+}
+
+// Explanation describes how a policy reached a decision.
+type Explanation struct {
+	// Decision is the combined decision of the policy.
+	Decision Decision
+
+	// Steps are the decisions of the individual rules, in evaluation order.
+	Steps []Step
+}
+
+func (e *Explanation) String() string {
+	var b strings.Builder
+	b.WriteString(e.Decision.String())
+	for _, s := range e.Steps {
+		fmt.Fprintf(&b, "\n  %s: %s (%s)", s.Rule, s.Decision, s.Reason)
+	}
+	return b.String()
+}
+
+// Policy is an ordered list of rules.
+//
+// The decision of a policy is the most severe decision returned by any of its
+// rules, where Deny is more severe than Flag, which is more severe than Allow.
+// If all rules abstain, the policy returns Default.
+type Policy struct {
+	Rules []Rule
+
+	// Default is the decision returned when all rules abstain. The zero value
+	// (Abstain) is treated as Allow.
+	Default Decision
+}
+
+// New constructs a policy with the given rules, that allows records when no
+// rule applies.
+func New(rules ...Rule) *Policy {
+	return &Policy{Rules: rules, Default: Allow}
+}
+
+// Evaluate returns the decision of the policy for the given input.
+func (p *Policy) Evaluate(in *Input) Decision {
+	return p.Explain(in).Decision
+}
+
+// Explain evaluates all rules against the given input, and returns the
+// combined decision along with the decision of each rule.
+func (p *Policy) Explain(in *Input) *Explanation {
+	exp := &Explanation{Steps: make([]Step, 0, len(p.Rules))}
+	for _, r := range p.Rules {
+		d, reason := r.Evaluate(in)
+		exp.Steps = append(exp.Steps, Step{Rule: r.Name(), Decision: d, Reason: reason})
+		if d > exp.Decision {
+			exp.Decision = d
+		}
+	}
+	if exp.Decision == Abstain {
+		exp.Decision = p.Default
+		if exp.Decision == Abstain {
+			exp.Decision = Allow
+		}
+	}
+	return exp
+}
+
+// ConsumeEnvelope consumes an envelope like record.ConsumeEnvelope, and then
+// evaluates the policy against it. The Envelope and Domain fields of in are
+// filled in automatically, as is the Delegation field if it is empty.
+//
+// If the policy denies the envelope, an error wrapping ErrDenied is returned
+// along with the envelope and the explanation. Flagged envelopes are returned
+// without an error; callers should inspect the explanation's Decision.
+func (p *Policy) ConsumeEnvelope(data []byte, domain string, in Input) (*record.Envelope, record.Record, *Explanation, error) {
+	env, rec, err := record.ConsumeEnvelope(data, domain)
+	if err != nil {
+		return env, rec, nil, err
+	}
+	in.Envelope = env
+	in.Domain = domain
+	if len(in.Delegation) == 0 {
+		in.Delegation = env.Delegation()
+	}
+	exp := p.Explain(&in)
+	if exp.Decision == Deny {
+		return env, nil, exp, fmt.Errorf("%w: %s", ErrDenied, exp)
+	}
+	return env, rec, exp, nil
+}
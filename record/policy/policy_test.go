@@ -0,0 +1,97 @@
+package policy_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	. "github.com/libp2p/go-libp2p-core/record/policy"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func sealedPeerRecord(t *testing.T, typ int) []byte {
+	t.Helper()
+	priv, _, err := test.RandTestKeyPair(typ, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := record.Seal(&peer.PeerRecord{PeerID: id, Seq: 1}, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := env.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestPolicyDefault(t *testing.T) {
+	if d := New().Evaluate(&Input{}); d != Allow {
+		t.Fatalf("expected empty policy to allow, got %s", d)
+	}
+	p := &Policy{Default: Deny}
+	if d := p.Evaluate(&Input{}); d != Deny {
+		t.Fatalf("expected default decision, got %s", d)
+	}
+}
+
+func TestPolicyMostSevereWins(t *testing.T) {
+	now := time.Now()
+	p := New(
+		MinSecurity(SecurityAuthenticated, Flag),
+		MaxAge(time.Hour, Deny),
+		MaxDelegationDepth(1),
+	)
+	in := &Input{Security: SecurityNone, Created: now.Add(-time.Minute), Received: now}
+	exp := p.Explain(in)
+	if exp.Decision != Flag {
+		t.Fatalf("expected Flag, got %s", exp)
+	}
+	if len(exp.Steps) != 3 || exp.Steps[0].Decision != Flag || exp.Steps[1].Decision != Allow {
+		t.Fatalf("unexpected steps: %s", exp)
+	}
+
+	in.Created = now.Add(-2 * time.Hour)
+	if d := p.Evaluate(in); d != Deny {
+		t.Fatalf("expected Deny for an old record, got %s", d)
+	}
+
+	in.Created = time.Time{}
+	in.Delegation = make([]crypto.PubKey, 2)
+	exp = p.Explain(in)
+	if exp.Decision != Deny || exp.Steps[1].Decision != Abstain {
+		t.Fatalf("expected Deny for a long delegation chain, got %s", exp)
+	}
+}
+
+func TestConsumeEnvelope(t *testing.T) {
+	p := New(KeyTypes(pb.KeyType_Ed25519), Domains(peer.PeerRecordEnvelopeDomain))
+
+	env, rec, exp, err := p.ConsumeEnvelope(sealedPeerRecord(t, crypto.Ed25519), peer.PeerRecordEnvelopeDomain, Input{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env == nil || exp.Decision != Allow {
+		t.Fatalf("expected envelope to be allowed, got %s", exp)
+	}
+	if _, ok := rec.(*peer.PeerRecord); !ok {
+		t.Fatalf("expected a peer record, got %T", rec)
+	}
+
+	_, rec, exp, err = p.ConsumeEnvelope(sealedPeerRecord(t, crypto.Secp256k1), peer.PeerRecordEnvelopeDomain, Input{})
+	if !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected ErrDenied, got %v", err)
+	}
+	if rec != nil || exp.Decision != Deny {
+		t.Fatalf("expected denied envelope, got %s", exp)
+	}
+}
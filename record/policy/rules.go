@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/libp2p/go-libp2p-core/crypto/pb"
+)
+
+// RuleFunc adapts a function to the Rule interface.
+type RuleFunc struct {
+	RuleName string
+	Func     func(in *Input) (Decision, string)
+}
+
+var _ Rule = RuleFunc{}
+
+func (r RuleFunc) Name() string {
+	return r.RuleName
+}
+
+func (r RuleFunc) Evaluate(in *Input) (Decision, string) {
+	return r.Func(in)
+}
+
+// KeyTypes returns a rule that denies envelopes signed with a key whose type
+// is not in the given list.
+func KeyTypes(types ...pb.KeyType) Rule {
+	return RuleFunc{
+		RuleName: "key-type",
+		Func: func(in *Input) (Decision, string) {
+			if in.Envelope == nil || in.Envelope.PublicKey == nil {
+				return Deny, "no signer key"
+			}
+			typ := in.Envelope.PublicKey.Type()
+			for _, t := range types {
+				if t == typ {
+					return Allow, fmt.Sprintf("key type %s is allowed", typ)
+				}
+			}
+			return Deny, fmt.Sprintf("key type %s is not allowed", typ)
+		},
+	}
+}
+
+// Domains returns a rule that denies envelopes whose domain is not in the
+// given list.
+func Domains(domains ...string) Rule {
+	return RuleFunc{
+		RuleName: "domain",
+		Func: func(in *Input) (Decision, string) {
+			for _, d := range domains {
+				if d == in.Domain {
+					return Allow, fmt.Sprintf("domain %q is allowed", in.Domain)
+				}
+			}
+			return Deny, fmt.Sprintf("domain %q is not allowed", in.Domain)
+		},
+	}
+}
+
+// MaxAge returns a rule that returns the given decision for records created
+// more than maxAge before they were received. The rule abstains if the
+// creation time of the record is unknown.
+func MaxAge(maxAge time.Duration, exceeded Decision) Rule {
+	return RuleFunc{
+		RuleName: "max-age",
+		Func: func(in *Input) (Decision, string) {
+			if in.Created.IsZero() {
+				return Abstain, "creation time unknown"
+			}
+			received := in.Received
+			if received.IsZero() {
+				received = time.Now()
+			}
+			age := received.Sub(in.Created)
+			if age > maxAge {
+				return exceeded, fmt.Sprintf("age %s exceeds %s", age, maxAge)
+			}
+			return Allow, fmt.Sprintf("age %s within %s", age, maxAge)
+		},
+	}
+}
+
+// MinSecurity returns a rule that returns the given decision for records
+// received on a connection with a security level lower than min.
+func MinSecurity(min SecurityLevel, below Decision) Rule {
+	return RuleFunc{
+		RuleName: "min-security",
+		Func: func(in *Input) (Decision, string) {
+			if in.Security < min {
+				return below, fmt.Sprintf("security %s below %s", in.Security, min)
+			}
+			return Allow, fmt.Sprintf("security %s", in.Security)
+		},
+	}
+}
+
+// MaxDelegationDepth returns a rule that denies records whose delegation
+// chain is longer than depth.
+func MaxDelegationDepth(depth int) Rule {
+	return RuleFunc{
+		RuleName: "max-delegation-depth",
+		Func: func(in *Input) (Decision, string) {
+			if n := len(in.Delegation); n > depth {
+				return Deny, fmt.Sprintf("delegation depth %d exceeds %d", n, depth)
+			}
+			return Allow, fmt.Sprintf("delegation depth %d", len(in.Delegation))
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package record
+
+import (
+	"time"
+
+	pool "github.com/libp2p/go-buffer-pool"
+)
+
+// SigningInput returns the bytes signed when sealing an Envelope, for
+// implementations in other languages and test vectors: the domain, the
+// payload type and the payload, each prefixed with its length as an unsigned
+// varint, followed, if a validity period is set, by the not-before and
+// expiration times in Unix seconds, each encoded as an unsigned varint and
+// length-prefixed in the same way. Times are truncated to whole seconds, and
+// zero times are encoded as 0.
+func SigningInput(domain string, payloadType, payload []byte, notBefore, expiration time.Time) []byte {
+	unsigned, _ := makeUnsigned(domain, payloadType, payload, toUnixSeconds(notBefore), toUnixSeconds(expiration))
+	defer pool.Put(unsigned)
+	return append([]byte(nil), unsigned...)
+}
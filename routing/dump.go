@@ -0,0 +1,164 @@
+package routing
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+)
+
+// DumpFormat is the output format of DumpSignedRoutingState.
+type DumpFormat int
+
+const (
+	// DumpText is a human-readable format, for terminals.
+	DumpText DumpFormat = iota
+	// DumpJSON is an indented JSON object, for tools.
+	DumpJSON
+)
+
+// seqTimestampMin is the smallest sequence number interpreted as a timestamp
+// (2001-09-09), as sequence numbers from peer.TimestampSeq are nanoseconds
+// since the Unix epoch.
+const seqTimestampMin = 1e18
+
+type routingStateDump struct {
+	Peer         string     `json:"peer,omitempty"`
+	Seq          *uint64    `json:"seq,omitempty"`
+	SeqTime      *time.Time `json:"seqTime,omitempty"`
+	Addrs        []string   `json:"addrs,omitempty"`
+	Signer       string     `json:"signer"`
+	KeyType      string     `json:"keyType"`
+	Issuer       string     `json:"issuer,omitempty"`
+	PayloadType  string     `json:"payloadType"`
+	NotBefore    *time.Time `json:"notBefore,omitempty"`
+	Expiration   *time.Time `json:"expiration,omitempty"`
+	EnvelopeSize int        `json:"envelopeSize"`
+	PayloadSize  int        `json:"payloadSize"`
+}
+
+// DumpSignedRoutingState writes a description of a signed routing record to w,
+// so that debugging tools and commands print records consistently. The
+// description includes the peer, the sequence number (along with the time it
+// stands for, if it is timestamp-based), the addresses, the signer and its key
+// type, the validity period and the sizes of the envelope and the payload.
+//
+// The peer, sequence number and addresses are only included for peer records
+// (see peer.AsPeerRecord). The envelope isn't verified; it should come from
+// record.ConsumeEnvelope unless the point is to inspect an invalid record.
+func DumpSignedRoutingState(w io.Writer, env *record.Envelope, format DumpFormat) error {
+	d, err := newRoutingStateDump(env)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case DumpText:
+		return d.writeText(w)
+	case DumpJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(d)
+	default:
+		return fmt.Errorf("unknown dump format %d", format)
+	}
+}
+
+func newRoutingStateDump(env *record.Envelope) (*routingStateDump, error) {
+	data, err := env.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	d := &routingStateDump{
+		KeyType:      env.PublicKey.Type().String(),
+		PayloadType:  formatPayloadType(env.PayloadType),
+		EnvelopeSize: len(data),
+		PayloadSize:  len(env.RawPayload),
+	}
+
+	signer, err := peer.IDFromPublicKey(env.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	d.Signer = signer.String()
+	if issuer := env.Issuer(); !issuer.Equals(env.PublicKey) {
+		id, err := peer.IDFromPublicKey(issuer)
+		if err != nil {
+			return nil, err
+		}
+		d.Issuer = id.String()
+	}
+
+	if !env.NotBefore.IsZero() {
+		nbf := env.NotBefore.UTC()
+		d.NotBefore = &nbf
+	}
+	if !env.Expiration.IsZero() {
+		exp := env.Expiration.UTC()
+		d.Expiration = &exp
+	}
+
+	if rec, err := env.Record(); err == nil {
+		if pr, ok := peer.AsPeerRecord(rec); ok {
+			d.Peer = pr.PeerID.String()
+			seq := pr.Seq
+			d.Seq = &seq
+			if seq >= seqTimestampMin {
+				t := time.Unix(0, int64(seq)).UTC()
+				d.SeqTime = &t
+			}
+			d.Addrs = make([]string, 0, len(pr.Addrs))
+			for _, a := range pr.Addrs {
+				d.Addrs = append(d.Addrs, a.String())
+			}
+		}
+	}
+	return d, nil
+}
+
+// formatPayloadType returns path-like payload types as is, and others, such
+// as multicodecs, in hexadecimal.
+func formatPayloadType(pt []byte) string {
+	for _, c := range pt {
+		if c < 0x20 || c > 0x7e {
+			return "0x" + hex.EncodeToString(pt)
+		}
+	}
+	return string(pt)
+}
+
+func (d *routingStateDump) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if d.Peer != "" {
+		fmt.Fprintf(tw, "peer:\t%s\n", d.Peer)
+	}
+	if d.Seq != nil {
+		if d.SeqTime != nil {
+			fmt.Fprintf(tw, "seq:\t%d (%s)\n", *d.Seq, d.SeqTime.Format(time.RFC3339Nano))
+		} else {
+			fmt.Fprintf(tw, "seq:\t%d\n", *d.Seq)
+		}
+		fmt.Fprintf(tw, "addresses:\t%d\n", len(d.Addrs))
+		for _, a := range d.Addrs {
+			fmt.Fprintf(tw, "\t%s\n", a)
+		}
+	}
+	fmt.Fprintf(tw, "signer:\t%s (%s)\n", d.Signer, d.KeyType)
+	if d.Issuer != "" {
+		fmt.Fprintf(tw, "issuer:\t%s\n", d.Issuer)
+	}
+	fmt.Fprintf(tw, "payload type:\t%s\n", d.PayloadType)
+	if d.NotBefore != nil {
+		fmt.Fprintf(tw, "not before:\t%s\n", d.NotBefore.Format(time.RFC3339))
+	}
+	if d.Expiration != nil {
+		fmt.Fprintf(tw, "expiration:\t%s\n", d.Expiration.Format(time.RFC3339))
+	}
+	fmt.Fprintf(tw, "envelope size:\t%d bytes\n", d.EnvelopeSize)
+	fmt.Fprintf(tw, "payload size:\t%d bytes\n", d.PayloadSize)
+	return tw.Flush()
+}
@@ -0,0 +1,67 @@
+package routing
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestDumpSignedRoutingState(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seqTime := time.Date(2022, 4, 1, 12, 0, 0, 0, time.UTC)
+	rec := &peer.PeerRecord{PeerID: id, Addrs: test.GenerateTestAddrs(2), Seq: uint64(seqTime.UnixNano())}
+	env, err := record.SealWithValidity(rec, priv, time.Time{}, seqTime.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := DumpSignedRoutingState(&buf, env, DumpText); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	for _, s := range []string{
+		id.String(),
+		"2022-04-01T12:00:00Z",
+		"/ip4/1.2.3.4/tcp/1",
+		"(Ed25519)",
+		"0x0301",
+		"expiration:",
+	} {
+		if !strings.Contains(text, s) {
+			t.Errorf("expected %q in dump:\n%s", s, text)
+		}
+	}
+
+	buf.Reset()
+	if err := DumpSignedRoutingState(&buf, env, DumpJSON); err != nil {
+		t.Fatal(err)
+	}
+	var d routingStateDump
+	if err := json.Unmarshal(buf.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Peer != id.String() || d.Signer != id.String() || d.Seq == nil || *d.Seq != rec.Seq {
+		t.Fatalf("unexpected JSON dump: %s", buf.String())
+	}
+	if len(d.Addrs) != 2 || d.NotBefore != nil || d.Expiration == nil || d.Issuer != "" {
+		t.Fatalf("unexpected JSON dump: %s", buf.String())
+	}
+	if d.PayloadSize != len(env.RawPayload) || d.EnvelopeSize <= d.PayloadSize {
+		t.Fatalf("unexpected sizes in JSON dump: %s", buf.String())
+	}
+}
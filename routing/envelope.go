@@ -0,0 +1,161 @@
+package routing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+)
+
+// EnvelopeNamespace is the ValueStore namespace of signed records, see
+// KeyForEnvelope.
+const EnvelopeNamespace = "envelopes"
+
+var (
+	// ErrInvalidEnvelopeKey is returned for malformed keys in the
+	// EnvelopeNamespace.
+	ErrInvalidEnvelopeKey = errors.New("routing: invalid envelope key")
+	// ErrEnvelopeKeyMismatch is returned when a signed record is stored
+	// under the key of another record type or peer.
+	ErrEnvelopeKeyMismatch = errors.New("routing: envelope does not match key")
+)
+
+// KeyForEnvelope returns the ValueStore key under which the signed records of
+// the given type, identified by the record's Codec, are stored for the given
+// peer:
+//
+//	/envelopes/<hex-encoded codec>/<binary peer ID>
+//
+// Each peer has a single record of each type in the keyspace.
+func KeyForEnvelope(codec []byte, p peer.ID) string {
+	return "/" + EnvelopeNamespace + "/" + hex.EncodeToString(codec) + "/" + string(p)
+}
+
+// ParseEnvelopeKey returns the record codec and peer of a key returned by
+// KeyForEnvelope.
+func ParseEnvelopeKey(key string) (codec []byte, p peer.ID, err error) {
+	prefix := "/" + EnvelopeNamespace + "/"
+	if !strings.HasPrefix(key, prefix) {
+		return nil, "", ErrInvalidEnvelopeKey
+	}
+	rest := key[len(prefix):]
+	i := strings.IndexByte(rest, '/')
+	if i <= 0 {
+		return nil, "", ErrInvalidEnvelopeKey
+	}
+	codec, err = hex.DecodeString(rest[:i])
+	if err != nil {
+		return nil, "", ErrInvalidEnvelopeKey
+	}
+	p, err = peer.IDFromBytes([]byte(rest[i+1:]))
+	if err != nil {
+		return nil, "", ErrInvalidEnvelopeKey
+	}
+	return codec, p, nil
+}
+
+// PutEnvelope stores a signed record in the ValueStore, under the key of its
+// type and signing peer. The signing peer is the issuer of the Envelope's
+// delegation chain, if any.
+func PutEnvelope(ctx context.Context, vs ValueStore, envelope *record.Envelope, opts ...Option) error {
+	p, err := peer.IDFromPublicKey(envelope.Issuer())
+	if err != nil {
+		return err
+	}
+	data, err := envelope.Bytes()
+	if err != nil {
+		return err
+	}
+	return vs.PutValue(ctx, KeyForEnvelope(envelope.PayloadType, p), data, opts...)
+}
+
+// GetEnvelope retrieves the signed record of the given type for the given
+// peer from the ValueStore, and validates it with an EnvelopeValidator.
+func GetEnvelope(ctx context.Context, vs ValueStore, codec []byte, p peer.ID, opts ...Option) (*record.Envelope, record.Record, error) {
+	key := KeyForEnvelope(codec, p)
+	data, err := vs.GetValue(ctx, key, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope, rec, err := consumeEnvelopeForKey(key, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return envelope, rec, nil
+}
+
+// EnvelopeValidator validates the values stored in the EnvelopeNamespace. It
+// implements the Validator interface of go-libp2p-record, so it can be
+// registered with DHT-compatible routers for the namespace.
+//
+// A value is valid if it is a serialized Envelope containing a record of a
+// registered type, signed in the record's domain by the peer of the key, with
+// the record type of the key, and within its validity period.
+type EnvelopeValidator struct{}
+
+// Validate returns nil if value is a valid signed record for key.
+func (EnvelopeValidator) Validate(key string, value []byte) error {
+	_, _, err := consumeEnvelopeForKey(key, value)
+	return err
+}
+
+// Select returns the index of the best of the given values for key: peer
+// records with the highest Seq, and otherwise the Envelope with the latest
+// NotBefore time. Invalid values are never selected.
+func (EnvelopeValidator) Select(key string, values [][]byte) (int, error) {
+	best := -1
+	var bestEnv *record.Envelope
+	var bestRec record.Record
+	for i, v := range values {
+		env, rec, err := consumeEnvelopeForKey(key, v)
+		if err != nil {
+			continue
+		}
+		if best < 0 || newerEnvelope(env, rec, bestEnv, bestRec) {
+			best, bestEnv, bestRec = i, env, rec
+		}
+	}
+	if best < 0 {
+		return 0, errors.New("routing: no valid envelope to select")
+	}
+	return best, nil
+}
+
+func newerEnvelope(a *record.Envelope, ar record.Record, b *record.Envelope, br record.Record) bool {
+	if apr, ok := peer.AsPeerRecord(ar); ok {
+		if bpr, ok := peer.AsPeerRecord(br); ok && apr.Seq != bpr.Seq {
+			return apr.Seq > bpr.Seq
+		}
+	}
+	return a.NotBefore.After(b.NotBefore)
+}
+
+func consumeEnvelopeForKey(key string, value []byte) (*record.Envelope, record.Record, error) {
+	codec, p, err := ParseEnvelopeKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	envelope, err := record.UnmarshalEnvelope(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(envelope.PayloadType) != string(codec) || !p.MatchesPublicKey(envelope.Issuer()) {
+		return nil, nil, ErrEnvelopeKeyMismatch
+	}
+	rec, err := envelope.Record()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal envelope payload: %w", err)
+	}
+	// a peer may only publish peer records about itself
+	if pr, ok := peer.AsPeerRecord(rec); ok && pr.PeerID != p {
+		return nil, nil, ErrEnvelopeKeyMismatch
+	}
+	if _, err := envelope.VerifyWithAnyDomain([]string{rec.Domain()}); err != nil {
+		return nil, nil, err
+	}
+	return envelope, rec, nil
+}
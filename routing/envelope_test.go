@@ -0,0 +1,85 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+func TestEnvelopeStorage(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(ci.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := peer.IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+
+	key := KeyForEnvelope(peer.PeerRecordEnvelopePayloadType, id)
+	codec, p, err := ParseEnvelopeKey(key)
+	test.AssertNilError(t, err)
+	if string(codec) != string(peer.PeerRecordEnvelopePayloadType) || p != id {
+		t.Fatalf("unexpected parse of %q: %x, %s", key, codec, p)
+	}
+	if _, _, err := ParseEnvelopeKey("/pk/" + string(id)); err != ErrInvalidEnvelopeKey {
+		t.Fatalf("expected ErrInvalidEnvelopeKey, got %v", err)
+	}
+
+	older, err := record.Seal(&peer.PeerRecord{PeerID: id, Addrs: test.GenerateTestAddrs(1), Seq: 1}, priv)
+	test.AssertNilError(t, err)
+	newer, err := record.Seal(&peer.PeerRecord{PeerID: id, Addrs: test.GenerateTestAddrs(2), Seq: 2}, priv)
+	test.AssertNilError(t, err)
+
+	vs := &mapValueStore{values: make(map[string][]byte)}
+	ctx := context.Background()
+	test.AssertNilError(t, PutEnvelope(ctx, vs, newer))
+	env, rec, err := GetEnvelope(ctx, vs, peer.PeerRecordEnvelopePayloadType, id)
+	test.AssertNilError(t, err)
+	if !env.Equal(newer) || rec.(*peer.PeerRecord).Seq != 2 {
+		t.Fatal("expected to get the stored peer record")
+	}
+
+	olderBytes, err := older.Marshal()
+	test.AssertNilError(t, err)
+	newerBytes, err := newer.Marshal()
+	test.AssertNilError(t, err)
+
+	var v EnvelopeValidator
+	test.AssertNilError(t, v.Validate(key, newerBytes))
+	i, err := v.Select(key, [][]byte{olderBytes, []byte("garbage"), newerBytes})
+	test.AssertNilError(t, err)
+	if i != 2 {
+		t.Fatalf("expected the record with the highest seq to be selected, got %d", i)
+	}
+
+	otherPriv, _, err := test.RandTestKeyPair(ci.Ed25519, 256)
+	test.AssertNilError(t, err)
+	otherID, err := peer.IDFromPrivateKey(otherPriv)
+	test.AssertNilError(t, err)
+	err = v.Validate(KeyForEnvelope(peer.PeerRecordEnvelopePayloadType, otherID), newerBytes)
+	if !errors.Is(err, ErrEnvelopeKeyMismatch) {
+		t.Fatalf("expected ErrEnvelopeKeyMismatch for another peer's key, got %v", err)
+	}
+	err = v.Validate(KeyForEnvelope(ProviderRecordEnvelopePayloadType, id), newerBytes)
+	if !errors.Is(err, ErrEnvelopeKeyMismatch) {
+		t.Fatalf("expected ErrEnvelopeKeyMismatch for another record type's key, got %v", err)
+	}
+
+	// a peer record about another peer, signed and published under the
+	// signer's own key
+	spoof, err := record.Seal(&peer.PeerRecord{PeerID: otherID, Addrs: test.GenerateTestAddrs(1), Seq: 3}, priv)
+	test.AssertNilError(t, err)
+	spoofBytes, err := spoof.Marshal()
+	test.AssertNilError(t, err)
+	err = v.Validate(key, spoofBytes)
+	if !errors.Is(err, ErrEnvelopeKeyMismatch) {
+		t.Fatalf("expected ErrEnvelopeKeyMismatch for a record about another peer, got %v", err)
+	}
+	i, err = v.Select(key, [][]byte{newerBytes, spoofBytes})
+	test.AssertNilError(t, err)
+	if i != 0 {
+		t.Fatalf("expected the spoofed record not to be selected, got %d", i)
+	}
+}
@@ -8,6 +8,15 @@ type Options struct {
 	// Allow expired values.
 	Expired bool
 	Offline bool
+
+	// Quorum is the number of peers that must agree on a value before a
+	// search is considered converged. Zero means the implementation default.
+	Quorum int
+
+	// OnBestValue, if set, is called once with the final best value when a
+	// SearchValue query completes.
+	OnBestValue func(value []byte)
+
 	// Other (ValueStore implementation specific) options.
 	Other map[interface{}]interface{}
 }
@@ -37,14 +46,41 @@ func (opts *Options) ToOption() Option {
 }
 
 // Expired is an option that tells the routing system to return expired records
-// when no newer records are known.
+// when no newer records are known. Without it, such lookups fail with
+// ErrExpired.
 var Expired Option = func(opts *Options) error {
 	opts.Expired = true
 	return nil
 }
 
 // Offline is an option that tells the routing system to operate offline (i.e., rely on cached/local data only).
+//
+// Operations that can't be satisfied from local data fail with ErrOffline
+// rather than ErrNotFound.
 var Offline Option = func(opts *Options) error {
 	opts.Offline = true
 	return nil
 }
+
+// Quorum is an option that tells SearchValue and GetValue to only consider a
+// search converged once n peers have returned the best value.
+func Quorum(n int) Option {
+	return func(opts *Options) error {
+		opts.Quorum = n
+		return nil
+	}
+}
+
+// OnBestValue is an option that registers a callback invoked once with the
+// final best value selected by SearchValue, after the search has converged and
+// before the result channel is closed. The callback is not invoked if no value
+// was found or the search was canceled.
+//
+// This lets callers act on the early results read from the channel while
+// still learning which value was eventually selected.
+func OnBestValue(f func(value []byte)) Option {
+	return func(opts *Options) error {
+		opts.OnBestValue = f
+		return nil
+	}
+}
@@ -0,0 +1,26 @@
+package routing
+
+import "testing"
+
+func TestSearchOptions(t *testing.T) {
+	var best []byte
+	var opts Options
+	if err := opts.Apply(Quorum(3), OnBestValue(func(v []byte) { best = v })); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Quorum != 3 {
+		t.Fatalf("expected quorum 3, got %d", opts.Quorum)
+	}
+
+	var copied Options
+	if err := copied.Apply(opts.ToOption()); err != nil {
+		t.Fatal(err)
+	}
+	if copied.Quorum != 3 || copied.OnBestValue == nil {
+		t.Fatal("expected options to be copied")
+	}
+	copied.OnBestValue([]byte("best"))
+	if string(best) != "best" {
+		t.Fatalf("expected callback to be invoked, got %q", best)
+	}
+}
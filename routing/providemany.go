@@ -0,0 +1,45 @@
+package routing
+
+import (
+	"context"
+
+	cid "github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+)
+
+// ProvideManyRouter is implemented by ContentRouting implementations that can
+// announce many keys at once more efficiently than with individual calls to
+// Provide, e.g. by batching them per closest peer.
+//
+// To test whether a given ContentRouting implementation supports batch
+// provides, type-assert on the ProvideManyRouter interface, or use the
+// ProvideMany helper which falls back to Provide.
+type ProvideManyRouter interface {
+	ContentRouting
+
+	// ProvideMany adds the given keys to the content routing system and
+	// announces them.
+	ProvideMany(ctx context.Context, keys []mh.Multihash) error
+
+	// Ready returns true if the router is ready to accept ProvideMany calls,
+	// e.g. once its routing table has been populated.
+	Ready() bool
+}
+
+// ProvideMany announces the given keys using r.ProvideMany if r is a
+// ProvideManyRouter, and by calling r.Provide for each key, wrapped in a raw
+// CIDv1, otherwise. In the latter case, it stops at the first error.
+func ProvideMany(ctx context.Context, r ContentRouting, keys []mh.Multihash) error {
+	if pmr, ok := r.(ProvideManyRouter); ok {
+		return pmr.ProvideMany(ctx, keys)
+	}
+	for _, k := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.Provide(ctx, cid.NewCidV1(cid.Raw, k), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package routing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	cid "github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+)
+
+type provideRecorder struct {
+	provided []cid.Cid
+	batches  [][]mh.Multihash
+}
+
+func (r *provideRecorder) Provide(_ context.Context, c cid.Cid, _ bool) error {
+	r.provided = append(r.provided, c)
+	return nil
+}
+
+func (r *provideRecorder) FindProvidersAsync(context.Context, cid.Cid, int) <-chan peer.AddrInfo {
+	return nil
+}
+
+type batchProvideRecorder struct {
+	provideRecorder
+}
+
+func (r *batchProvideRecorder) ProvideMany(_ context.Context, keys []mh.Multihash) error {
+	r.batches = append(r.batches, keys)
+	return nil
+}
+
+func (r *batchProvideRecorder) Ready() bool { return true }
+
+func TestProvideMany(t *testing.T) {
+	var keys []mh.Multihash
+	for _, s := range []string{"foo", "bar"} {
+		h, err := mh.Sum([]byte(s), mh.SHA2_256, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, h)
+	}
+	ctx := context.Background()
+
+	var r provideRecorder
+	if err := ProvideMany(ctx, &r, keys); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.provided) != len(keys) {
+		t.Fatalf("expected %d calls to Provide, got %d", len(keys), len(r.provided))
+	}
+	for i, c := range r.provided {
+		if c.Prefix().Codec != cid.Raw || string(c.Hash()) != string(keys[i]) {
+			t.Errorf("unexpected cid %s for key %d", c, i)
+		}
+	}
+
+	var br batchProvideRecorder
+	if err := ProvideMany(ctx, &br, keys); err != nil {
+		t.Fatal(err)
+	}
+	if len(br.batches) != 1 || len(br.provided) != 0 {
+		t.Fatalf("expected a single ProvideMany call, got %d batches and %d provides", len(br.batches), len(br.provided))
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := ProvideMany(cctx, &provideRecorder{}, keys); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
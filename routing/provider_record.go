@@ -0,0 +1,192 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/internal/catch"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	pb "github.com/libp2p/go-libp2p-core/routing/pb"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/ipfs/go-cid"
+)
+
+var _ record.Record = (*ProviderRecord)(nil)
+
+func init() {
+	record.RegisterType(&ProviderRecord{})
+}
+
+// ProviderRecordEnvelopeDomain is the domain string used for provider records
+// contained in a Envelope.
+const ProviderRecordEnvelopeDomain = "libp2p-provider-record"
+
+// ProviderRecordEnvelopePayloadType is the type hint used to identify provider
+// records in a Envelope.
+//
+// There is no registered multicodec for provider records yet, so a path-like
+// identifier is used instead.
+var ProviderRecordEnvelopePayloadType = []byte("/libp2p/provider-record")
+
+// MaxProviderRecordTTL bounds how far in the future a ProviderRecord may
+// expire. ConsumeProviderRecord rejects records expiring later, so that a
+// single announcement can't be replayed indefinitely.
+var MaxProviderRecordTTL = 48 * time.Hour
+
+var (
+	// ErrProviderRecordExpired is returned when consuming an expired
+	// ProviderRecord.
+	ErrProviderRecordExpired = errors.New("provider record expired")
+	// ErrProviderRecordTTLTooLong is returned when consuming a ProviderRecord
+	// expiring more than MaxProviderRecordTTL in the future.
+	ErrProviderRecordTTLTooLong = errors.New("provider record TTL too long")
+	// ErrProviderMismatch is returned when consuming a ProviderRecord that
+	// isn't signed by the provider it announces.
+	ErrProviderMismatch = errors.New("provider record not signed by provider")
+)
+
+// ProviderRecord is an announcement that a peer provides the content
+// identified by a CID, until an expiration time.
+//
+// ProviderRecords are signed by the provider inside a record.Envelope, so
+// that content routing systems can reject announcements made on behalf of
+// other peers:
+//
+//	envelope, err := routing.SealProviderRecord(privKey, c, time.Hour)
+//	if err != nil {
+//	  return err
+//	}
+//	data, err := envelope.Marshal()
+//
+// and, on the receiving end:
+//
+//	rec, envelope, err := routing.ConsumeProviderRecord(data)
+type ProviderRecord struct {
+	// Provider is the ID of the peer providing the content.
+	Provider peer.ID
+
+	// Cid identifies the provided content.
+	Cid cid.Cid
+
+	// Expiration is the time the record expires.
+	Expiration time.Time
+}
+
+// NewProviderRecord returns a ProviderRecord announcing that the given peer
+// provides the given CID for the next ttl, which must not exceed
+// MaxProviderRecordTTL to be accepted by ConsumeProviderRecord.
+func NewProviderRecord(p peer.ID, c cid.Cid, ttl time.Duration) *ProviderRecord {
+	return &ProviderRecord{
+		Provider:   p,
+		Cid:        c,
+		Expiration: time.Now().Add(ttl),
+	}
+}
+
+// SealProviderRecord creates a ProviderRecord for the peer with the given
+// private key, and signs it into a record.Envelope.
+func SealProviderRecord(privKey crypto.PrivKey, c cid.Cid, ttl time.Duration) (*record.Envelope, error) {
+	p, err := peer.IDFromPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return record.Seal(NewProviderRecord(p, c, ttl), privKey)
+}
+
+// ConsumeProviderRecord unmarshals a serialized Envelope containing a
+// ProviderRecord and verifies it: the Envelope must be signed by the
+// provider, and the record must neither be expired nor expire more than
+// MaxProviderRecordTTL in the future.
+//
+// As with record.ConsumeEnvelope, the Envelope may be returned along with an
+// error, and must not be used in that case.
+func ConsumeProviderRecord(data []byte) (*ProviderRecord, *record.Envelope, error) {
+	var rec ProviderRecord
+	envelope, err := record.ConsumeTypedEnvelope(data, &rec)
+	if err != nil {
+		return nil, envelope, err
+	}
+	if err := rec.Verify(envelope); err != nil {
+		return nil, envelope, err
+	}
+	return &rec, envelope, nil
+}
+
+// Verify checks that the ProviderRecord, contained in the given Envelope, is
+// issued by its provider, directly or through a delegated key, and valid at
+// the current time.
+func (r *ProviderRecord) Verify(envelope *record.Envelope) error {
+	if !r.Provider.MatchesPublicKey(envelope.Issuer()) {
+		return ErrProviderMismatch
+	}
+	now := time.Now()
+	if !now.Before(r.Expiration) {
+		return ErrProviderRecordExpired
+	}
+	if r.Expiration.Sub(now) > MaxProviderRecordTTL {
+		return ErrProviderRecordTTLTooLong
+	}
+	return nil
+}
+
+// Domain is used when signing and validating ProviderRecords contained in
+// Envelopes. It is constant for all ProviderRecord instances.
+func (r *ProviderRecord) Domain() string {
+	return ProviderRecordEnvelopeDomain
+}
+
+// Codec is a binary identifier for the ProviderRecord type. It is constant
+// for all ProviderRecord instances.
+func (r *ProviderRecord) Codec() []byte {
+	return ProviderRecordEnvelopePayloadType
+}
+
+// UnmarshalRecord parses a ProviderRecord from a byte slice.
+// This method is called automatically when consuming a record.Envelope
+// whose PayloadType indicates that it contains a ProviderRecord.
+func (r *ProviderRecord) UnmarshalRecord(bytes []byte) (err error) {
+	if r == nil {
+		return fmt.Errorf("cannot unmarshal ProviderRecord to nil receiver")
+	}
+
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p provider record unmarshal") }()
+
+	var msg pb.ProviderRecord
+	if err := proto.Unmarshal(bytes, &msg); err != nil {
+		return err
+	}
+
+	var id peer.ID
+	if err := id.UnmarshalBinary(msg.PeerId); err != nil {
+		return err
+	}
+	c, err := cid.Cast(msg.Cid)
+	if err != nil {
+		return err
+	}
+	r.Provider = id
+	r.Cid = c
+	r.Expiration = time.Unix(0, msg.Expiration)
+	return nil
+}
+
+// MarshalRecord serializes a ProviderRecord to a byte slice.
+// This method is called automatically when constructing a record.Envelope
+// using record.Seal.
+func (r *ProviderRecord) MarshalRecord() (res []byte, err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p provider record marshal") }()
+
+	idBytes, err := r.Provider.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(&pb.ProviderRecord{
+		PeerId:     idBytes,
+		Cid:        r.Cid.Bytes(),
+		Expiration: r.Expiration.UnixNano(),
+	})
+}
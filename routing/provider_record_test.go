@@ -0,0 +1,69 @@
+package routing
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	"github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+)
+
+func TestProviderRecord(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(ci.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := peer.IDFromPrivateKey(priv)
+	test.AssertNilError(t, err)
+	h, err := mh.Sum([]byte("content"), mh.SHA2_256, -1)
+	test.AssertNilError(t, err)
+	c := cid.NewCidV1(cid.Raw, h)
+
+	consume := func(t *testing.T, env *record.Envelope) (*ProviderRecord, error) {
+		t.Helper()
+		data, err := env.Marshal()
+		test.AssertNilError(t, err)
+		rec, _, err := ConsumeProviderRecord(data)
+		return rec, err
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		env, err := SealProviderRecord(priv, c, time.Hour)
+		test.AssertNilError(t, err)
+		rec, err := consume(t, env)
+		test.AssertNilError(t, err)
+		if rec.Provider != id || !rec.Cid.Equals(c) {
+			t.Fatalf("unexpected provider record %+v", rec)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		env, err := SealProviderRecord(priv, c, -time.Second)
+		test.AssertNilError(t, err)
+		if _, err := consume(t, env); !errors.Is(err, ErrProviderRecordExpired) {
+			t.Fatalf("expected ErrProviderRecordExpired, got %v", err)
+		}
+	})
+
+	t.Run("TTL too long", func(t *testing.T) {
+		env, err := SealProviderRecord(priv, c, MaxProviderRecordTTL+time.Hour)
+		test.AssertNilError(t, err)
+		if _, err := consume(t, env); !errors.Is(err, ErrProviderRecordTTLTooLong) {
+			t.Fatalf("expected ErrProviderRecordTTLTooLong, got %v", err)
+		}
+	})
+
+	t.Run("spoofed provider", func(t *testing.T) {
+		other, _, err := test.RandTestKeyPair(ci.Ed25519, 256)
+		test.AssertNilError(t, err)
+		env, err := record.Seal(NewProviderRecord(id, c, time.Hour), other)
+		test.AssertNilError(t, err)
+		if _, err := consume(t, env); !errors.Is(err, ErrProviderMismatch) {
+			t.Fatalf("expected ErrProviderMismatch, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package routing
+
+import (
+	"context"
+	"errors"
+
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+)
+
+// ErrPublicKeyMismatch is returned when a public key retrieved for a peer
+// doesn't match the peer's ID.
+var ErrPublicKeyMismatch = errors.New("routing: public key does not match peer ID")
+
+// CachingPubKeyFetcher is a PubKeyFetcher that looks up public keys from the
+// cheapest to the most expensive source:
+//
+//  1. the key inlined in the peer ID, if any;
+//  2. the key cached in the KeyBook;
+//  3. the signer of the peer's certified peer record, if the KeyBook is also
+//     a peerstore.CertifiedAddrBook;
+//  4. the ValueStore, see GetPublicKey.
+//
+// Keys found in the last two sources are checked against the peer ID and
+// cached in the KeyBook.
+type CachingPubKeyFetcher struct {
+	keys peerstore.KeyBook
+	vs   ValueStore
+}
+
+var _ PubKeyFetcher = (*CachingPubKeyFetcher)(nil)
+
+// NewCachingPubKeyFetcher constructs a CachingPubKeyFetcher. vs may be nil,
+// in which case keys are only looked up locally.
+func NewCachingPubKeyFetcher(keys peerstore.KeyBook, vs ValueStore) *CachingPubKeyFetcher {
+	return &CachingPubKeyFetcher{keys: keys, vs: vs}
+}
+
+// GetPublicKey returns the public key for the given peer.
+func (f *CachingPubKeyFetcher) GetPublicKey(ctx context.Context, p peer.ID) (ci.PubKey, error) {
+	switch k, err := p.ExtractPublicKey(); err {
+	case peer.ErrNoPublicKey:
+	case nil:
+		return k, nil
+	default:
+		return nil, err
+	}
+
+	if k := f.keys.PubKey(p); k != nil {
+		return k, nil
+	}
+
+	if cab, ok := f.keys.(peerstore.CertifiedAddrBook); ok {
+		if env := cab.GetPeerRecord(p); env != nil {
+			if k := env.Issuer(); p.MatchesPublicKey(k) {
+				return k, f.keys.AddPubKey(p, k)
+			}
+		}
+	}
+
+	if f.vs == nil {
+		return nil, ErrNotFound
+	}
+	k, err := GetPublicKey(f.vs, ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if !p.MatchesPublicKey(k) {
+		return nil, ErrPublicKeyMismatch
+	}
+	return k, f.keys.AddPubKey(p, k)
+}
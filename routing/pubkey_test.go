@@ -0,0 +1,160 @@
+package routing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	ci "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+type mapKeyBook struct {
+	sync.Mutex
+	keys map[peer.ID]ci.PubKey
+}
+
+func (kb *mapKeyBook) PubKey(p peer.ID) ci.PubKey {
+	kb.Lock()
+	defer kb.Unlock()
+	return kb.keys[p]
+}
+
+func (kb *mapKeyBook) AddPubKey(p peer.ID, k ci.PubKey) error {
+	kb.Lock()
+	defer kb.Unlock()
+	kb.keys[p] = k
+	return nil
+}
+
+func (kb *mapKeyBook) PrivKey(peer.ID) ci.PrivKey           { return nil }
+func (kb *mapKeyBook) AddPrivKey(peer.ID, ci.PrivKey) error { return nil }
+func (kb *mapKeyBook) PeersWithKeys() peer.IDSlice          { return nil }
+func (kb *mapKeyBook) RemovePeer(peer.ID)                   {}
+
+// certifiedKeyBook is a KeyBook that is also a minimal CertifiedAddrBook.
+type certifiedKeyBook struct {
+	mapKeyBook
+	records map[peer.ID]*record.Envelope
+}
+
+func (kb *certifiedKeyBook) ConsumePeerRecord(e *record.Envelope, _ time.Duration) (bool, error) {
+	var rec peer.PeerRecord
+	if err := e.TypedRecord(&rec); err != nil {
+		return false, err
+	}
+	if err := rec.VerifySigner(e); err != nil {
+		return false, err
+	}
+	kb.records[rec.PeerID] = e
+	return true, nil
+}
+
+func (kb *certifiedKeyBook) GetPeerRecord(p peer.ID) *record.Envelope {
+	return kb.records[p]
+}
+
+type mapValueStore struct {
+	values map[string][]byte
+	gets   int
+}
+
+func (vs *mapValueStore) PutValue(_ context.Context, k string, v []byte, _ ...Option) error {
+	vs.values[k] = v
+	return nil
+}
+
+func (vs *mapValueStore) GetValue(_ context.Context, k string, _ ...Option) ([]byte, error) {
+	vs.gets++
+	v, ok := vs.values[k]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return v, nil
+}
+
+func (vs *mapValueStore) SearchValue(context.Context, string, ...Option) (<-chan []byte, error) {
+	return nil, ErrNotSupported
+}
+
+func TestCachingPubKeyFetcher(t *testing.T) {
+	ctx := context.Background()
+	_, pub, err := test.RandTestKeyPair(ci.RSA, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubBytes, err := ci.MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kb := &mapKeyBook{keys: make(map[peer.ID]ci.PubKey)}
+	vs := &mapValueStore{values: map[string][]byte{KeyForPublicKey(id): pubBytes}}
+	f := NewCachingPubKeyFetcher(kb, vs)
+
+	for i := 0; i < 2; i++ {
+		k, err := f.GetPublicKey(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !k.Equals(pub) {
+			t.Fatal("unexpected key")
+		}
+	}
+	if vs.gets != 1 {
+		t.Fatalf("expected key to be cached after the first lookup, got %d lookups", vs.gets)
+	}
+
+	// a key that doesn't match the peer ID is rejected
+	other := test.RandPeerIDFatal(t)
+	vs.values[KeyForPublicKey(other)] = pubBytes
+	if _, err := f.GetPublicKey(ctx, other); err != ErrPublicKeyMismatch {
+		t.Fatalf("expected ErrPublicKeyMismatch, got %v", err)
+	}
+
+	if _, err := NewCachingPubKeyFetcher(kb, nil).GetPublicKey(ctx, test.RandPeerIDFatal(t)); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCachingPubKeyFetcherDelegatedPeerRecord(t *testing.T) {
+	identity, identityPub, err := test.RandTestKeyPair(ci.RSA, 2048)
+	test.AssertNilError(t, err)
+	opKey, opPub, err := test.RandTestKeyPair(ci.Ed25519, 256)
+	test.AssertNilError(t, err)
+	id, err := peer.IDFromPublicKey(identityPub)
+	test.AssertNilError(t, err)
+
+	now := time.Now()
+	delegation, err := record.SealDelegation(identity, opPub, []string{peer.PeerRecordEnvelopeDomain}, now.Add(-time.Minute), now.Add(time.Hour))
+	test.AssertNilError(t, err)
+	envelope, err := record.SealDelegated(&peer.PeerRecord{PeerID: id, Addrs: test.GenerateTestAddrs(1), Seq: 1}, opKey, delegation)
+	test.AssertNilError(t, err)
+	data, err := envelope.Marshal()
+	test.AssertNilError(t, err)
+	consumed, _, err := record.ConsumeEnvelope(data, peer.PeerRecordEnvelopeDomain)
+	test.AssertNilError(t, err)
+
+	kb := &certifiedKeyBook{
+		mapKeyBook: mapKeyBook{keys: make(map[peer.ID]ci.PubKey)},
+		records:    make(map[peer.ID]*record.Envelope),
+	}
+	accepted, err := kb.ConsumePeerRecord(consumed, time.Hour)
+	test.AssertNilError(t, err)
+	if !accepted {
+		t.Fatal("expected the delegated peer record to be accepted")
+	}
+
+	k, err := NewCachingPubKeyFetcher(kb, nil).GetPublicKey(context.Background(), id)
+	test.AssertNilError(t, err)
+	if !k.Equals(identityPub) {
+		t.Fatal("expected the identity key, not the delegate key, to be returned")
+	}
+}
@@ -18,6 +18,20 @@ var ErrNotFound = errors.New("routing: not found")
 // type/operation.
 var ErrNotSupported = errors.New("routing: operation or key not supported")
 
+// ErrOffline is returned when the router can't reach the network, either
+// because it has no connected peers or because the Offline option was given
+// and the requested record isn't available locally. Unlike ErrNotFound, it
+// doesn't imply that the record doesn't exist.
+var ErrOffline = errors.New("routing: operation requires the network but the router is offline")
+
+// ErrQuorumNotMet is returned when fewer peers than required by the Quorum
+// option agreed on a value before the query terminated.
+var ErrQuorumNotMet = errors.New("routing: quorum not met")
+
+// ErrExpired is returned when the only records found have expired and the
+// Expired option wasn't given.
+var ErrExpired = errors.New("routing: record expired")
+
 // ContentRouting is a value provider layer of indirection. It is used to find
 // information about who has what content.
 //
@@ -62,6 +76,9 @@ type ValueStore interface {
 	// Useful when you want a result *now* but still want to hear about
 	// better/newer results.
 	//
+	// The Quorum option controls when the search is considered converged, and
+	// the OnBestValue option can be used to learn the final selection.
+	//
 	// Implementations of this methods won't return ErrNotFound. When a value
 	// couldn't be found, the channel will get closed without passing any results
 	SearchValue(context.Context, string, ...Option) (<-chan []byte, error)
@@ -0,0 +1,81 @@
+package sampling
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// Option is a single sampling option.
+type Option func(opts *Options) error
+
+// Options is a set of sampling options.
+type Options struct {
+	// Exclude lists peers that must not be returned.
+	Exclude map[peer.ID]struct{}
+
+	// Filter, if set, is called for each candidate; candidates for which it
+	// returns false are skipped.
+	Filter func(peer.AddrInfo) bool
+
+	// Weight, if set, biases the sample: candidates are drawn with a
+	// probability proportional to their weight. Peers with a weight <= 0 are
+	// never returned.
+	Weight func(peer.ID) float64
+
+	// MaxAge is the maximum age of the view entries to draw from. Zero
+	// means no limit.
+	MaxAge time.Duration
+
+	// Other (implementation-specific) options
+	Other map[interface{}]interface{}
+}
+
+// Apply applies the given options to this Options
+func (opts *Options) Apply(options ...Option) error {
+	for _, o := range options {
+		if err := o(opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exclude is an option that prevents the given peers from being sampled.
+func Exclude(peers ...peer.ID) Option {
+	return func(opts *Options) error {
+		if opts.Exclude == nil {
+			opts.Exclude = make(map[peer.ID]struct{}, len(peers))
+		}
+		for _, p := range peers {
+			opts.Exclude[p] = struct{}{}
+		}
+		return nil
+	}
+}
+
+// Filter is an option that restricts the sample to the peers satisfying f.
+func Filter(f func(peer.AddrInfo) bool) Option {
+	return func(opts *Options) error {
+		opts.Filter = f
+		return nil
+	}
+}
+
+// Weight is an option that biases the sample towards peers with a higher
+// weight.
+func Weight(f func(peer.ID) float64) Option {
+	return func(opts *Options) error {
+		opts.Weight = f
+		return nil
+	}
+}
+
+// MaxAge is an option that restricts the sample to view entries younger than
+// the given age.
+func MaxAge(age time.Duration) Option {
+	return func(opts *Options) error {
+		opts.MaxAge = age
+		return nil
+	}
+}
@@ -0,0 +1,138 @@
+package sampling
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p-core/internal/catch"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	pb "github.com/libp2p/go-libp2p-core/sampling/pb"
+
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var _ record.Record = (*ViewRecord)(nil)
+
+func init() {
+	record.RegisterType(&ViewRecord{})
+}
+
+// ViewRecordEnvelopeDomain is the domain string used for view records contained
+// in a Envelope.
+const ViewRecordEnvelopeDomain = "libp2p-peer-sampling-view"
+
+// ViewRecordEnvelopePayloadType is the type hint used to identify view records
+// in a Envelope.
+var ViewRecordEnvelopePayloadType = []byte("/libp2p/peer-sampling-view")
+
+// ViewEntry is a single peer in a ViewRecord.
+type ViewEntry struct {
+	peer.AddrInfo
+
+	// Age is the number of exchange rounds since the entry was created by
+	// the peer it describes.
+	Age uint32
+}
+
+// ViewRecord is a snapshot of the partial view of a peer sampling service,
+// exchanged between peers inside a signed record.Envelope:
+//
+//	rec := sampler.LocalView()
+//	envelope, err := record.Seal(rec, myPrivateKey)
+type ViewRecord struct {
+	// PeerID is the ID of the peer owning the view.
+	PeerID peer.ID
+
+	// Seq orders the ViewRecords of a peer in time.
+	Seq uint64
+
+	// Entries is the list of peers in the view.
+	Entries []ViewEntry
+}
+
+// Domain is used when signing and validating ViewRecords contained in
+// Envelopes. It is constant for all ViewRecord instances.
+func (r *ViewRecord) Domain() string {
+	return ViewRecordEnvelopeDomain
+}
+
+// Codec is a binary identifier for the ViewRecord type. It is constant for all
+// ViewRecord instances.
+func (r *ViewRecord) Codec() []byte {
+	return ViewRecordEnvelopePayloadType
+}
+
+// UnmarshalRecord parses a ViewRecord from a byte slice.
+func (r *ViewRecord) UnmarshalRecord(bytes []byte) (err error) {
+	if r == nil {
+		return fmt.Errorf("cannot unmarshal ViewRecord to nil receiver")
+	}
+
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p view record unmarshal") }()
+
+	var msg pb.ViewRecord
+	if err := proto.Unmarshal(bytes, &msg); err != nil {
+		return err
+	}
+
+	var id peer.ID
+	if err := id.UnmarshalBinary(msg.PeerId); err != nil {
+		return err
+	}
+	entries := make([]ViewEntry, 0, len(msg.Entries))
+	for _, e := range msg.Entries {
+		var eid peer.ID
+		if err := eid.UnmarshalBinary(e.PeerId); err != nil {
+			return err
+		}
+		addrs := make([]ma.Multiaddr, 0, len(e.Addresses))
+		for _, b := range e.Addresses {
+			a, err := ma.NewMultiaddrBytes(b)
+			if err != nil {
+				continue
+			}
+			addrs = append(addrs, a)
+		}
+		entries = append(entries, ViewEntry{
+			AddrInfo: peer.AddrInfo{ID: eid, Addrs: addrs},
+			Age:      e.Age,
+		})
+	}
+
+	r.PeerID = id
+	r.Seq = msg.Seq
+	r.Entries = entries
+	return nil
+}
+
+// MarshalRecord serializes a ViewRecord to a byte slice.
+func (r *ViewRecord) MarshalRecord() (res []byte, err error) {
+	defer func() { catch.HandlePanic(recover(), &err, "libp2p view record marshal") }()
+
+	idBytes, err := r.PeerID.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	msg := &pb.ViewRecord{
+		PeerId:  idBytes,
+		Seq:     r.Seq,
+		Entries: make([]*pb.ViewRecord_Entry, 0, len(r.Entries)),
+	}
+	for _, e := range r.Entries {
+		eid, err := e.ID.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		addrs := make([][]byte, 0, len(e.Addrs))
+		for _, a := range e.Addrs {
+			addrs = append(addrs, a.Bytes())
+		}
+		msg.Entries = append(msg.Entries, &pb.ViewRecord_Entry{
+			PeerId:    eid,
+			Addresses: addrs,
+			Age:       e.Age,
+		})
+	}
+	return proto.Marshal(msg)
+}
@@ -0,0 +1,45 @@
+package sampling
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/record"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewRecordRoundTrip(t *testing.T) {
+	priv, _, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	require.NoError(t, err)
+	id, err := peer.IDFromPrivateKey(priv)
+	require.NoError(t, err)
+
+	rec := &ViewRecord{
+		PeerID: id,
+		Seq:    peer.TimestampSeq(),
+		Entries: []ViewEntry{
+			{AddrInfo: peer.AddrInfo{ID: test.RandPeerIDFatal(t), Addrs: test.GenerateTestAddrs(2)}, Age: 3},
+			{AddrInfo: peer.AddrInfo{ID: test.RandPeerIDFatal(t)}},
+		},
+	}
+	envelope, err := record.Seal(rec, priv)
+	require.NoError(t, err)
+	envBytes, err := envelope.Marshal()
+	require.NoError(t, err)
+
+	_, untyped, err := record.ConsumeEnvelope(envBytes, ViewRecordEnvelopeDomain)
+	require.NoError(t, err)
+	rec2, ok := untyped.(*ViewRecord)
+	require.True(t, ok)
+	require.Equal(t, rec.PeerID, rec2.PeerID)
+	require.Equal(t, rec.Seq, rec2.Seq)
+	require.Len(t, rec2.Entries, 2)
+	for i := range rec.Entries {
+		require.Equal(t, rec.Entries[i].ID, rec2.Entries[i].ID)
+		require.Equal(t, rec.Entries[i].Age, rec2.Entries[i].Age)
+		test.AssertAddressesEqual(t, rec.Entries[i].Addrs, rec2.Entries[i].Addrs)
+	}
+}
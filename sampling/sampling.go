@@ -0,0 +1,41 @@
+// Package sampling provides interfaces for peer sampling services.
+//
+// A peer sampling service maintains a partial view of the live peers in the
+// network, usually through random walks or periodic view exchanges with other
+// peers (as in gossip-based membership protocols), and hands out random
+// samples of it. Components needing random peers (e.g. gossip fanout, load
+// spreading, bootstrapping) should depend on the Sampler interface, so that
+// implementations can be swapped.
+package sampling
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// Sampler is implemented by peer sampling services.
+type Sampler interface {
+	// Sample returns up to k peers drawn at random from the live peers known
+	// to the service. The returned slice may be shorter than k if not enough
+	// peers satisfy the given options.
+	Sample(ctx context.Context, k int, opts ...Option) ([]peer.AddrInfo, error)
+
+	// View returns a snapshot of the current partial view of the service.
+	View() []peer.AddrInfo
+}
+
+// ViewExchanger is implemented by samplers that periodically exchange
+// (signed) views with other peers.
+type ViewExchanger interface {
+	Sampler
+
+	// LocalView returns the current view of the local peer as a ViewRecord,
+	// ready to be signed and shared.
+	LocalView() *ViewRecord
+
+	// ConsumeView merges a view received from a remote peer into the local
+	// view. The record must have been verified (e.g. by consuming the
+	// envelope containing it) before being passed here.
+	ConsumeView(rec *ViewRecord) error
+}
@@ -0,0 +1,46 @@
+package sec
+
+import (
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// ConnState describes the properties negotiated during the handshake of a
+// SecureConn, for metrics and audit logging.
+type ConnState struct {
+	// SecurityProtocol is the ID of the negotiated security protocol, e.g.
+	// "/noise" or "/tls/1.0.0".
+	SecurityProtocol protocol.ID
+
+	// Version is the version of the underlying handshake, e.g. "TLS 1.3" or
+	// the Noise protocol name ("Noise_XX_25519_ChaChaPoly_SHA256"). It is
+	// empty if not applicable.
+	Version string
+
+	// CipherSuite is the negotiated cipher suite, if applicable.
+	CipherSuite string
+
+	// UsedEarlyData is true if early data was exchanged during the
+	// handshake. See EarlyDataTransport.
+	UsedEarlyData bool
+}
+
+// ConnStateConn is implemented by SecureConns that expose the properties of
+// their handshake.
+//
+// To test whether a given SecureConn exposes its state, use the GetConnState
+// helper.
+type ConnStateConn interface {
+	SecureConn
+
+	// ConnState returns the properties negotiated during the handshake.
+	ConnState() ConnState
+}
+
+// GetConnState returns the handshake properties of c if it is a
+// ConnStateConn. Returns (ConnState{}, false) otherwise.
+func GetConnState(c SecureConn) (ConnState, bool) {
+	if csc, ok := c.(ConnStateConn); ok {
+		return csc.ConnState(), true
+	}
+	return ConnState{}, false
+}
@@ -0,0 +1,63 @@
+package sec
+
+import (
+	"context"
+	"net"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p-core/record"
+)
+
+// EarlyData is information exchanged as part of the security handshake, so
+// that it doesn't take an extra round trip once the connection is secured.
+type EarlyData struct {
+	// SignedPeerRecord is the sender's signed peer.PeerRecord, if any.
+	SignedPeerRecord *record.Envelope
+
+	// Muxers are the stream multiplexers supported by the sender, in order
+	// of preference.
+	Muxers []protocol.ID
+}
+
+// EarlyDataHandler provides and consumes the early data of a handshake.
+//
+// Security protocols only send early data once the remote peer has been
+// authenticated, or encrypted such that only the expected remote peer can
+// read it. Implementations that can't guarantee this must not send early
+// data.
+type EarlyDataHandler interface {
+	// Send returns the early data to send to the remote peer, or nil to not
+	// send any. The remote peer is empty for inbound connections where it
+	// isn't known yet.
+	Send(ctx context.Context, conn net.Conn, p peer.ID) *EarlyData
+
+	// Received is called with the early data received from the
+	// authenticated remote peer. Returning an error aborts the handshake.
+	Received(ctx context.Context, conn net.Conn, p peer.ID, ed *EarlyData) error
+}
+
+// EarlyDataTransport is implemented by SecureTransports that can piggyback
+// early data on their handshake, such as Noise and TLS 1.3.
+type EarlyDataTransport interface {
+	SecureTransport
+
+	// SetEarlyDataHandler sets the handler used for all subsequent
+	// handshakes. It must be called before the transport is used.
+	SetEarlyDataHandler(h EarlyDataHandler)
+}
+
+// EarlyDataConn is implemented by SecureConns established by an
+// EarlyDataTransport.
+type EarlyDataConn interface {
+	SecureConn
+
+	// RemoteEarlyData returns the early data received from the remote peer
+	// during the handshake, or nil if it didn't send any.
+	RemoteEarlyData() *EarlyData
+
+	// NegotiatedMuxer returns the stream multiplexer selected during the
+	// handshake, or an empty string if none was selected, in which case the
+	// multiplexer must be negotiated once the connection is secured.
+	NegotiatedMuxer() protocol.ID
+}
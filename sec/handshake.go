@@ -0,0 +1,216 @@
+package sec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+var (
+	// ErrPeerMismatch is matched by handshake errors due to the remote peer
+	// not being the expected peer.
+	ErrPeerMismatch = errors.New("remote peer is not the expected peer")
+
+	// ErrHandshakeCryptoFailure is matched by handshake errors due to a
+	// failed cryptographic verification, e.g. an invalid signature or a
+	// peer ID that doesn't match the presented public key.
+	ErrHandshakeCryptoFailure = errors.New("handshake cryptographic verification failed")
+
+	// ErrHandshakeTimeout is matched by handshake errors due to the
+	// handshake not completing in time.
+	ErrHandshakeTimeout = errors.New("handshake timed out")
+)
+
+// HandshakeAbortReason is the reason a security handshake was aborted, so that
+// dialers can react appropriately, e.g. by evicting an address that leads to
+// the wrong peer.
+type HandshakeAbortReason int
+
+const (
+	// HandshakeAbortUnknown is the reason of errors that don't fall in any
+	// of the other categories, e.g. network errors.
+	HandshakeAbortUnknown HandshakeAbortReason = iota
+	// HandshakeAbortPeerMismatch means the remote peer isn't the expected
+	// peer.
+	HandshakeAbortPeerMismatch
+	// HandshakeAbortCryptoFailure means a cryptographic verification
+	// failed.
+	HandshakeAbortCryptoFailure
+	// HandshakeAbortTimeout means the handshake didn't complete in time.
+	HandshakeAbortTimeout
+)
+
+func (r HandshakeAbortReason) String() string {
+	switch r {
+	case HandshakeAbortPeerMismatch:
+		return "peer mismatch"
+	case HandshakeAbortCryptoFailure:
+		return "crypto failure"
+	case HandshakeAbortTimeout:
+		return "timeout"
+	default:
+		return "unknown"
+	}
+}
+
+func (r HandshakeAbortReason) sentinel() error {
+	switch r {
+	case HandshakeAbortPeerMismatch:
+		return ErrPeerMismatch
+	case HandshakeAbortCryptoFailure:
+		return ErrHandshakeCryptoFailure
+	case HandshakeAbortTimeout:
+		return ErrHandshakeTimeout
+	default:
+		return nil
+	}
+}
+
+// HandshakeError is returned by SecureTransports when a handshake is aborted.
+// It matches the sentinel error of its reason with errors.Is, e.g.
+//
+//	if errors.Is(err, sec.ErrPeerMismatch) {
+//		// the address doesn't lead to the expected peer
+//	}
+type HandshakeError struct {
+	// Reason is the reason the handshake was aborted.
+	Reason HandshakeAbortReason
+
+	// Expected and Actual are the expected and actual remote peers, if
+	// known, e.g. for a HandshakeAbortPeerMismatch.
+	Expected, Actual peer.ID
+
+	// Err is the underlying error, if any.
+	Err error
+}
+
+var _ net.Error = (*HandshakeError)(nil)
+
+func (e *HandshakeError) Error() string {
+	msg := "security handshake aborted (" + e.Reason.String() + ")"
+	if e.Reason == HandshakeAbortPeerMismatch {
+		msg += fmt.Sprintf(" expected=%s actual=%s", e.Expected, e.Actual)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *HandshakeError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is the sentinel error of the abort reason.
+func (e *HandshakeError) Is(target error) bool {
+	s := e.Reason.sentinel()
+	return s != nil && target == s
+}
+
+// Timeout returns true if the handshake timed out.
+func (e *HandshakeError) Timeout() bool {
+	return e.Reason == HandshakeAbortTimeout
+}
+
+// Temporary returns false. It is only present to implement net.Error.
+func (e *HandshakeError) Temporary() bool {
+	return false
+}
+
+// HandshakeAbortReasonOf returns the reason a handshake failed with err.
+// Errors that aren't HandshakeErrors are classified as timeouts if they are
+// context deadline errors or net.Errors reporting a timeout, and as
+// HandshakeAbortUnknown otherwise.
+func HandshakeAbortReasonOf(err error) HandshakeAbortReason {
+	var herr *HandshakeError
+	if errors.As(err, &herr) {
+		return herr.Reason
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return HandshakeAbortTimeout
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) && nerr.Timeout() {
+		return HandshakeAbortTimeout
+	}
+	return HandshakeAbortUnknown
+}
+
+// HandshakeOptions are the per-handshake options of a SecureTransport.
+type HandshakeOptions struct {
+	// Timeout bounds the duration of the handshake, in addition to the
+	// context's deadline. Zero means no timeout.
+	Timeout time.Duration
+
+	// ExpectedPeer is the peer expected on the other end of the
+	// connection. It is required for outbound handshakes. For inbound
+	// handshakes, an empty ExpectedPeer accepts any peer.
+	ExpectedPeer peer.ID
+}
+
+// HandshakeOptionsTransport is implemented by SecureTransports accepting
+// per-handshake options. Their handshake errors are HandshakeErrors.
+//
+// To secure connections with options regardless of the transport, use the
+// SecureInboundWithOptions and SecureOutboundWithOptions helpers.
+type HandshakeOptionsTransport interface {
+	SecureTransport
+
+	// SecureInboundWithOptions secures an inbound connection.
+	SecureInboundWithOptions(ctx context.Context, insecure net.Conn, opts HandshakeOptions) (SecureConn, error)
+
+	// SecureOutboundWithOptions secures an outbound connection.
+	SecureOutboundWithOptions(ctx context.Context, insecure net.Conn, opts HandshakeOptions) (SecureConn, error)
+}
+
+// SecureInboundWithOptions secures an inbound connection with t. If t isn't a
+// HandshakeOptionsTransport, the timeout is applied to the context and as a
+// deadline on the connection, and timeouts are reported as HandshakeErrors.
+func SecureInboundWithOptions(ctx context.Context, t SecureTransport, insecure net.Conn, opts HandshakeOptions) (SecureConn, error) {
+	if ot, ok := t.(HandshakeOptionsTransport); ok {
+		return ot.SecureInboundWithOptions(ctx, insecure, opts)
+	}
+	return secureWithTimeout(ctx, insecure, opts, t.SecureInbound)
+}
+
+// SecureOutboundWithOptions secures an outbound connection with t, falling
+// back like SecureInboundWithOptions.
+func SecureOutboundWithOptions(ctx context.Context, t SecureTransport, insecure net.Conn, opts HandshakeOptions) (SecureConn, error) {
+	if ot, ok := t.(HandshakeOptionsTransport); ok {
+		return ot.SecureOutboundWithOptions(ctx, insecure, opts)
+	}
+	return secureWithTimeout(ctx, insecure, opts, t.SecureOutbound)
+}
+
+func secureWithTimeout(
+	ctx context.Context,
+	insecure net.Conn,
+	opts HandshakeOptions,
+	secure func(context.Context, net.Conn, peer.ID) (SecureConn, error),
+) (SecureConn, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	// Transports that ignore the context are bounded by the deadline.
+	if deadline, ok := ctx.Deadline(); ok && opts.Timeout > 0 {
+		if err := insecure.SetDeadline(deadline); err == nil {
+			defer insecure.SetDeadline(time.Time{})
+		}
+	}
+
+	conn, err := secure(ctx, insecure, opts.ExpectedPeer)
+	if err != nil {
+		var herr *HandshakeError
+		if !errors.As(err, &herr) && HandshakeAbortReasonOf(err) == HandshakeAbortTimeout {
+			err = &HandshakeError{Reason: HandshakeAbortTimeout, Expected: opts.ExpectedPeer, Err: err}
+		}
+		return nil, err
+	}
+	return conn, nil
+}
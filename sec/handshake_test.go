@@ -0,0 +1,255 @@
+package sec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+)
+
+// deadlineConn records the deadlines set on a net.Conn.
+type deadlineConn struct {
+	net.Conn
+
+	mu        sync.Mutex
+	deadlines []time.Time
+}
+
+func (c *deadlineConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deadlines = append(c.deadlines, t)
+	return nil
+}
+
+func (c *deadlineConn) Deadlines() []time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Time(nil), c.deadlines...)
+}
+
+type secureConn struct {
+	net.Conn
+	network.ConnSecurity
+}
+
+// stubTransport is a SecureTransport returning err, or a SecureConn wrapping
+// the insecure connection, and recording the arguments of its last call.
+type stubTransport struct {
+	err error
+
+	inbound  bool
+	ctx      context.Context
+	deadline []time.Time
+	peer     peer.ID
+}
+
+func (t *stubTransport) secure(ctx context.Context, inbound bool, insecure net.Conn, p peer.ID) (SecureConn, error) {
+	t.inbound, t.ctx, t.peer = inbound, ctx, p
+	t.deadline = insecure.(*deadlineConn).Deadlines()
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &secureConn{Conn: insecure}, nil
+}
+
+func (t *stubTransport) SecureInbound(ctx context.Context, insecure net.Conn, p peer.ID) (SecureConn, error) {
+	return t.secure(ctx, true, insecure, p)
+}
+
+func (t *stubTransport) SecureOutbound(ctx context.Context, insecure net.Conn, p peer.ID) (SecureConn, error) {
+	return t.secure(ctx, false, insecure, p)
+}
+
+// stubOptionsTransport is a HandshakeOptionsTransport recording the options
+// of its last call.
+type stubOptionsTransport struct {
+	stubTransport
+	opts HandshakeOptions
+}
+
+func (t *stubOptionsTransport) SecureInboundWithOptions(ctx context.Context, insecure net.Conn, opts HandshakeOptions) (SecureConn, error) {
+	t.opts = opts
+	return t.secure(ctx, true, insecure, opts.ExpectedPeer)
+}
+
+func (t *stubOptionsTransport) SecureOutboundWithOptions(ctx context.Context, insecure net.Conn, opts HandshakeOptions) (SecureConn, error) {
+	t.opts = opts
+	return t.secure(ctx, false, insecure, opts.ExpectedPeer)
+}
+
+type netError struct {
+	timeout bool
+}
+
+func (e *netError) Error() string   { return "net error" }
+func (e *netError) Timeout() bool   { return e.timeout }
+func (e *netError) Temporary() bool { return false }
+
+func newDeadlineConn(t *testing.T) *deadlineConn {
+	a, b := net.Pipe()
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	return &deadlineConn{Conn: a}
+}
+
+func TestHandshakeError(t *testing.T) {
+	expected, actual := test.RandPeerIDFatal(t), test.RandPeerIDFatal(t)
+	err := &HandshakeError{Reason: HandshakeAbortPeerMismatch, Expected: expected, Actual: actual, Err: io.EOF}
+	require.True(t, errors.Is(err, ErrPeerMismatch))
+	require.False(t, errors.Is(err, ErrHandshakeTimeout))
+	require.False(t, errors.Is(err, ErrHandshakeCryptoFailure))
+	require.True(t, errors.Is(err, io.EOF))
+	require.Equal(t, io.EOF, errors.Unwrap(err))
+	require.Contains(t, err.Error(), expected.String())
+	require.Contains(t, err.Error(), actual.String())
+	require.False(t, err.Timeout())
+
+	// matches through wrapping
+	wrapped := fmt.Errorf("dial failed: %w", err)
+	require.True(t, errors.Is(wrapped, ErrPeerMismatch))
+
+	err = &HandshakeError{Reason: HandshakeAbortTimeout}
+	require.True(t, errors.Is(err, ErrHandshakeTimeout))
+	require.True(t, err.Timeout())
+	require.Nil(t, errors.Unwrap(err))
+
+	// unknown reasons don't match any sentinel
+	err = &HandshakeError{Reason: HandshakeAbortUnknown, Err: io.EOF}
+	for _, sentinel := range []error{ErrPeerMismatch, ErrHandshakeCryptoFailure, ErrHandshakeTimeout} {
+		require.False(t, errors.Is(err, sentinel))
+	}
+}
+
+func TestHandshakeAbortReasonOf(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected HandshakeAbortReason
+	}{
+		{"handshake error", &HandshakeError{Reason: HandshakeAbortCryptoFailure}, HandshakeAbortCryptoFailure},
+		{"wrapped handshake error", fmt.Errorf("x: %w", &HandshakeError{Reason: HandshakeAbortPeerMismatch}), HandshakeAbortPeerMismatch},
+		{"context deadline", context.DeadlineExceeded, HandshakeAbortTimeout},
+		{"wrapped context deadline", fmt.Errorf("x: %w", context.DeadlineExceeded), HandshakeAbortTimeout},
+		{"net.Error timeout", &netError{timeout: true}, HandshakeAbortTimeout},
+		{"net.Error", &netError{}, HandshakeAbortUnknown},
+		{"context canceled", context.Canceled, HandshakeAbortUnknown},
+		{"other error", io.EOF, HandshakeAbortUnknown},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, HandshakeAbortReasonOf(tc.err))
+		})
+	}
+}
+
+func TestSecureWithTimeoutFallback(t *testing.T) {
+	p := test.RandPeerIDFatal(t)
+	opts := HandshakeOptions{Timeout: time.Minute, ExpectedPeer: p}
+
+	for _, inbound := range []bool{true, false} {
+		secure := SecureOutboundWithOptions
+		if inbound {
+			secure = SecureInboundWithOptions
+		}
+
+		tpt := &stubTransport{}
+		conn := newDeadlineConn(t)
+		start := time.Now()
+		sconn, err := secure(context.Background(), tpt, conn, opts)
+		require.NoError(t, err)
+		require.Equal(t, conn, sconn.(*secureConn).Conn)
+		require.Equal(t, inbound, tpt.inbound)
+		require.Equal(t, p, tpt.peer)
+
+		// the timeout is applied to the context and as a deadline on the
+		// connection, which is reset once the handshake completes
+		deadline, ok := tpt.ctx.Deadline()
+		require.True(t, ok)
+		require.WithinDuration(t, start.Add(time.Minute), deadline, time.Second)
+		require.Equal(t, []time.Time{deadline}, tpt.deadline)
+		require.Equal(t, []time.Time{deadline, {}}, conn.Deadlines())
+	}
+
+	// without a timeout, no deadline is set
+	tpt := &stubTransport{}
+	conn := newDeadlineConn(t)
+	_, err := SecureOutboundWithOptions(context.Background(), tpt, conn, HandshakeOptions{ExpectedPeer: p})
+	require.NoError(t, err)
+	_, ok := tpt.ctx.Deadline()
+	require.False(t, ok)
+	require.Empty(t, conn.Deadlines())
+
+	// a shorter context deadline wins over the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ctxDeadline, _ := ctx.Deadline()
+	_, err = SecureOutboundWithOptions(ctx, tpt, newDeadlineConn(t), opts)
+	require.NoError(t, err)
+	deadline, _ := tpt.ctx.Deadline()
+	require.Equal(t, ctxDeadline, deadline)
+	require.Equal(t, []time.Time{ctxDeadline}, tpt.deadline)
+}
+
+func TestSecureWithTimeoutErrors(t *testing.T) {
+	p := test.RandPeerIDFatal(t)
+	opts := HandshakeOptions{Timeout: time.Minute, ExpectedPeer: p}
+
+	// timeouts are wrapped in HandshakeErrors
+	for _, timeoutErr := range []error{&netError{timeout: true}, context.DeadlineExceeded} {
+		tpt := &stubTransport{err: timeoutErr}
+		conn := newDeadlineConn(t)
+		_, err := SecureOutboundWithOptions(context.Background(), tpt, conn, opts)
+		var herr *HandshakeError
+		require.True(t, errors.As(err, &herr))
+		require.Equal(t, HandshakeAbortTimeout, herr.Reason)
+		require.Equal(t, p, herr.Expected)
+		require.True(t, errors.Is(err, ErrHandshakeTimeout))
+		require.Equal(t, timeoutErr, errors.Unwrap(err))
+		// the deadline is reset on failure too
+		require.Len(t, conn.Deadlines(), 2)
+		require.True(t, conn.Deadlines()[1].IsZero())
+	}
+
+	// other errors are returned as is
+	tpt := &stubTransport{err: io.EOF}
+	_, err := SecureOutboundWithOptions(context.Background(), tpt, newDeadlineConn(t), opts)
+	require.Equal(t, io.EOF, err)
+
+	herr := &HandshakeError{Reason: HandshakeAbortPeerMismatch, Expected: p, Err: &netError{timeout: true}}
+	tpt = &stubTransport{err: herr}
+	_, err = SecureOutboundWithOptions(context.Background(), tpt, newDeadlineConn(t), opts)
+	require.Equal(t, herr, err)
+}
+
+func TestSecureWithOptionsTransport(t *testing.T) {
+	opts := HandshakeOptions{Timeout: time.Minute, ExpectedPeer: test.RandPeerIDFatal(t)}
+	for _, inbound := range []bool{true, false} {
+		secure := SecureOutboundWithOptions
+		if inbound {
+			secure = SecureInboundWithOptions
+		}
+		tpt := &stubOptionsTransport{}
+		conn := newDeadlineConn(t)
+		_, err := secure(context.Background(), tpt, conn, opts)
+		require.NoError(t, err)
+		require.Equal(t, inbound, tpt.inbound)
+		// the options are passed on as is, and the transport applies the
+		// timeout itself
+		require.Equal(t, opts, tpt.opts)
+		_, ok := tpt.ctx.Deadline()
+		require.False(t, ok)
+		require.Empty(t, conn.Deadlines())
+	}
+}
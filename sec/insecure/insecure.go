@@ -73,7 +73,7 @@ func (t *Transport) SecureInbound(ctx context.Context, insecure net.Conn, p peer
 	}
 
 	if t.key != nil && p != "" && p != conn.remote {
-		return nil, fmt.Errorf("remote peer sent unexpected peer ID. expected=%s received=%s", p, conn.remote)
+		return nil, peerMismatchError(p, conn.remote)
 	}
 
 	return conn, nil
@@ -100,13 +100,21 @@ func (t *Transport) SecureOutbound(ctx context.Context, insecure net.Conn, p pee
 	}
 
 	if t.key != nil && p != conn.remote {
-		return nil, fmt.Errorf("remote peer sent unexpected peer ID. expected=%s received=%s",
-			p, conn.remote)
+		return nil, peerMismatchError(p, conn.remote)
 	}
 
 	return conn, nil
 }
 
+func peerMismatchError(expected, actual peer.ID) error {
+	return &sec.HandshakeError{
+		Reason:   sec.HandshakeAbortPeerMismatch,
+		Expected: expected,
+		Actual:   actual,
+		Err:      fmt.Errorf("remote peer sent unexpected peer ID"),
+	}
+}
+
 // Conn is the connection type returned by the insecure transport.
 type Conn struct {
 	net.Conn
@@ -166,8 +174,12 @@ func (ic *Conn) runHandshakeSync() error {
 	// Validate that ID matches public key
 	if !remoteID.MatchesPublicKey(remotePubkey) {
 		calculatedID, _ := peer.IDFromPublicKey(remotePubkey)
-		return fmt.Errorf("remote peer id does not match public key. id=%s calculated_id=%s",
-			remoteID, calculatedID)
+		return &sec.HandshakeError{
+			Reason: sec.HandshakeAbortCryptoFailure,
+			Actual: remoteID,
+			Err: fmt.Errorf("remote peer id does not match public key. id=%s calculated_id=%s",
+				remoteID, calculatedID),
+		}
 	}
 
 	// Add remote ID and key to conn state
@@ -230,5 +242,12 @@ func (ic *Conn) LocalPrivateKey() ci.PrivKey {
 	return ic.localPrivKey
 }
 
+// ConnState returns the state of the connection. Only the security protocol
+// is set, as no cryptographic handshake takes place.
+func (ic *Conn) ConnState() sec.ConnState {
+	return sec.ConnState{SecurityProtocol: ID}
+}
+
 var _ sec.SecureTransport = (*Transport)(nil)
 var _ sec.SecureConn = (*Conn)(nil)
+var _ sec.ConnStateConn = (*Conn)(nil)
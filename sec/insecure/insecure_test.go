@@ -45,6 +45,7 @@ func TestPeerIDMismatchInbound(t *testing.T) {
 	_, _, _, serverErr := connect(t, clientTpt, serverTpt, serverTpt.LocalPeer(), "a-random-peer")
 	require.Error(t, serverErr)
 	require.Contains(t, serverErr.Error(), "remote peer sent unexpected peer ID")
+	require.ErrorIs(t, serverErr, sec.ErrPeerMismatch)
 }
 
 func TestPeerIDMismatchOutbound(t *testing.T) {
@@ -54,6 +55,7 @@ func TestPeerIDMismatchOutbound(t *testing.T) {
 	_, _, clientErr, _ := connect(t, clientTpt, serverTpt, "a random peer", "")
 	require.Error(t, clientErr)
 	require.Contains(t, clientErr.Error(), "remote peer sent unexpected peer ID")
+	require.ErrorIs(t, clientErr, sec.ErrPeerMismatch)
 }
 
 func newTestTransport(t *testing.T, typ, bits int) *Transport {
@@ -17,6 +17,9 @@ type SecureConn interface {
 
 // A SecureTransport turns inbound and outbound unauthenticated,
 // plain-text, native connections into authenticated, encrypted connections.
+//
+// Implementations should report aborted handshakes with HandshakeErrors, and
+// may accept per-handshake options by implementing HandshakeOptionsTransport.
 type SecureTransport interface {
 	// SecureInbound secures an inbound connection.
 	// If p is empty, connections from any peer are accepted.
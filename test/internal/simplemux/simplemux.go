@@ -0,0 +1,348 @@
+// Package simplemux is a minimal stream multiplexer, used to check that the
+// test suites of the test packages pass against a correct implementation.
+//
+// Frames consist of a type byte, a stream ID and a payload length, both
+// big-endian uint32s, followed by the payload. There is no flow control: each
+// connection buffers all data received for its streams until it is read.
+package simplemux
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+)
+
+const (
+	frameOpen byte = iota
+	frameData
+	frameCloseWrite
+	frameReset
+)
+
+const headerLen = 9
+
+var (
+	errConnClosed   = errors.New("simplemux: connection closed")
+	errStreamClosed = errors.New("simplemux: stream closed")
+)
+
+// Transport is a network.Multiplexer creating simplemux connections.
+type Transport struct{}
+
+var _ network.Multiplexer = Transport{}
+
+// NewConn multiplexes c. The server side of the connection must pass
+// isServer, so that the two sides use different stream IDs.
+func (Transport) NewConn(c net.Conn, isServer bool, scope network.PeerScope) (network.MuxedConn, error) {
+	mc := &conn{
+		c:       c,
+		streams: make(map[uint32]*stream),
+		accept:  make(chan *stream, 256),
+		done:    make(chan struct{}),
+		nextID:  1,
+	}
+	if isServer {
+		mc.nextID = 2
+	}
+	go mc.readLoop()
+	return mc, nil
+}
+
+type conn struct {
+	c net.Conn
+
+	wmu sync.Mutex
+
+	mu      sync.Mutex
+	streams map[uint32]*stream
+	nextID  uint32
+	closed  bool
+
+	accept chan *stream
+	done   chan struct{}
+}
+
+func (c *conn) writeFrame(typ byte, id uint32, payload []byte) error {
+	buf := make([]byte, headerLen+len(payload))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:], id)
+	binary.BigEndian.PutUint32(buf[5:], uint32(len(payload)))
+	copy(buf[headerLen:], payload)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.c.Write(buf)
+	return err
+}
+
+func (c *conn) readLoop() {
+	defer c.shutdown()
+	header := make([]byte, headerLen)
+	for {
+		if _, err := io.ReadFull(c.c, header); err != nil {
+			return
+		}
+		typ, id := header[0], binary.BigEndian.Uint32(header[1:])
+		payload := make([]byte, binary.BigEndian.Uint32(header[5:]))
+		if _, err := io.ReadFull(c.c, payload); err != nil {
+			return
+		}
+
+		if typ == frameOpen {
+			s := c.newStream(id)
+			c.mu.Lock()
+			c.streams[id] = s
+			c.mu.Unlock()
+			select {
+			case c.accept <- s:
+			case <-c.done:
+				return
+			}
+			continue
+		}
+		c.mu.Lock()
+		s, ok := c.streams[id]
+		c.mu.Unlock()
+		if !ok {
+			// the stream was closed or reset locally
+			continue
+		}
+		switch typ {
+		case frameData:
+			s.push(payload, false, false)
+		case frameCloseWrite:
+			s.push(nil, true, false)
+		case frameReset:
+			s.push(nil, false, true)
+			c.removeStream(id)
+		}
+	}
+}
+
+// shutdown closes the connection and resets all its streams.
+func (c *conn) shutdown() {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+	}
+	streams := c.streams
+	c.streams = make(map[uint32]*stream)
+	c.mu.Unlock()
+
+	c.c.Close()
+	for _, s := range streams {
+		s.push(nil, false, true)
+	}
+}
+
+func (c *conn) newStream(id uint32) *stream {
+	return &stream{id: id, conn: c, notify: make(chan struct{}, 1)}
+}
+
+func (c *conn) removeStream(id uint32) {
+	c.mu.Lock()
+	delete(c.streams, id)
+	c.mu.Unlock()
+}
+
+func (c *conn) Close() error {
+	c.shutdown()
+	return nil
+}
+
+func (c *conn) IsClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *conn) OpenStream(ctx context.Context) (network.MuxedStream, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errConnClosed
+	}
+	id := c.nextID
+	c.nextID += 2
+	s := c.newStream(id)
+	c.streams[id] = s
+	c.mu.Unlock()
+
+	if err := c.writeFrame(frameOpen, id, nil); err != nil {
+		c.removeStream(id)
+		return nil, err
+	}
+	return s, nil
+}
+
+func (c *conn) AcceptStream() (network.MuxedStream, error) {
+	select {
+	case s := <-c.accept:
+		return s, nil
+	case <-c.done:
+		return nil, errConnClosed
+	}
+}
+
+type stream struct {
+	id   uint32
+	conn *conn
+
+	mu           sync.Mutex
+	buf          []byte
+	remoteClosed bool
+	reset        bool
+	readClosed   bool
+	writeClosed  bool
+	readDeadline time.Time
+
+	// notify is signaled when the read state changes.
+	notify chan struct{}
+}
+
+var _ network.MuxedStream = (*stream)(nil)
+
+func (s *stream) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// push records data received for the stream, the end of the remote's writes,
+// or a reset.
+func (s *stream) push(data []byte, eof, reset bool) {
+	s.mu.Lock()
+	if !s.readClosed {
+		s.buf = append(s.buf, data...)
+	}
+	s.remoteClosed = s.remoteClosed || eof
+	s.reset = s.reset || reset
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	for {
+		s.mu.Lock()
+		switch {
+		case s.reset:
+			s.mu.Unlock()
+			return 0, network.ErrReset
+		case s.readClosed:
+			s.mu.Unlock()
+			return 0, errStreamClosed
+		case len(s.buf) > 0:
+			n := copy(p, s.buf)
+			s.buf = s.buf[n:]
+			s.mu.Unlock()
+			return n, nil
+		case s.remoteClosed:
+			s.mu.Unlock()
+			return 0, io.EOF
+		}
+		deadline := s.readDeadline
+		s.mu.Unlock()
+
+		if deadline.IsZero() {
+			<-s.notify
+			continue
+		}
+		d := time.Until(deadline)
+		if d <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-s.notify:
+			timer.Stop()
+		case <-timer.C:
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	reset, closed := s.reset, s.writeClosed
+	s.mu.Unlock()
+	if reset {
+		return 0, network.ErrReset
+	}
+	if closed {
+		return 0, errStreamClosed
+	}
+	if err := s.conn.writeFrame(frameData, s.id, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *stream) CloseWrite() error {
+	s.mu.Lock()
+	if s.writeClosed || s.reset {
+		s.mu.Unlock()
+		return nil
+	}
+	s.writeClosed = true
+	s.mu.Unlock()
+	return s.conn.writeFrame(frameCloseWrite, s.id, nil)
+}
+
+func (s *stream) CloseRead() error {
+	s.mu.Lock()
+	s.readClosed = true
+	s.buf = nil
+	s.mu.Unlock()
+	s.signal()
+	return nil
+}
+
+func (s *stream) Close() error {
+	err := s.CloseWrite()
+	s.CloseRead()
+	s.conn.removeStream(s.id)
+	return err
+}
+
+func (s *stream) Reset() error {
+	s.mu.Lock()
+	if s.reset {
+		s.mu.Unlock()
+		return nil
+	}
+	s.reset = true
+	s.mu.Unlock()
+	s.signal()
+	s.conn.removeStream(s.id)
+	return s.conn.writeFrame(frameReset, s.id, nil)
+}
+
+func (s *stream) SetDeadline(t time.Time) error {
+	return s.SetReadDeadline(t)
+}
+
+func (s *stream) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	s.readDeadline = t
+	s.mu.Unlock()
+	s.signal()
+	return nil
+}
+
+// SetWriteDeadline is a no-op: without flow control, writes don't wait for
+// the remote.
+func (s *stream) SetWriteDeadline(t time.Time) error {
+	return nil
+}
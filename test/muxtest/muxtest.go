@@ -0,0 +1,314 @@
+// Package muxtest provides a test suite that implementations of
+// network.Multiplexer can run to check that they behave as the interface
+// documents.
+//
+// Usage, from a _test.go file of the implementation:
+//
+//	func TestMuxerCompliance(t *testing.T) {
+//		muxtest.TestSuite(t, DefaultTransport)
+//	}
+package muxtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+)
+
+var suite = []struct {
+	name string
+	run  func(t *testing.T, a, b network.MuxedConn)
+}{
+	{"Echo", testEcho},
+	{"CloseWrite", testCloseWrite},
+	{"Reset", testReset},
+	{"ManyStreams", testManyStreams},
+	{"ReadDeadline", testReadDeadline},
+	{"CloseConn", testCloseConn},
+}
+
+// TestSuite runs the compliance tests against connections created by mux,
+// over TCP connections on the loopback interface. Each test gets its own
+// pair of connections.
+func TestSuite(t *testing.T, mux network.Multiplexer) {
+	for _, tc := range suite {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := newConnPair(t, mux)
+			defer a.Close()
+			defer b.Close()
+			tc.run(t, a, b)
+		})
+	}
+}
+
+func newConnPair(t *testing.T, mux network.Multiplexer) (client, server network.MuxedConn) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	type result struct {
+		conn network.MuxedConn
+		err  error
+	}
+	serverCh := make(chan result, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			serverCh <- result{err: err}
+			return
+		}
+		mc, err := mux.NewConn(c, true, network.NullScope)
+		if err != nil {
+			c.Close()
+		}
+		serverCh <- result{mc, err}
+	}()
+
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err = mux.NewConn(c, false, network.NullScope)
+	if err != nil {
+		c.Close()
+		t.Fatal(err)
+	}
+	res := <-serverCh
+	if res.err != nil {
+		client.Close()
+		t.Fatal(res.err)
+	}
+	return client, res.conn
+}
+
+// openPair opens a stream on a and accepts it on b. A byte is written on the
+// stream, as some muxers only announce streams to the remote once data is
+// sent, and consumed on the accepting side.
+func openPair(t *testing.T, a, b network.MuxedConn) (local, remote network.MuxedStream) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	local, err := a.OpenStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.Write([]byte{0}); err != nil {
+		t.Fatal(err)
+	}
+	remote, err = b.AcceptStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(remote, make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+	return local, remote
+}
+
+func testEcho(t *testing.T, a, b network.MuxedConn) {
+	local, remote := openPair(t, a, b)
+	defer local.Close()
+	defer remote.Close()
+
+	msg := []byte("hello world")
+	go func() {
+		// echo everything back
+		io.Copy(remote, remote)
+		remote.CloseWrite()
+	}()
+
+	if _, err := local.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	local.SetReadDeadline(time.Now().Add(10 * time.Second))
+	resp, err := io.ReadAll(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(resp, msg) {
+		t.Fatalf("expected %q, got %q", msg, resp)
+	}
+}
+
+func testCloseWrite(t *testing.T, a, b network.MuxedConn) {
+	local, remote := openPair(t, a, b)
+	defer local.Close()
+	defer remote.Close()
+
+	if err := local.CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	remote.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if n, err := remote.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Fatalf("expected EOF after CloseWrite, got %d bytes and %v", n, err)
+	}
+
+	// the other direction must still be usable
+	msg := []byte("still open")
+	if _, err := remote.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, len(msg))
+	local.SetReadDeadline(time.Now().Add(10 * time.Second))
+	if _, err := io.ReadFull(local, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, msg) {
+		t.Fatalf("expected %q, got %q", msg, buf)
+	}
+}
+
+func testReset(t *testing.T, a, b network.MuxedConn) {
+	local, remote := openPair(t, a, b)
+	defer remote.Close()
+
+	if err := local.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected error reading from a reset stream")
+	}
+
+	remote.SetReadDeadline(time.Now().Add(10 * time.Second))
+	_, err := remote.Read(make([]byte, 1))
+	if err == nil || err == io.EOF {
+		t.Fatalf("expected a reset error on the remote side, got %v", err)
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatal("remote side didn't learn about the reset")
+	}
+}
+
+func testManyStreams(t *testing.T, a, b network.MuxedConn) {
+	const streams = 50
+	msg := bytes.Repeat([]byte("x"), 4096)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*streams)
+
+	go func() {
+		for i := 0; i < streams; i++ {
+			s, err := b.AcceptStream()
+			if err != nil {
+				errs <- err
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer s.Close()
+				if _, err := io.Copy(s, s); err != nil {
+					errs <- err
+					return
+				}
+				if err := s.CloseWrite(); err != nil {
+					errs <- err
+				}
+			}()
+		}
+	}()
+
+	var clients sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		clients.Add(1)
+		go func() {
+			defer clients.Done()
+			s, err := a.OpenStream(context.Background())
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer s.Close()
+			s.SetDeadline(time.Now().Add(30 * time.Second))
+			if _, err := s.Write(msg); err != nil {
+				errs <- err
+				return
+			}
+			if err := s.CloseWrite(); err != nil {
+				errs <- err
+				return
+			}
+			resp, err := io.ReadAll(s)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !bytes.Equal(resp, msg) {
+				errs <- errors.New("echoed data doesn't match")
+			}
+		}()
+	}
+	clients.Wait()
+	wg.Wait()
+
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func testReadDeadline(t *testing.T, a, b network.MuxedConn) {
+	local, remote := openPair(t, a, b)
+	defer local.Close()
+	defer remote.Close()
+
+	if err := local.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := local.Read(make([]byte, 1))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected read to time out")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("read deadline wasn't honored")
+	}
+}
+
+func testCloseConn(t *testing.T, a, b network.MuxedConn) {
+	local, remote := openPair(t, a, b)
+	defer local.Close()
+	defer remote.Close()
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for !a.IsClosed() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected connection to be closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := a.OpenStream(context.Background()); err == nil {
+		t.Fatal("expected OpenStream on a closed connection to fail")
+	}
+
+	// streams on the remote side must eventually fail too
+	remote.SetReadDeadline(deadline)
+	if _, err := io.ReadAll(remote); errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatal("remote stream wasn't closed with the connection")
+	}
+}
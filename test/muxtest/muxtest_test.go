@@ -0,0 +1,12 @@
+package muxtest_test
+
+import (
+	"testing"
+
+	"github.com/libp2p/go-libp2p-core/test/internal/simplemux"
+	"github.com/libp2p/go-libp2p-core/test/muxtest"
+)
+
+func TestSuite(t *testing.T) {
+	muxtest.TestSuite(t, simplemux.Transport{})
+}
@@ -0,0 +1,400 @@
+// Package peerstoretest provides a test suite that implementations of
+// peerstore.Peerstore can run to check that they behave as the interface
+// documents.
+//
+// Usage, from a _test.go file of the implementation:
+//
+//	func TestPeerstoreCompliance(t *testing.T) {
+//		peerstoretest.TestSuite(t, func() (peerstore.Peerstore, func()) {
+//			ps := NewPeerstore()
+//			return ps, func() { ps.Close() }
+//		})
+//	}
+package peerstoretest
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/test"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Factory returns a new, empty Peerstore, and a function releasing it.
+type Factory func() (ps peerstore.Peerstore, closeFunc func())
+
+var suite = []struct {
+	name string
+	run  func(t *testing.T, ps peerstore.Peerstore)
+}{
+	{"AddAddrs", testAddAddrs},
+	{"AddrsExpire", testAddrsExpire},
+	{"SetAddrs", testSetAddrs},
+	{"UpdateAddrs", testUpdateAddrs},
+	{"ClearAddrs", testClearAddrs},
+	{"AddrStream", testAddrStream},
+	{"PeersWithAddr", testPeersWithAddr},
+	{"KeyBook", testKeyBook},
+	{"ProtoBook", testProtoBook},
+	{"Metadata", testMetadata},
+	{"Metrics", testMetrics},
+	{"PeerInfo", testPeerInfo},
+	{"RemovePeer", testRemovePeer},
+}
+
+// TestSuite runs the compliance tests against Peerstores returned by
+// factory. Each test gets its own Peerstore.
+func TestSuite(t *testing.T, factory Factory) {
+	for _, tc := range suite {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ps, closeFunc := factory()
+			defer closeFunc()
+			tc.run(t, ps)
+		})
+	}
+}
+
+func assertStrings(t *testing.T, exp, act []string) {
+	t.Helper()
+	e := append([]string(nil), exp...)
+	a := append([]string(nil), act...)
+	sort.Strings(e)
+	sort.Strings(a)
+	if len(e) != len(a) {
+		t.Fatalf("expected %v, got %v", e, a)
+	}
+	for i := range e {
+		if e[i] != a[i] {
+			t.Fatalf("expected %v, got %v", e, a)
+		}
+	}
+}
+
+func containsPeer(ids peer.IDSlice, p peer.ID) bool {
+	for _, id := range ids {
+		if id == p {
+			return true
+		}
+	}
+	return false
+}
+
+func testAddAddrs(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+	addrs := test.GenerateTestAddrs(4)
+
+	ps.AddAddrs(p, addrs[:2], time.Hour)
+	ps.AddAddr(p, addrs[2], time.Hour)
+	// adding the same address again must not duplicate it
+	ps.AddAddrs(p, addrs[:3], time.Hour)
+	test.AssertAddressesEqual(t, addrs[:3], ps.Addrs(p))
+
+	// adding an address with a shorter ttl must not shorten it
+	ps.AddAddr(p, addrs[0], time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	test.AssertAddressesEqual(t, addrs[:3], ps.Addrs(p))
+
+	if !containsPeer(ps.PeersWithAddrs(), p) {
+		t.Fatal("expected peer in PeersWithAddrs")
+	}
+	if other := test.RandPeerIDFatal(t); len(ps.Addrs(other)) != 0 {
+		t.Fatal("expected no addresses for an unknown peer")
+	}
+}
+
+func testAddrsExpire(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+	addrs := test.GenerateTestAddrs(2)
+
+	ps.AddAddr(p, addrs[0], time.Hour)
+	ps.AddAddr(p, addrs[1], 50*time.Millisecond)
+	test.AssertAddressesEqual(t, addrs, ps.Addrs(p))
+
+	time.Sleep(100 * time.Millisecond)
+	test.AssertAddressesEqual(t, addrs[:1], ps.Addrs(p))
+}
+
+func testSetAddrs(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+	addrs := test.GenerateTestAddrs(3)
+
+	ps.AddAddrs(p, addrs, time.Hour)
+
+	// SetAddrs overrides the ttl, even if shorter
+	ps.SetAddr(p, addrs[0], 50*time.Millisecond)
+	// a zero ttl removes the address
+	ps.SetAddr(p, addrs[1], 0)
+	test.AssertAddressesEqual(t, []ma.Multiaddr{addrs[0], addrs[2]}, ps.Addrs(p))
+
+	time.Sleep(100 * time.Millisecond)
+	test.AssertAddressesEqual(t, addrs[2:], ps.Addrs(p))
+}
+
+func testUpdateAddrs(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+	addrs := test.GenerateTestAddrs(2)
+
+	ps.AddAddr(p, addrs[0], time.Hour)
+	ps.AddAddr(p, addrs[1], peerstore.PermanentAddrTTL)
+	ps.UpdateAddrs(p, time.Hour, 50*time.Millisecond)
+	test.AssertAddressesEqual(t, addrs, ps.Addrs(p))
+
+	time.Sleep(100 * time.Millisecond)
+	test.AssertAddressesEqual(t, addrs[1:], ps.Addrs(p))
+}
+
+func testClearAddrs(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+	ps.AddAddrs(p, test.GenerateTestAddrs(3), time.Hour)
+	ps.ClearAddrs(p)
+	if addrs := ps.Addrs(p); len(addrs) != 0 {
+		t.Fatalf("expected no addresses after ClearAddrs, got %v", addrs)
+	}
+}
+
+func testAddrStream(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+	addrs := test.GenerateTestAddrs(4)
+
+	ps.AddAddrs(p, addrs[:2], time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ch := ps.AddrStream(ctx, p)
+
+	ps.AddAddrs(p, addrs[2:], time.Hour)
+
+	var received []ma.Multiaddr
+	for len(received) < len(addrs) {
+		select {
+		case a, ok := <-ch:
+			if !ok {
+				t.Fatalf("address stream closed after %d addresses", len(received))
+			}
+			received = append(received, a)
+		case <-ctx.Done():
+			t.Fatalf("timed out after receiving %d addresses", len(received))
+		}
+	}
+	test.AssertAddressesEqual(t, addrs, received)
+}
+
+func testPeersWithAddr(t *testing.T, ps peerstore.Peerstore) {
+	p1 := test.RandPeerIDFatal(t)
+	p2 := test.RandPeerIDFatal(t)
+	ps.AddAddr(p1, ma.StringCast("/ip4/1.2.3.4/tcp/1"), time.Hour)
+	ps.AddAddr(p2, ma.StringCast("/ip4/1.2.3.4/udp/1/quic"), time.Hour)
+	ps.AddAddr(p2, ma.StringCast("/ip4/5.6.7.8/tcp/1"), time.Hour)
+
+	check := func(prefix string, exp ...peer.ID) {
+		t.Helper()
+		peers := peerstore.PeersWithAddr(ps, ma.StringCast(prefix))
+		if len(peers) != len(exp) {
+			t.Fatalf("expected %d peers with %s, got %v", len(exp), prefix, peers)
+		}
+		for _, p := range exp {
+			if !containsPeer(peers, p) {
+				t.Fatalf("expected %s to have an address matching %s", p, prefix)
+			}
+		}
+	}
+	check("/ip4/1.2.3.4", p1, p2)
+	check("/ip4/1.2.3.4/tcp/1", p1)
+	check("/ip4/5.6.7.8", p2)
+	check("/ip4/9.9.9.9")
+
+	ps.ClearAddrs(p1)
+	check("/ip4/1.2.3.4", p2)
+}
+
+func testKeyBook(t *testing.T, ps peerstore.Peerstore) {
+	priv, pub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ps.AddPubKey(p, pub); err != nil {
+		t.Fatal(err)
+	}
+	if k := ps.PubKey(p); k == nil || !k.Equals(pub) {
+		t.Fatal("stored public key doesn't match")
+	}
+	if err := ps.AddPrivKey(p, priv); err != nil {
+		t.Fatal(err)
+	}
+	if k := ps.PrivKey(p); k == nil || !k.Equals(priv) {
+		t.Fatal("stored private key doesn't match")
+	}
+	if !containsPeer(ps.PeersWithKeys(), p) {
+		t.Fatal("expected peer in PeersWithKeys")
+	}
+
+	// keys that don't match the peer ID must be rejected
+	otherPriv, otherPub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := test.RandPeerIDFatal(t)
+	if err := ps.AddPubKey(other, otherPub); err == nil {
+		t.Fatal("expected error when adding a public key not matching the peer ID")
+	}
+	if err := ps.AddPrivKey(other, otherPriv); err == nil {
+		t.Fatal("expected error when adding a private key not matching the peer ID")
+	}
+}
+
+func testProtoBook(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+
+	if err := ps.SetProtocols(p, "/a/1", "/b/1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.AddProtocols(p, "/c/1"); err != nil {
+		t.Fatal(err)
+	}
+	protos, err := ps.GetProtocols(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, []string{"/a/1", "/b/1", "/c/1"}, protos)
+
+	supported, err := ps.SupportsProtocols(p, "/a/1", "/x/1", "/c/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, []string{"/a/1", "/c/1"}, supported)
+
+	first, err := ps.FirstSupportedProtocol(p, "/x/1", "/b/1", "/a/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "/b/1" {
+		t.Fatalf("expected /b/1 as first supported protocol, got %q", first)
+	}
+	first, err = ps.FirstSupportedProtocol(p, "/x/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "" {
+		t.Fatalf("expected no supported protocol, got %q", first)
+	}
+
+	if err := ps.RemoveProtocols(p, "/a/1"); err != nil {
+		t.Fatal(err)
+	}
+	protos, err = ps.GetProtocols(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, []string{"/b/1", "/c/1"}, protos)
+
+	// SetProtocols replaces the protocols
+	if err := ps.SetProtocols(p, "/d/1"); err != nil {
+		t.Fatal(err)
+	}
+	protos, err = ps.GetProtocols(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertStrings(t, []string{"/d/1"}, protos)
+}
+
+func testMetadata(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+
+	if _, err := ps.Get(p, "AgentVersion"); err != peerstore.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for a missing key, got %v", err)
+	}
+	if err := ps.Put(p, "AgentVersion", "test/1.0"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := ps.Get(p, "AgentVersion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := v.(string); !ok || s != "test/1.0" {
+		t.Fatalf("expected %q, got %v", "test/1.0", v)
+	}
+}
+
+func testMetrics(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+
+	if l := ps.LatencyEWMA(p); l != 0 {
+		t.Fatalf("expected no latency for an unknown peer, got %s", l)
+	}
+	for i := 0; i < 10; i++ {
+		ps.RecordLatency(p, 100*time.Millisecond)
+	}
+	if l := ps.LatencyEWMA(p); l <= 0 || l > 100*time.Millisecond {
+		t.Fatalf("expected latency in (0, 100ms], got %s", l)
+	}
+}
+
+func testPeerInfo(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+	addrs := test.GenerateTestAddrs(2)
+	ps.AddAddrs(p, addrs, time.Hour)
+
+	info := ps.PeerInfo(p)
+	if info.ID != p {
+		t.Fatalf("expected peer %s, got %s", p, info.ID)
+	}
+	test.AssertAddressesEqual(t, addrs, info.Addrs)
+
+	_, pub, err := test.RandTestKeyPair(crypto.Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPeer, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.AddPubKey(keyPeer, pub); err != nil {
+		t.Fatal(err)
+	}
+
+	peers := ps.Peers()
+	if !containsPeer(peers, p) || !containsPeer(peers, keyPeer) {
+		t.Fatalf("expected Peers to contain peers from all books, got %v", peers)
+	}
+}
+
+func testRemovePeer(t *testing.T, ps peerstore.Peerstore) {
+	p := test.RandPeerIDFatal(t)
+
+	if err := ps.Put(p, "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.SetProtocols(p, "/a/1"); err != nil {
+		t.Fatal(err)
+	}
+	ps.RecordLatency(p, time.Millisecond)
+
+	ps.RemovePeer(p)
+
+	if _, err := ps.Get(p, "key"); err != peerstore.ErrNotFound {
+		t.Fatalf("expected ErrNotFound after RemovePeer, got %v", err)
+	}
+	protos, err := ps.GetProtocols(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(protos) != 0 {
+		t.Fatalf("expected no protocols after RemovePeer, got %v", protos)
+	}
+	if l := ps.LatencyEWMA(p); l != 0 {
+		t.Fatalf("expected no latency after RemovePeer, got %s", l)
+	}
+}
@@ -0,0 +1,418 @@
+package peerstoretest_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/test/peerstoretest"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+type expiringAddr struct {
+	addr ma.Multiaddr
+	ttl  time.Duration
+	// expires is zero for addresses that never expire.
+	expires time.Time
+}
+
+func (a *expiringAddr) expired(now time.Time) bool {
+	return !a.expires.IsZero() && !now.Before(a.expires)
+}
+
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	exp := now.Add(ttl)
+	if exp.Before(now) {
+		// overflow, e.g. for peerstore.PermanentAddrTTL
+		return time.Time{}
+	}
+	return exp
+}
+
+// expiresAfter returns true if a expires after b, zero meaning never.
+func expiresAfter(a, b time.Time) bool {
+	if b.IsZero() {
+		return false
+	}
+	return a.IsZero() || a.After(b)
+}
+
+// addrSub is the subscription of an AddrStream.
+type addrSub struct {
+	pending []ma.Multiaddr
+	notify  chan struct{}
+}
+
+// memPeerstore is a minimal in-memory peerstore.Peerstore.
+type memPeerstore struct {
+	mu        sync.Mutex
+	addrs     map[peer.ID]map[string]*expiringAddr
+	subs      map[peer.ID]map[*addrSub]struct{}
+	pubKeys   map[peer.ID]ic.PubKey
+	privKeys  map[peer.ID]ic.PrivKey
+	metadata  map[peer.ID]map[string]interface{}
+	protocols map[peer.ID]map[string]struct{}
+	latencies map[peer.ID]time.Duration
+}
+
+var _ peerstore.Peerstore = (*memPeerstore)(nil)
+
+func newMemPeerstore() *memPeerstore {
+	return &memPeerstore{
+		addrs:     make(map[peer.ID]map[string]*expiringAddr),
+		subs:      make(map[peer.ID]map[*addrSub]struct{}),
+		pubKeys:   make(map[peer.ID]ic.PubKey),
+		privKeys:  make(map[peer.ID]ic.PrivKey),
+		metadata:  make(map[peer.ID]map[string]interface{}),
+		protocols: make(map[peer.ID]map[string]struct{}),
+		latencies: make(map[peer.ID]time.Duration),
+	}
+}
+
+func (ps *memPeerstore) Close() error {
+	return nil
+}
+
+// AddrBook
+
+func (ps *memPeerstore) AddAddr(p peer.ID, addr ma.Multiaddr, ttl time.Duration) {
+	ps.AddAddrs(p, []ma.Multiaddr{addr}, ttl)
+}
+
+func (ps *memPeerstore) AddAddrs(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration) {
+	ps.setAddrs(p, addrs, ttl, false)
+}
+
+func (ps *memPeerstore) SetAddr(p peer.ID, addr ma.Multiaddr, ttl time.Duration) {
+	ps.SetAddrs(p, []ma.Multiaddr{addr}, ttl)
+}
+
+func (ps *memPeerstore) SetAddrs(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration) {
+	ps.setAddrs(p, addrs, ttl, true)
+}
+
+func (ps *memPeerstore) setAddrs(p peer.ID, addrs []ma.Multiaddr, ttl time.Duration, override bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	now := time.Now()
+	book, ok := ps.addrs[p]
+	if !ok {
+		book = make(map[string]*expiringAddr)
+		ps.addrs[p] = book
+	}
+	var added []ma.Multiaddr
+	for _, addr := range addrs {
+		key := string(addr.Bytes())
+		a, ok := book[key]
+		if ok && a.expired(now) {
+			delete(book, key)
+			ok = false
+		}
+		switch {
+		case override && ttl <= 0:
+			delete(book, key)
+		case ttl <= 0:
+		case !ok:
+			book[key] = &expiringAddr{addr: addr, ttl: ttl, expires: expiry(now, ttl)}
+			added = append(added, addr)
+		default:
+			// AddAddrs only extends the ttl
+			if exp := expiry(now, ttl); override || expiresAfter(exp, a.expires) {
+				a.ttl, a.expires = ttl, exp
+			}
+		}
+	}
+	for sub := range ps.subs[p] {
+		sub.pending = append(sub.pending, added...)
+		select {
+		case sub.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (ps *memPeerstore) UpdateAddrs(p peer.ID, oldTTL time.Duration, newTTL time.Duration) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	now := time.Now()
+	for key, a := range ps.addrs[p] {
+		if a.ttl != oldTTL {
+			continue
+		}
+		if newTTL <= 0 {
+			delete(ps.addrs[p], key)
+			continue
+		}
+		a.ttl, a.expires = newTTL, expiry(now, newTTL)
+	}
+}
+
+func (ps *memPeerstore) Addrs(p peer.ID) []ma.Multiaddr {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.validAddrs(p, time.Now())
+}
+
+func (ps *memPeerstore) validAddrs(p peer.ID, now time.Time) []ma.Multiaddr {
+	var addrs []ma.Multiaddr
+	for _, a := range ps.addrs[p] {
+		if !a.expired(now) {
+			addrs = append(addrs, a.addr)
+		}
+	}
+	return addrs
+}
+
+func (ps *memPeerstore) AddrStream(ctx context.Context, p peer.ID) <-chan ma.Multiaddr {
+	ps.mu.Lock()
+	sub := &addrSub{pending: ps.validAddrs(p, time.Now()), notify: make(chan struct{}, 1)}
+	if ps.subs[p] == nil {
+		ps.subs[p] = make(map[*addrSub]struct{})
+	}
+	ps.subs[p][sub] = struct{}{}
+	ps.mu.Unlock()
+
+	out := make(chan ma.Multiaddr)
+	go func() {
+		defer func() {
+			ps.mu.Lock()
+			delete(ps.subs[p], sub)
+			ps.mu.Unlock()
+			close(out)
+		}()
+		for {
+			ps.mu.Lock()
+			pending := sub.pending
+			sub.pending = nil
+			ps.mu.Unlock()
+			for _, a := range pending {
+				select {
+				case out <- a:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-sub.notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (ps *memPeerstore) ClearAddrs(p peer.ID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.addrs, p)
+}
+
+func (ps *memPeerstore) PeersWithAddrs() peer.IDSlice {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	now := time.Now()
+	var peers peer.IDSlice
+	for p := range ps.addrs {
+		if len(ps.validAddrs(p, now)) > 0 {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// KeyBook
+
+func (ps *memPeerstore) PubKey(p peer.ID) ic.PubKey {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.pubKeys[p]
+}
+
+func (ps *memPeerstore) AddPubKey(p peer.ID, pk ic.PubKey) error {
+	if !p.MatchesPublicKey(pk) {
+		return errors.New("public key doesn't match peer ID")
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.pubKeys[p] = pk
+	return nil
+}
+
+func (ps *memPeerstore) PrivKey(p peer.ID) ic.PrivKey {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.privKeys[p]
+}
+
+func (ps *memPeerstore) AddPrivKey(p peer.ID, sk ic.PrivKey) error {
+	if !p.MatchesPrivateKey(sk) {
+		return errors.New("private key doesn't match peer ID")
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.privKeys[p] = sk
+	return nil
+}
+
+func (ps *memPeerstore) PeersWithKeys() peer.IDSlice {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	var peers peer.IDSlice
+	for p := range ps.pubKeys {
+		peers = append(peers, p)
+	}
+	for p := range ps.privKeys {
+		if _, ok := ps.pubKeys[p]; !ok {
+			peers = append(peers, p)
+		}
+	}
+	return peers
+}
+
+// PeerMetadata
+
+func (ps *memPeerstore) Get(p peer.ID, key string) (interface{}, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	v, ok := ps.metadata[p][key]
+	if !ok {
+		return nil, peerstore.ErrNotFound
+	}
+	return v, nil
+}
+
+func (ps *memPeerstore) Put(p peer.ID, key string, val interface{}) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.metadata[p] == nil {
+		ps.metadata[p] = make(map[string]interface{})
+	}
+	ps.metadata[p][key] = val
+	return nil
+}
+
+// Metrics
+
+func (ps *memPeerstore) RecordLatency(p peer.ID, next time.Duration) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ewma, ok := ps.latencies[p]
+	if !ok {
+		ps.latencies[p] = next
+		return
+	}
+	s := peerstore.DefaultLatencyConfig.Smoothing
+	ps.latencies[p] = time.Duration(s*float64(next) + (1-s)*float64(ewma))
+}
+
+func (ps *memPeerstore) LatencyEWMA(p peer.ID) time.Duration {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.latencies[p]
+}
+
+// ProtoBook
+
+func (ps *memPeerstore) GetProtocols(p peer.ID) ([]string, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	protos := make([]string, 0, len(ps.protocols[p]))
+	for proto := range ps.protocols[p] {
+		protos = append(protos, proto)
+	}
+	return protos, nil
+}
+
+func (ps *memPeerstore) AddProtocols(p peer.ID, protos ...string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if ps.protocols[p] == nil {
+		ps.protocols[p] = make(map[string]struct{})
+	}
+	for _, proto := range protos {
+		ps.protocols[p][proto] = struct{}{}
+	}
+	return nil
+}
+
+func (ps *memPeerstore) SetProtocols(p peer.ID, protos ...string) error {
+	ps.mu.Lock()
+	delete(ps.protocols, p)
+	ps.mu.Unlock()
+	return ps.AddProtocols(p, protos...)
+}
+
+func (ps *memPeerstore) RemoveProtocols(p peer.ID, protos ...string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for _, proto := range protos {
+		delete(ps.protocols[p], proto)
+	}
+	return nil
+}
+
+func (ps *memPeerstore) SupportsProtocols(p peer.ID, protos ...string) ([]string, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	var supported []string
+	for _, proto := range protos {
+		if _, ok := ps.protocols[p][proto]; ok {
+			supported = append(supported, proto)
+		}
+	}
+	return supported, nil
+}
+
+func (ps *memPeerstore) FirstSupportedProtocol(p peer.ID, protos ...string) (string, error) {
+	supported, err := ps.SupportsProtocols(p, protos...)
+	if err != nil || len(supported) == 0 {
+		return "", err
+	}
+	return supported[0], nil
+}
+
+// RemovePeer removes the peer from all books but the AddrBook.
+func (ps *memPeerstore) RemovePeer(p peer.ID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.pubKeys, p)
+	delete(ps.privKeys, p)
+	delete(ps.metadata, p)
+	delete(ps.protocols, p)
+	delete(ps.latencies, p)
+}
+
+func (ps *memPeerstore) PeerInfo(p peer.ID) peer.AddrInfo {
+	return peer.AddrInfo{ID: p, Addrs: ps.Addrs(p)}
+}
+
+func (ps *memPeerstore) Peers() peer.IDSlice {
+	set := make(map[peer.ID]struct{})
+	for _, p := range ps.PeersWithAddrs() {
+		set[p] = struct{}{}
+	}
+	for _, p := range ps.PeersWithKeys() {
+		set[p] = struct{}{}
+	}
+	peers := make(peer.IDSlice, 0, len(set))
+	for p := range set {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+func TestSuite(t *testing.T) {
+	peerstoretest.TestSuite(t, func() (peerstore.Peerstore, func()) {
+		ps := newMemPeerstore()
+		return ps, func() { ps.Close() }
+	})
+}
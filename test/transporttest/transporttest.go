@@ -0,0 +1,276 @@
+// Package transporttest provides a test suite that implementations of
+// transport.Transport can run to check that they behave as the interface
+// documents.
+//
+// Usage, from a _test.go file of the implementation:
+//
+//	func TestTransportCompliance(t *testing.T) {
+//		ta, tb := newTransport(t, keyA), newTransport(t, keyB)
+//		addr := ma.StringCast("/ip4/127.0.0.1/tcp/0")
+//		transporttest.TestSuite(t, ta, tb, addr, peerA)
+//	}
+package transporttest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/transport"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var suite = []struct {
+	name string
+	run  func(t *testing.T, ta, tb transport.Transport, addr ma.Multiaddr, peerA peer.ID)
+}{
+	{"Protocols", testProtocols},
+	{"Echo", testEcho},
+	{"ManyStreams", testManyStreams},
+	{"CanceledDial", testCanceledDial},
+	{"CloseListener", testCloseListener},
+}
+
+// TestSuite runs the compliance tests against two transports: ta, run by
+// peerA, listens on addr and tb dials it. addr may use a wildcard port.
+func TestSuite(t *testing.T, ta, tb transport.Transport, addr ma.Multiaddr, peerA peer.ID) {
+	for _, tc := range suite {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, ta, tb, addr, peerA)
+		})
+	}
+}
+
+func listen(t *testing.T, tpt transport.Transport, addr ma.Multiaddr) transport.Listener {
+	t.Helper()
+	l, err := tpt.Listen(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// connect dials the listener of peerA from tb, and returns both ends of the
+// connection.
+func connect(t *testing.T, l transport.Listener, tb transport.Transport, peerA peer.ID) (dialed, accepted transport.CapableConn) {
+	t.Helper()
+
+	type result struct {
+		conn transport.CapableConn
+		err  error
+	}
+	acceptCh := make(chan result, 1)
+	go func() {
+		c, err := l.Accept()
+		acceptCh <- result{c, err}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dialed, err := tb.Dial(ctx, l.Multiaddr(), peerA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := <-acceptCh
+	if res.err != nil {
+		dialed.Close()
+		t.Fatal(res.err)
+	}
+	return dialed, res.conn
+}
+
+func testProtocols(t *testing.T, ta, tb transport.Transport, addr ma.Multiaddr, peerA peer.ID) {
+	if len(ta.Protocols()) == 0 {
+		t.Fatal("transport must handle at least one protocol")
+	}
+
+	l := listen(t, ta, addr)
+	defer l.Close()
+
+	if !tb.CanDial(l.Multiaddr()) {
+		t.Fatalf("transport can't dial its own listen address %s", l.Multiaddr())
+	}
+	if l.Addr() == nil {
+		t.Fatal("listener must have a net.Addr")
+	}
+}
+
+func testEcho(t *testing.T, ta, tb transport.Transport, addr ma.Multiaddr, peerA peer.ID) {
+	l := listen(t, ta, addr)
+	defer l.Close()
+
+	dialed, accepted := connect(t, l, tb, peerA)
+	defer dialed.Close()
+	defer accepted.Close()
+
+	if dialed.RemotePeer() != peerA {
+		t.Fatalf("expected dialed connection to peer %s, got %s", peerA, dialed.RemotePeer())
+	}
+	if accepted.LocalPeer() != peerA {
+		t.Fatalf("expected accepted connection from peer %s, got %s", peerA, accepted.LocalPeer())
+	}
+	if dialed.Transport() != tb || accepted.Transport() != ta {
+		t.Fatal("connection doesn't return its transport")
+	}
+
+	msg := []byte("hello world")
+	errCh := make(chan error, 1)
+	go func() {
+		s, err := accepted.AcceptStream()
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer s.Close()
+		if _, err := io.Copy(s, s); err != nil {
+			errCh <- err
+			return
+		}
+		errCh <- s.CloseWrite()
+	}()
+
+	s, err := dialed.OpenStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := s.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(resp, msg) {
+		t.Fatalf("expected %q, got %q", msg, resp)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testManyStreams(t *testing.T, ta, tb transport.Transport, addr ma.Multiaddr, peerA peer.ID) {
+	const streams = 20
+	msg := bytes.Repeat([]byte("x"), 4096)
+
+	l := listen(t, ta, addr)
+	defer l.Close()
+
+	dialed, accepted := connect(t, l, tb, peerA)
+	defer dialed.Close()
+	defer accepted.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*streams)
+
+	go func() {
+		for i := 0; i < streams; i++ {
+			s, err := accepted.AcceptStream()
+			if err != nil {
+				errs <- err
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer s.Close()
+				if _, err := io.Copy(s, s); err != nil {
+					errs <- err
+					return
+				}
+				if err := s.CloseWrite(); err != nil {
+					errs <- err
+				}
+			}()
+		}
+	}()
+
+	var clients sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		clients.Add(1)
+		go func() {
+			defer clients.Done()
+			s, err := dialed.OpenStream(context.Background())
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer s.Close()
+			s.SetDeadline(time.Now().Add(30 * time.Second))
+			if _, err := s.Write(msg); err != nil {
+				errs <- err
+				return
+			}
+			if err := s.CloseWrite(); err != nil {
+				errs <- err
+				return
+			}
+			resp, err := io.ReadAll(s)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !bytes.Equal(resp, msg) {
+				errs <- errors.New("echoed data doesn't match")
+			}
+		}()
+	}
+	clients.Wait()
+	wg.Wait()
+
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func testCanceledDial(t *testing.T, ta, tb transport.Transport, addr ma.Multiaddr, peerA peer.ID) {
+	l := listen(t, ta, addr)
+	defer l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c, err := tb.Dial(ctx, l.Multiaddr(), peerA)
+	if err == nil {
+		c.Close()
+		t.Fatal("expected dial with a canceled context to fail")
+	}
+}
+
+func testCloseListener(t *testing.T, ta, tb transport.Transport, addr ma.Multiaddr, peerA peer.ID) {
+	l := listen(t, ta, addr)
+
+	errCh := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err == nil {
+			c.Close()
+		}
+		errCh <- err
+	}()
+
+	// give Accept a chance to block
+	time.Sleep(10 * time.Millisecond)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("expected Accept to fail after Close")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close didn't unblock Accept")
+	}
+}
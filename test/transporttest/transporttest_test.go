@@ -0,0 +1,187 @@
+package transporttest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	ic "github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/test"
+	"github.com/libp2p/go-libp2p-core/test/internal/simplemux"
+	"github.com/libp2p/go-libp2p-core/test/transporttest"
+	"github.com/libp2p/go-libp2p-core/transport"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// memTransport is a transport connecting to the memListeners of the same
+// process over net.Pipes, multiplexed with simplemux. Its addresses are
+// /ip4/127.0.0.1/tcp/<port> addresses, but no sockets are used.
+type memTransport struct {
+	priv ic.PrivKey
+	id   peer.ID
+}
+
+var listeners = struct {
+	sync.Mutex
+	byPort   map[int]*memListener
+	nextPort int
+}{byPort: make(map[int]*memListener), nextPort: 10000}
+
+func newMemTransport(t *testing.T) *memTransport {
+	priv, _, err := test.RandTestKeyPair(ic.Ed25519, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := peer.IDFromPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &memTransport{priv: priv, id: id}
+}
+
+func (t *memTransport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (transport.CapableConn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	port, err := portOf(raddr)
+	if err != nil {
+		return nil, err
+	}
+	listeners.Lock()
+	l, ok := listeners.byPort[port]
+	listeners.Unlock()
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	if l.t.id != p {
+		return nil, fmt.Errorf("expected peer %s, got %s", p, l.t.id)
+	}
+
+	a, b := net.Pipe()
+	local, _ := simplemux.Transport{}.NewConn(a, false, network.NullScope)
+	remote, _ := simplemux.Transport{}.NewConn(b, true, network.NullScope)
+	select {
+	case l.conns <- &memConn{MuxedConn: remote, t: l.t, remote: t, laddr: raddr, raddr: ma.StringCast("/ip4/127.0.0.1/tcp/0")}:
+	case <-l.done:
+		local.Close()
+		remote.Close()
+		return nil, errors.New("connection refused")
+	case <-ctx.Done():
+		local.Close()
+		remote.Close()
+		return nil, ctx.Err()
+	}
+	return &memConn{MuxedConn: local, t: t, remote: l.t, laddr: ma.StringCast("/ip4/127.0.0.1/tcp/0"), raddr: raddr}, nil
+}
+
+func (t *memTransport) CanDial(addr ma.Multiaddr) bool {
+	_, err := portOf(addr)
+	return err == nil
+}
+
+func (t *memTransport) Listen(laddr ma.Multiaddr) (transport.Listener, error) {
+	port, err := portOf(laddr)
+	if err != nil {
+		return nil, err
+	}
+	listeners.Lock()
+	defer listeners.Unlock()
+	if port == 0 {
+		port = listeners.nextPort
+		listeners.nextPort++
+	}
+	if _, ok := listeners.byPort[port]; ok {
+		return nil, errors.New("address in use")
+	}
+	l := &memListener{
+		t:     t,
+		port:  port,
+		conns: make(chan *memConn),
+		done:  make(chan struct{}),
+	}
+	listeners.byPort[port] = l
+	return l, nil
+}
+
+func (t *memTransport) Protocols() []int {
+	return []int{ma.P_TCP}
+}
+
+func (t *memTransport) Proxy() bool {
+	return false
+}
+
+func portOf(addr ma.Multiaddr) (int, error) {
+	if ip, err := addr.ValueForProtocol(ma.P_IP4); err != nil || ip != "127.0.0.1" {
+		return 0, errors.New("not a loopback address")
+	}
+	port, err := addr.ValueForProtocol(ma.P_TCP)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
+}
+
+type memListener struct {
+	t    *memTransport
+	port int
+
+	conns     chan *memConn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *memListener) Accept() (transport.CapableConn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		return nil, errors.New("listener closed")
+	}
+}
+
+func (l *memListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		listeners.Lock()
+		delete(listeners.byPort, l.port)
+		listeners.Unlock()
+	})
+	return nil
+}
+
+func (l *memListener) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: l.port}
+}
+
+func (l *memListener) Multiaddr() ma.Multiaddr {
+	return ma.StringCast("/ip4/127.0.0.1/tcp/" + strconv.Itoa(l.port))
+}
+
+type memConn struct {
+	network.MuxedConn
+
+	t, remote    *memTransport
+	laddr, raddr ma.Multiaddr
+}
+
+func (c *memConn) LocalPeer() peer.ID             { return c.t.id }
+func (c *memConn) LocalPrivateKey() ic.PrivKey    { return c.t.priv }
+func (c *memConn) RemotePeer() peer.ID            { return c.remote.id }
+func (c *memConn) RemotePublicKey() ic.PubKey     { return c.remote.priv.GetPublic() }
+func (c *memConn) LocalMultiaddr() ma.Multiaddr   { return c.laddr }
+func (c *memConn) RemoteMultiaddr() ma.Multiaddr  { return c.raddr }
+func (c *memConn) Scope() network.ConnScope       { return network.NullScope }
+func (c *memConn) Transport() transport.Transport { return c.t }
+
+func TestSuite(t *testing.T) {
+	ta, tb := newMemTransport(t), newMemTransport(t)
+	transporttest.TestSuite(t, ta, tb, ma.StringCast("/ip4/127.0.0.1/tcp/0"), ta.id)
+}
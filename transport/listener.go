@@ -0,0 +1,71 @@
+package transport
+
+import (
+	"context"
+	"errors"
+)
+
+// ContextListener is a Listener whose Accept can be interrupted with a
+// context, rather than only by closing the Listener.
+//
+// To accept connections with a context from a Listener that may not support
+// it, use the AcceptContext helper.
+type ContextListener interface {
+	Listener
+
+	// AcceptContext waits for and returns the next connection, like Accept.
+	// It returns ctx.Err() if ctx is done before a connection is accepted,
+	// leaving the Listener open.
+	AcceptContext(ctx context.Context) (CapableConn, error)
+}
+
+// PausableListener is a Listener that can temporarily stop accepting
+// connections, e.g. when the resource manager is under memory pressure.
+//
+// While paused, Accept blocks and incoming connections are left queued by the
+// operating system or the transport, up to their own limits, instead of being
+// accepted and upgraded. Transports under pressure thus degrade gracefully,
+// at the cost of incoming connections timing out, instead of running out of
+// memory.
+//
+// To test whether a given Listener supports pausing, use the
+// GetPausableListener helper.
+type PausableListener interface {
+	Listener
+
+	// Pause stops accepting connections until Resume is called. Calls to
+	// Accept already waiting for a connection keep waiting. Calling Pause on
+	// a paused Listener is a no-op.
+	Pause()
+
+	// Resume resumes accepting connections after a call to Pause. Calling
+	// Resume on a Listener that isn't paused is a no-op.
+	Resume()
+
+	// IsPaused returns true if the Listener is currently paused.
+	IsPaused() bool
+}
+
+// GetPausableListener is a helper to "upcast" a Listener to a
+// PausableListener by using type assertion. Returns (nil, false) if the
+// Listener doesn't support pausing.
+func GetPausableListener(l Listener) (pl PausableListener, ok bool) {
+	pl, ok = l.(PausableListener)
+	return pl, ok
+}
+
+// ErrNoContextSupport is returned by AcceptContext for Listeners that aren't
+// ContextListeners.
+var ErrNoContextSupport = errors.New("listener doesn't support accepting with a context")
+
+// AcceptContext accepts the next connection from l, returning ctx.Err() if
+// ctx is done first. It returns ErrNoContextSupport if l isn't a
+// ContextListener: a plain Accept can only be interrupted by closing l, and
+// waiting for it in the background would leak its result.
+func AcceptContext(ctx context.Context, l Listener) (CapableConn, error) {
+	cl, ok := l.(ContextListener)
+	if !ok {
+		return nil, ErrNoContextSupport
+	}
+	return cl.AcceptContext(ctx)
+}
@@ -0,0 +1,179 @@
+// Package quicutil provides helpers shared by QUIC based transports for
+// validating /quic-v1 and /webtransport multiaddrs, and for matching the
+// certificate hashes contained in /certhash components against TLS
+// certificates.
+package quicutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	ma "github.com/multiformats/go-multiaddr"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/multiformats/go-varint"
+)
+
+var (
+	// ErrNotQUICV1 is returned for multiaddrs that are not /quic-v1 addresses.
+	ErrNotQUICV1 = errors.New("not a /quic-v1 multiaddr")
+	// ErrNotWebTransport is returned for multiaddrs that are not /webtransport
+	// addresses.
+	ErrNotWebTransport = errors.New("not a /webtransport multiaddr")
+	// ErrNoCertHash is returned when a /webtransport multiaddr that must
+	// contain certificate hashes doesn't contain any.
+	ErrNoCertHash = errors.New("no /certhash in multiaddr")
+	// ErrUnsupportedHash is returned when a certificate hash uses a hash
+	// function other than SHA-256.
+	ErrUnsupportedHash = errors.New("unsupported certhash hash function")
+	// ErrCertHashMismatch is returned when a certificate doesn't match any of
+	// the certificate hashes of a multiaddr.
+	ErrCertHashMismatch = errors.New("certificate doesn't match any certhash")
+)
+
+// parse splits a multiaddr of the form
+// /<ip4|ip6|dns|dns4|dns6>/<host>/udp/<port>/quic-v1[/<rest>...][/p2p/<id>]
+// into the components following /quic-v1, without the trailing /p2p component.
+func parse(addr ma.Multiaddr) ([]ma.Component, error) {
+	var comps []ma.Component
+	ma.ForEach(addr, func(c ma.Component) bool {
+		comps = append(comps, c)
+		return true
+	})
+	if len(comps) < 3 {
+		return nil, ErrNotQUICV1
+	}
+	switch comps[0].Protocol().Code {
+	case ma.P_IP4, ma.P_IP6, ma.P_DNS, ma.P_DNS4, ma.P_DNS6:
+	default:
+		return nil, ErrNotQUICV1
+	}
+	if comps[1].Protocol().Code != ma.P_UDP || comps[2].Protocol().Code != ma.P_QUIC_V1 {
+		return nil, ErrNotQUICV1
+	}
+	rest := comps[3:]
+	if n := len(rest); n > 0 && rest[n-1].Protocol().Code == ma.P_P2P {
+		rest = rest[:n-1]
+	}
+	return rest, nil
+}
+
+// IsQUICV1Addr returns true if addr is a plain /quic-v1 multiaddr, optionally
+// followed by a /p2p component.
+func IsQUICV1Addr(addr ma.Multiaddr) bool {
+	rest, err := parse(addr)
+	return err == nil && len(rest) == 0
+}
+
+// IsWebTransportAddr returns true if addr is a well-formed /webtransport
+// multiaddr. See ValidateWebTransportAddr.
+func IsWebTransportAddr(addr ma.Multiaddr) bool {
+	return ValidateWebTransportAddr(addr, false) == nil
+}
+
+// ValidateWebTransportAddr checks that addr is of the form
+// /.../udp/<port>/quic-v1/webtransport[/certhash/<hash>...][/p2p/<id>], and
+// that all certificate hashes are valid multihashes. If requireCertHash is
+// true, at least one /certhash component must be present, as is the case for
+// addresses of servers using self-signed certificates.
+func ValidateWebTransportAddr(addr ma.Multiaddr, requireCertHash bool) error {
+	rest, err := parse(addr)
+	if err != nil {
+		return err
+	}
+	if len(rest) == 0 || rest[0].Protocol().Code != ma.P_WEBTRANSPORT {
+		return ErrNotWebTransport
+	}
+	for _, c := range rest[1:] {
+		if c.Protocol().Code != ma.P_CERTHASH {
+			return fmt.Errorf("unexpected /%s component after /webtransport", c.Protocol().Name)
+		}
+		if _, err := mh.Decode(c.RawValue()); err != nil {
+			return fmt.Errorf("invalid certhash: %w", err)
+		}
+	}
+	if requireCertHash && len(rest) == 1 {
+		return ErrNoCertHash
+	}
+	return nil
+}
+
+// CertHashes returns the decoded certificate hashes of a /webtransport
+// multiaddr, in order.
+func CertHashes(addr ma.Multiaddr) ([]*mh.DecodedMultihash, error) {
+	if err := ValidateWebTransportAddr(addr, false); err != nil {
+		return nil, err
+	}
+	var hashes []*mh.DecodedMultihash
+	var err error
+	ma.ForEach(addr, func(c ma.Component) bool {
+		if c.Protocol().Code != ma.P_CERTHASH {
+			return true
+		}
+		var h *mh.DecodedMultihash
+		h, err = mh.Decode(c.RawValue())
+		if err != nil {
+			return false
+		}
+		hashes = append(hashes, h)
+		return true
+	})
+	return hashes, err
+}
+
+// CertHash returns the /certhash component for the given certificate, using
+// SHA-256.
+func CertHash(cert *x509.Certificate) (ma.Multiaddr, error) {
+	hash, err := mh.Sum(cert.Raw, mh.SHA2_256, -1)
+	if err != nil {
+		return nil, err
+	}
+	b := ma.CodeToVarint(ma.P_CERTHASH)
+	b = append(b, varint.ToUvarint(uint64(len(hash)))...)
+	b = append(b, hash...)
+	return ma.NewMultiaddrBytes(b)
+}
+
+// MatchCertHash returns true if any of the given certificate hashes is the
+// hash of cert. Hashes using a hash function other than SHA-256 are ignored;
+// if no hash uses SHA-256, ErrUnsupportedHash is returned.
+func MatchCertHash(hashes []*mh.DecodedMultihash, cert *x509.Certificate) (bool, error) {
+	sum := sha256.Sum256(cert.Raw)
+	supported := false
+	for _, h := range hashes {
+		if h.Code != mh.SHA2_256 {
+			continue
+		}
+		supported = true
+		if bytes.Equal(h.Digest, sum[:]) {
+			return true, nil
+		}
+	}
+	if !supported && len(hashes) > 0 {
+		return false, ErrUnsupportedHash
+	}
+	return false, nil
+}
+
+// VerifyCertHash checks that the leaf certificate presented by a server
+// matches one of the certificate hashes of the /webtransport multiaddr it was
+// dialed on.
+func VerifyCertHash(addr ma.Multiaddr, cert *x509.Certificate) error {
+	hashes, err := CertHashes(addr)
+	if err != nil {
+		return err
+	}
+	if len(hashes) == 0 {
+		return ErrNoCertHash
+	}
+	ok, err := MatchCertHash(hashes, cert)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCertHashMismatch
+	}
+	return nil
+}
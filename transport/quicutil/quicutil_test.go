@@ -0,0 +1,102 @@
+package quicutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+	mh "github.com/multiformats/go-multihash"
+)
+
+func newCert(t *testing.T) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestIsQUICV1Addr(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"/ip4/1.2.3.4/udp/1234/quic-v1": true,
+		"/ip6/::1/udp/1234/quic-v1/p2p/QmS3zcG7LhYZYSJMhyRZvTddvbNUqtt8BJpaSs6mi1K5Va": true,
+		"/dns4/example.com/udp/1234/quic-v1":                                           true,
+		"/ip4/1.2.3.4/udp/1234/quic":                                                   false,
+		"/ip4/1.2.3.4/tcp/1234":                                                        false,
+		"/ip4/1.2.3.4/udp/1234/quic-v1/webtransport":                                   false,
+	} {
+		if IsQUICV1Addr(ma.StringCast(addr)) != expected {
+			t.Errorf("expected IsQUICV1Addr(%s) to be %t", addr, expected)
+		}
+	}
+}
+
+func TestValidateWebTransportAddr(t *testing.T) {
+	cert := newCert(t)
+	certhash, err := CertHash(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := ma.StringCast("/ip4/1.2.3.4/udp/1234/quic-v1/webtransport")
+	withHash := base.Encapsulate(certhash)
+
+	if err := ValidateWebTransportAddr(base, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateWebTransportAddr(base, true); err != ErrNoCertHash {
+		t.Fatalf("expected ErrNoCertHash, got %v", err)
+	}
+	if err := ValidateWebTransportAddr(withHash, true); err != nil {
+		t.Fatal(err)
+	}
+	if IsWebTransportAddr(ma.StringCast("/ip4/1.2.3.4/udp/1234/quic-v1")) {
+		t.Fatal("expected plain QUIC address not to be a WebTransport address")
+	}
+	if IsWebTransportAddr(ma.StringCast("/ip4/1.2.3.4/udp/1234/quic/webtransport")) {
+		t.Fatal("expected draft-29 QUIC address not to be a WebTransport address")
+	}
+
+	if err := VerifyCertHash(withHash, cert); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyCertHash(withHash, newCert(t)); err != ErrCertHashMismatch {
+		t.Fatalf("expected ErrCertHashMismatch, got %v", err)
+	}
+	if err := VerifyCertHash(base, cert); err != ErrNoCertHash {
+		t.Fatalf("expected ErrNoCertHash, got %v", err)
+	}
+}
+
+func TestMatchCertHashUnsupported(t *testing.T) {
+	cert := newCert(t)
+	sum, err := mh.Sum(cert.Raw, mh.SHA2_512, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := mh.Decode(sum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MatchCertHash([]*mh.DecodedMultihash{h}, cert); err != ErrUnsupportedHash {
+		t.Fatalf("expected ErrUnsupportedHash, got %v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package transport
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var (
+	// ErrResolveDepth is returned when resolving an address takes more steps
+	// than allowed, e.g. because of a cycle of /dnsaddr records.
+	ErrResolveDepth = errors.New("multiaddr resolution exceeded maximum depth")
+	// ErrResolveLookups is returned when resolving an address takes more
+	// lookups than allowed, e.g. because every /dnsaddr record lists many
+	// other /dnsaddr records.
+	ErrResolveLookups = errors.New("multiaddr resolution exceeded maximum number of lookups")
+)
+
+// Resolver resolves multiaddrs whose first component names a host, e.g.
+// /dns4, /dns6 or /dnsaddr, into the addresses they stand for.
+//
+// Networks resolve addresses before dialing them. Embedders can plug in
+// custom resolvers, e.g. DNS over HTTPS, by composing them into a Pipeline,
+// possibly behind a CachingResolver:
+//
+//	resolver := transport.NewCachingResolver(
+//		transport.NewDNSPipeline(&transport.NetResolver{TTL: time.Minute}),
+//		transport.DefaultResolverCacheSize,
+//		time.Hour,
+//	)
+type Resolver interface {
+	// Resolve returns the addresses addr resolves to, which may need to be
+	// resolved further, and how long they may be cached. The components of
+	// addr following the resolved one, e.g. /tcp/4001, are kept.
+	Resolve(ctx context.Context, addr ma.Multiaddr) (addrs []ma.Multiaddr, ttl time.Duration, err error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error)
+
+var _ Resolver = ResolverFunc(nil)
+
+// Resolve calls f(ctx, addr).
+func (f ResolverFunc) Resolve(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error) {
+	return f(ctx, addr)
+}
+
+// DefaultMaxResolveDepth is the default maximum number of resolution steps
+// from an address to the addresses that are dialed.
+const DefaultMaxResolveDepth = 8
+
+// DefaultMaxResolveLookups is the default maximum number of lookups made to
+// resolve an address.
+const DefaultMaxResolveLookups = 32
+
+// Pipeline is a Resolver that dispatches addresses to Resolvers according to
+// the protocol of their first component, and resolves the results again until
+// none of them needs resolving, e.g. from /dnsaddr to /dns4 to /ip4.
+// Addresses that no Resolver handles are returned as is.
+type Pipeline struct {
+	// MaxDepth is the maximum number of resolution steps, beyond which
+	// ErrResolveDepth is returned. It defaults to DefaultMaxResolveDepth.
+	MaxDepth int
+
+	// MaxLookups is the maximum number of lookups made by the Resolvers,
+	// beyond which ErrResolveLookups is returned. As every step may yield
+	// several addresses to resolve, the number of lookups grows exponentially
+	// with the depth. It defaults to DefaultMaxResolveLookups.
+	MaxLookups int
+
+	resolvers map[int]Resolver
+}
+
+var _ Resolver = (*Pipeline)(nil)
+
+// NewPipeline returns an empty Pipeline. Register Resolvers with Handle.
+func NewPipeline() *Pipeline {
+	return &Pipeline{resolvers: make(map[int]Resolver)}
+}
+
+// Handle registers r to resolve addresses starting with the given multiaddr
+// protocols, e.g. ma.P_DNS4, replacing the Resolver previously registered for
+// them, and returns the Pipeline.
+func (p *Pipeline) Handle(r Resolver, codes ...int) *Pipeline {
+	for _, code := range codes {
+		p.resolvers[code] = r
+	}
+	return p
+}
+
+// Resolvable returns true if the first component of addr is handled by one of
+// the Pipeline's Resolvers.
+func (p *Pipeline) Resolvable(addr ma.Multiaddr) bool {
+	_, ok := p.resolverFor(addr)
+	return ok
+}
+
+func (p *Pipeline) resolverFor(addr ma.Multiaddr) (Resolver, bool) {
+	protos := addr.Protocols()
+	if len(protos) == 0 {
+		return nil, false
+	}
+	r, ok := p.resolvers[protos[0].Code]
+	return r, ok
+}
+
+// Resolve resolves addr until none of the resulting addresses is resolvable,
+// and returns them without duplicates. The returned TTL is the smallest of
+// the TTLs of all resolution steps, or zero if addr isn't resolvable.
+func (p *Pipeline) Resolve(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error) {
+	maxDepth := p.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxResolveDepth
+	}
+	maxLookups := p.MaxLookups
+	if maxLookups <= 0 {
+		maxLookups = DefaultMaxResolveLookups
+	}
+
+	var (
+		out      []ma.Multiaddr
+		seen     = make(map[string]struct{})
+		ttl      time.Duration
+		resolved bool
+		lookups  int
+	)
+	var resolve func(addr ma.Multiaddr, depth int) error
+	resolve = func(addr ma.Multiaddr, depth int) error {
+		r, ok := p.resolverFor(addr)
+		if !ok {
+			if _, dup := seen[string(addr.Bytes())]; !dup {
+				seen[string(addr.Bytes())] = struct{}{}
+				out = append(out, addr)
+			}
+			return nil
+		}
+		if depth >= maxDepth {
+			return ErrResolveDepth
+		}
+		if lookups >= maxLookups {
+			return ErrResolveLookups
+		}
+		lookups++
+		addrs, stepTTL, err := r.Resolve(ctx, addr)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", addr, err)
+		}
+		if !resolved || stepTTL < ttl {
+			ttl = stepTTL
+		}
+		resolved = true
+		for _, a := range addrs {
+			if err := resolve(a, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := resolve(addr, 0); err != nil {
+		return nil, 0, err
+	}
+	return out, ttl, nil
+}
+
+// DefaultResolverCacheSize is the default number of addresses whose resolution
+// a CachingResolver keeps.
+const DefaultResolverCacheSize = 1024
+
+type cachedResolution struct {
+	key     string
+	addrs   []ma.Multiaddr
+	expires time.Time
+}
+
+// CachingResolver is a Resolver that caches the results of another Resolver
+// for their TTL, bounded by a maximum. It keeps the results for a bounded
+// number of addresses, evicting the least recently used ones.
+type CachingResolver struct {
+	r      Resolver
+	size   int
+	maxTTL time.Duration
+	clock  func() time.Time
+
+	mu sync.Mutex
+	// lru holds the *cachedResolutions, most recently used first.
+	lru   *list.List
+	cache map[string]*list.Element
+}
+
+var _ Resolver = (*CachingResolver)(nil)
+
+// NewCachingResolver returns a Resolver caching the results of r for their
+// TTL, but no longer than maxTTL, for at most size addresses. Results with a
+// zero TTL aren't cached. size defaults to DefaultResolverCacheSize if it
+// isn't positive.
+func NewCachingResolver(r Resolver, size int, maxTTL time.Duration) *CachingResolver {
+	if size <= 0 {
+		size = DefaultResolverCacheSize
+	}
+	return &CachingResolver{
+		r:      r,
+		size:   size,
+		maxTTL: maxTTL,
+		clock:  time.Now,
+		lru:    list.New(),
+		cache:  make(map[string]*list.Element),
+	}
+}
+
+// Resolve returns the cached resolution of addr if it hasn't expired, and
+// resolves it with the underlying Resolver otherwise. The returned TTL is the
+// remaining time the result is cached for.
+func (c *CachingResolver) Resolve(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error) {
+	key := string(addr.Bytes())
+	now := c.clock()
+
+	c.mu.Lock()
+	if elem, ok := c.cache[key]; ok {
+		entry := elem.Value.(*cachedResolution)
+		if now.Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			addrs := copyAddrs(entry.addrs)
+			c.mu.Unlock()
+			return addrs, entry.expires.Sub(now), nil
+		}
+		c.remove(elem)
+	}
+	c.mu.Unlock()
+
+	addrs, ttl, err := c.r.Resolve(ctx, addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ttl > c.maxTTL {
+		ttl = c.maxTTL
+	}
+	if ttl > 0 {
+		c.add(&cachedResolution{key: key, addrs: copyAddrs(addrs), expires: now.Add(ttl)})
+	}
+	return addrs, ttl, nil
+}
+
+// add caches entry, replacing the previous resolution of the same address,
+// and evicts the least recently used entries beyond the cache size.
+func (c *CachingResolver) add(entry *cachedResolution) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.cache[entry.key]; ok {
+		c.remove(elem)
+	}
+	c.cache[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes elem from the cache. The caller must hold c.mu.
+func (c *CachingResolver) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.cache, elem.Value.(*cachedResolution).key)
+}
+
+// Len returns the number of addresses whose resolution is cached, including
+// expired resolutions that haven't been evicted yet.
+func (c *CachingResolver) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// copyAddrs copies addrs, so that callers modifying the returned slice don't
+// modify the cache.
+func copyAddrs(addrs []ma.Multiaddr) []ma.Multiaddr {
+	return append([]ma.Multiaddr(nil), addrs...)
+}
+
+// Flush empties the cache.
+func (c *CachingResolver) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.cache = make(map[string]*list.Element)
+}
+
+// dnsaddrTXTPrefix prefixes the addresses in the TXT records of /dnsaddr
+// domains.
+const dnsaddrTXTPrefix = "dnsaddr="
+
+// NetResolver resolves /dns, /dns4, /dns6 and /dnsaddr addresses with a
+// net.Resolver. As net.Resolver doesn't report the TTL of DNS records, the
+// results are given a fixed TTL.
+type NetResolver struct {
+	// Resolver is the resolver used for DNS lookups. It defaults to
+	// net.DefaultResolver.
+	Resolver *net.Resolver
+
+	// TTL is the TTL reported for all results.
+	TTL time.Duration
+}
+
+var _ Resolver = (*NetResolver)(nil)
+
+// Resolve resolves addr, whose first component must be a /dns, /dns4, /dns6
+// or /dnsaddr component.
+//
+// /dnsaddr addresses are resolved to the addresses listed in the TXT records
+// of the domain's _dnsaddr subdomain, as "dnsaddr=<multiaddr>". If addr has
+// components following the /dnsaddr component, e.g. /p2p/<peer ID>, only the
+// listed addresses ending with the same components are returned.
+func (r *NetResolver) Resolve(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error) {
+	res := r.Resolver
+	if res == nil {
+		res = net.DefaultResolver
+	}
+	first, rest := ma.SplitFirst(addr)
+	if first == nil {
+		return nil, 0, fmt.Errorf("empty multiaddr")
+	}
+	host := first.Value()
+
+	var network, proto string
+	switch first.Protocol().Code {
+	case ma.P_DNS:
+		network = "ip"
+	case ma.P_DNS4:
+		network, proto = "ip4", "ip4"
+	case ma.P_DNS6:
+		network, proto = "ip6", "ip6"
+	case ma.P_DNSADDR:
+		addrs, err := r.resolveDNSAddr(ctx, res, host, rest)
+		return addrs, r.TTL, err
+	default:
+		return nil, 0, fmt.Errorf("cannot resolve %s: unsupported protocol %s", addr, first.Protocol().Name)
+	}
+
+	ips, err := res.LookupIP(ctx, network, host)
+	if err != nil {
+		return nil, 0, err
+	}
+	addrs := make([]ma.Multiaddr, 0, len(ips))
+	for _, ip := range ips {
+		p := proto
+		if p == "" {
+			p = "ip6"
+			if ip.To4() != nil {
+				p = "ip4"
+			}
+		}
+		c, err := ma.NewComponent(p, ip.String())
+		if err != nil {
+			return nil, 0, err
+		}
+		if rest != nil {
+			addrs = append(addrs, c.Encapsulate(rest))
+		} else {
+			addrs = append(addrs, c)
+		}
+	}
+	return addrs, r.TTL, nil
+}
+
+func (r *NetResolver) resolveDNSAddr(ctx context.Context, res *net.Resolver, host string, rest ma.Multiaddr) ([]ma.Multiaddr, error) {
+	records, err := res.LookupTXT(ctx, "_dnsaddr."+host)
+	if err != nil {
+		return nil, err
+	}
+	var addrs []ma.Multiaddr
+	for _, rec := range records {
+		if !strings.HasPrefix(rec, dnsaddrTXTPrefix) {
+			continue
+		}
+		a, err := ma.NewMultiaddr(rec[len(dnsaddrTXTPrefix):])
+		if err != nil {
+			continue
+		}
+		if rest != nil && !hasSuffix(a, rest) {
+			continue
+		}
+		addrs = append(addrs, a)
+	}
+	return addrs, nil
+}
+
+func hasSuffix(addr, suffix ma.Multiaddr) bool {
+	b, s := addr.Bytes(), suffix.Bytes()
+	return len(b) >= len(s) && string(b[len(b)-len(s):]) == string(s)
+}
+
+// NewDNSPipeline returns a Pipeline resolving /dns, /dns4, /dns6 and
+// /dnsaddr addresses with r.
+func NewDNSPipeline(r Resolver) *Pipeline {
+	return NewPipeline().Handle(r, ma.P_DNS, ma.P_DNS4, ma.P_DNS6, ma.P_DNSADDR)
+}
@@ -0,0 +1,287 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// staticResolver resolves addresses from a table, with a fixed TTL per
+// address, and counts the lookups.
+type staticResolver struct {
+	table   map[string][]ma.Multiaddr
+	ttls    map[string]time.Duration
+	lookups int
+}
+
+func (r *staticResolver) resolver() Resolver {
+	return ResolverFunc(func(_ context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error) {
+		r.lookups++
+		addrs, ok := r.table[addr.String()]
+		if !ok {
+			return nil, 0, errors.New("no such host")
+		}
+		return addrs, r.ttls[addr.String()], nil
+	})
+}
+
+func addrs(ss ...string) []ma.Multiaddr {
+	out := make([]ma.Multiaddr, 0, len(ss))
+	for _, s := range ss {
+		out = append(out, ma.StringCast(s))
+	}
+	return out
+}
+
+func requireAddrs(t *testing.T, expected, actual []ma.Multiaddr) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if !expected[i].Equal(actual[i]) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	sr := &staticResolver{
+		table: map[string][]ma.Multiaddr{
+			"/dnsaddr/bootstrap.example": addrs("/dns4/a.example/tcp/1", "/dns4/b.example/tcp/1", "/ip4/1.1.1.1/tcp/1"),
+			"/dns4/a.example/tcp/1":      addrs("/ip4/1.1.1.1/tcp/1", "/ip4/2.2.2.2/tcp/1"),
+			"/dns4/b.example/tcp/1":      addrs("/ip4/2.2.2.2/tcp/1"),
+		},
+		ttls: map[string]time.Duration{
+			"/dnsaddr/bootstrap.example": time.Hour,
+			"/dns4/a.example/tcp/1":      time.Minute,
+			"/dns4/b.example/tcp/1":      10 * time.Minute,
+		},
+	}
+	p := NewDNSPipeline(sr.resolver())
+
+	if p.Resolvable(ma.StringCast("/ip4/1.1.1.1/tcp/1")) || !p.Resolvable(ma.StringCast("/dns4/a.example/tcp/1")) {
+		t.Fatal("unexpected Resolvable result")
+	}
+
+	out, ttl, err := p.Resolve(context.Background(), ma.StringCast("/dnsaddr/bootstrap.example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// duplicates are dropped, in order of first appearance
+	requireAddrs(t, addrs("/ip4/1.1.1.1/tcp/1", "/ip4/2.2.2.2/tcp/1"), out)
+	if ttl != time.Minute {
+		t.Fatalf("expected the smallest TTL of all steps, got %s", ttl)
+	}
+
+	// addresses that aren't resolvable are returned as is, with a zero TTL
+	out, ttl, err = p.Resolve(context.Background(), ma.StringCast("/ip4/3.3.3.3/tcp/1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireAddrs(t, addrs("/ip4/3.3.3.3/tcp/1"), out)
+	if ttl != 0 {
+		t.Fatalf("expected a zero TTL, got %s", ttl)
+	}
+
+	if _, _, err := p.Resolve(context.Background(), ma.StringCast("/dns4/unknown.example/tcp/1")); err == nil {
+		t.Fatal("expected resolution errors to be returned")
+	}
+}
+
+func TestPipelineDepth(t *testing.T) {
+	sr := &staticResolver{
+		table: map[string][]ma.Multiaddr{
+			"/dnsaddr/a.example": addrs("/dnsaddr/b.example"),
+			"/dnsaddr/b.example": addrs("/dnsaddr/a.example"),
+		},
+	}
+	p := NewDNSPipeline(sr.resolver())
+	_, _, err := p.Resolve(context.Background(), ma.StringCast("/dnsaddr/a.example"))
+	if !errors.Is(err, ErrResolveDepth) {
+		t.Fatalf("expected ErrResolveDepth for a cycle, got %v", err)
+	}
+	if sr.lookups != DefaultMaxResolveDepth {
+		t.Fatalf("expected %d lookups, got %d", DefaultMaxResolveDepth, sr.lookups)
+	}
+
+	sr.lookups = 0
+	p.MaxDepth = 2
+	if _, _, err := p.Resolve(context.Background(), ma.StringCast("/dnsaddr/a.example")); !errors.Is(err, ErrResolveDepth) {
+		t.Fatalf("expected ErrResolveDepth, got %v", err)
+	}
+	if sr.lookups != 2 {
+		t.Fatalf("expected MaxDepth to bound the lookups, got %d", sr.lookups)
+	}
+}
+
+func TestPipelineLookups(t *testing.T) {
+	// every /dnsaddr record lists two others, down to 6 levels, i.e. 127
+	// lookups within the maximum depth
+	fanOut := ResolverFunc(func(_ context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error) {
+		name, _ := addr.ValueForProtocol(ma.P_DNSADDR)
+		if len(name) > 6 {
+			return addrs("/ip4/1.1.1.1"), time.Minute, nil
+		}
+		return addrs("/dnsaddr/"+name+"0", "/dnsaddr/"+name+"1"), time.Minute, nil
+	})
+	lookups := 0
+	counted := ResolverFunc(func(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, time.Duration, error) {
+		lookups++
+		return fanOut(ctx, addr)
+	})
+	p := NewDNSPipeline(counted)
+	if _, _, err := p.Resolve(context.Background(), ma.StringCast("/dnsaddr/a")); !errors.Is(err, ErrResolveLookups) {
+		t.Fatalf("expected ErrResolveLookups, got %v", err)
+	}
+	if lookups != DefaultMaxResolveLookups {
+		t.Fatalf("expected %d lookups, got %d", DefaultMaxResolveLookups, lookups)
+	}
+
+	lookups = 0
+	p.MaxLookups = 5
+	if _, _, err := p.Resolve(context.Background(), ma.StringCast("/dnsaddr/a")); !errors.Is(err, ErrResolveLookups) {
+		t.Fatalf("expected ErrResolveLookups, got %v", err)
+	}
+	if lookups != 5 {
+		t.Fatalf("expected MaxLookups to bound the lookups, got %d", lookups)
+	}
+}
+
+func TestCachingResolver(t *testing.T) {
+	sr := &staticResolver{
+		table: map[string][]ma.Multiaddr{
+			"/dns4/short.example": addrs("/ip4/1.1.1.1"),
+			"/dns4/long.example":  addrs("/ip4/2.2.2.2"),
+			"/dns4/none.example":  addrs("/ip4/3.3.3.3"),
+		},
+		ttls: map[string]time.Duration{
+			"/dns4/short.example": time.Minute,
+			"/dns4/long.example":  24 * time.Hour,
+		},
+	}
+	now := time.Now()
+	c := NewCachingResolver(sr.resolver(), 0, time.Hour)
+	c.clock = func() time.Time { return now }
+	ctx := context.Background()
+	resolve := func(addr string) ([]ma.Multiaddr, time.Duration) {
+		t.Helper()
+		out, ttl, err := c.Resolve(ctx, ma.StringCast(addr))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out, ttl
+	}
+
+	out, ttl := resolve("/dns4/short.example")
+	requireAddrs(t, addrs("/ip4/1.1.1.1"), out)
+	if ttl != time.Minute {
+		t.Fatalf("expected a TTL of a minute, got %s", ttl)
+	}
+	// modifying the result doesn't modify the cache
+	out[0] = ma.StringCast("/ip4/6.6.6.6")
+
+	now = now.Add(30 * time.Second)
+	out, ttl = resolve("/dns4/short.example")
+	requireAddrs(t, addrs("/ip4/1.1.1.1"), out)
+	if ttl != 30*time.Second || sr.lookups != 1 {
+		t.Fatalf("expected a cached result with the remaining TTL, got %s after %d lookups", ttl, sr.lookups)
+	}
+	out[0] = ma.StringCast("/ip4/6.6.6.6")
+	out, _ = resolve("/dns4/short.example")
+	requireAddrs(t, addrs("/ip4/1.1.1.1"), out)
+
+	// expiry
+	now = now.Add(30 * time.Second)
+	resolve("/dns4/short.example")
+	if sr.lookups != 2 {
+		t.Fatalf("expected the expired result to be resolved again, got %d lookups", sr.lookups)
+	}
+
+	// TTLs are clamped to maxTTL
+	if _, ttl := resolve("/dns4/long.example"); ttl != time.Hour {
+		t.Fatalf("expected the TTL to be clamped to an hour, got %s", ttl)
+	}
+	now = now.Add(time.Hour)
+	resolve("/dns4/long.example")
+	if sr.lookups != 4 {
+		t.Fatalf("expected the result to expire after maxTTL, got %d lookups", sr.lookups)
+	}
+
+	// results with a zero TTL aren't cached
+	resolve("/dns4/none.example")
+	resolve("/dns4/none.example")
+	if sr.lookups != 6 {
+		t.Fatalf("expected results with a zero TTL not to be cached, got %d lookups", sr.lookups)
+	}
+
+	c.Flush()
+	resolve("/dns4/long.example")
+	if sr.lookups != 7 {
+		t.Fatalf("expected Flush to empty the cache, got %d lookups", sr.lookups)
+	}
+}
+
+func TestCachingResolverEviction(t *testing.T) {
+	sr := &staticResolver{
+		table: map[string][]ma.Multiaddr{
+			"/dns4/a.example": addrs("/ip4/1.1.1.1"),
+			"/dns4/b.example": addrs("/ip4/2.2.2.2"),
+			"/dns4/c.example": addrs("/ip4/3.3.3.3"),
+		},
+		ttls: map[string]time.Duration{
+			"/dns4/a.example": time.Hour,
+			"/dns4/b.example": time.Hour,
+			"/dns4/c.example": time.Hour,
+		},
+	}
+	c := NewCachingResolver(sr.resolver(), 2, time.Hour)
+	resolve := func(addr string) {
+		t.Helper()
+		if _, _, err := c.Resolve(context.Background(), ma.StringCast(addr)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolve("/dns4/a.example")
+	resolve("/dns4/b.example")
+	// a is now the most recently used
+	resolve("/dns4/a.example")
+	resolve("/dns4/c.example")
+	if c.Len() != 2 {
+		t.Fatalf("expected the cache to hold 2 addresses, got %d", c.Len())
+	}
+	if sr.lookups != 3 {
+		t.Fatalf("expected 3 lookups, got %d", sr.lookups)
+	}
+	resolve("/dns4/a.example")
+	if sr.lookups != 3 {
+		t.Fatal("expected the recently used resolution to be kept")
+	}
+	resolve("/dns4/b.example")
+	if sr.lookups != 4 {
+		t.Fatal("expected the least recently used resolution to be evicted")
+	}
+}
+
+func TestHasSuffix(t *testing.T) {
+	peerAddr := "/p2p/QmcgpsyWgH8Y8ajJz1Cu72KnS5uo2Aa2LpzU7kinSupNKC"
+	for _, tc := range []struct {
+		addr, suffix string
+		expected     bool
+	}{
+		{"/ip4/1.2.3.4/tcp/1" + peerAddr, peerAddr, true},
+		{"/ip4/1.2.3.4/tcp/1" + peerAddr, "/tcp/1" + peerAddr, true},
+		{"/ip4/1.2.3.4/tcp/1", peerAddr, false},
+		{"/ip4/1.2.3.4/tcp/1" + peerAddr, "/p2p/QmSoLer265NRgSp2LA3dPaeykiS1J6DifTC88f5uVQKNAd", false},
+		{"/tcp/1", "/ip4/1.2.3.4/tcp/1", false},
+		{"/ip4/1.2.3.4/tcp/1", "/ip4/1.2.3.4/tcp/1", true},
+	} {
+		if hasSuffix(ma.StringCast(tc.addr), ma.StringCast(tc.suffix)) != tc.expected {
+			t.Errorf("hasSuffix(%s, %s) != %t", tc.addr, tc.suffix, tc.expected)
+		}
+	}
+}
@@ -81,6 +81,8 @@ type Transport interface {
 // only real difference is that Accept() returns Conn's of the type in this
 // package, and also exposes a Multiaddr method as opposed to a regular Addr
 // method
+//
+// Listeners may additionally implement ContextListener and PausableListener.
 type Listener interface {
 	Accept() (CapableConn, error)
 	Close() error
@@ -108,6 +110,12 @@ type TransportNetwork interface {
 
 // Upgrader is a multistream upgrader that can upgrade an underlying connection
 // to a full transport connection (secure and multiplexed).
+//
+// An upgrade first secures the connection using a sec.SecureMuxer, and then
+// multiplexes it using a network.Multiplexer. Implementations consult the
+// connmgr.ConnectionGater, if configured, after the connection has been
+// secured (InterceptSecured) and after it has been multiplexed
+// (InterceptUpgraded), aborting the upgrade if the gater rejects it.
 type Upgrader interface {
 	// UpgradeListener upgrades the passed multiaddr-net listener into a full libp2p-transport listener.
 	UpgradeListener(Transport, manet.Listener) Listener